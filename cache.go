@@ -0,0 +1,437 @@
+package pdf
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache stores the output documents of conversions. The cache keys are
+// computed from the converter options, the object options and the content
+// of the converted documents, so identical conversions are served from the
+// cache. The resources loaded by the documents, such as style sheets, images
+// and scripts, are not part of the keys, so changes to them are not detected.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the data stored using the specified key, if it exists.
+	Get(key string) ([]byte, bool)
+
+	// Set stores the specified data using the provided key. Existing data
+	// stored using the same key is replaced.
+	Set(key string, data []byte)
+}
+
+// CacheOpts contains the limits of a cache. When the limits are exceeded,
+// the least recently used entries are evicted.
+type CacheOpts struct {
+	// The amount of time after which cache entries expire. If not specified,
+	// the entries do not expire.
+	// E.g.: time.Hour.
+	TTL time.Duration
+
+	// The maximum total size of the cache entries, in bytes. If not
+	// specified, the size of the cache is not limited.
+	// E.g.: 100 << 20.
+	MaxSize int64
+
+	// The maximum number of cache entries. If not specified, the number of
+	// entries is not limited.
+	// E.g.: 1000.
+	MaxEntries int
+}
+
+// MemoryCache is an in-memory cache, which evicts the least recently used
+// entries when its limits are exceeded.
+type MemoryCache struct {
+	mu  sync.Mutex
+	lru *lruIndex
+}
+
+// NewMemoryCache returns a new in-memory cache with the specified limits.
+// If no options are provided, the cache is not limited.
+func NewMemoryCache(opts *CacheOpts) *MemoryCache {
+	return &MemoryCache{lru: newLRUIndex(opts, nil)}
+}
+
+// Get returns a copy of the data stored using the specified key, if it
+// exists.
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.lru.get(key)
+	if !ok {
+		return nil, false
+	}
+
+	return append([]byte(nil), entry.data...), true
+}
+
+// Set stores the specified data using the provided key.
+func (c *MemoryCache) Set(key string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lru.add(&cacheEntry{
+		key:     key,
+		data:    append([]byte(nil), data...),
+		size:    int64(len(data)),
+		created: time.Now(),
+	})
+}
+
+// DiskCache is an on-disk cache, which stores each entry as a file in a
+// directory and evicts the least recently used entries when its limits
+// are exceeded. The entries stored in the directory are reused across
+// process restarts.
+type DiskCache struct {
+	mu  sync.Mutex
+	dir string
+	lru *lruIndex
+}
+
+// NewDiskCache returns a new on-disk cache with the specified limits, which
+// stores its entries in the provided directory. The directory is created if
+// it does not exist. If no options are provided, the cache is not limited.
+func NewDiskCache(dir string, opts *CacheOpts) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	c := &DiskCache{dir: dir}
+	c.lru = newLRUIndex(opts, func(entry *cacheEntry) {
+		os.Remove(c.path(entry.key)) // nolint:errcheck
+	})
+
+	// Load existing entries, from the least to the most recently stored.
+	paths, err := filepath.Glob(filepath.Join(dir, "*.cache"))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*cacheEntry
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+
+		entries = append(entries, &cacheEntry{
+			key:     strings.TrimSuffix(filepath.Base(path), ".cache"),
+			size:    info.Size(),
+			created: info.ModTime(),
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].created.Before(entries[j].created)
+	})
+
+	for _, entry := range entries {
+		if c.lru.expired(entry) {
+			c.lru.evict(entry)
+			continue
+		}
+		c.lru.add(entry)
+	}
+
+	return c, nil
+}
+
+// Get returns the data stored using the specified key, if it exists.
+func (c *DiskCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.lru.get(key); !ok {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		c.lru.remove(key)
+		return nil, false
+	}
+
+	return data, true
+}
+
+// Set stores the specified data using the provided key. The data is written
+// atomically, so the entries are not corrupted if the process is stopped.
+// Entries which cannot be written are ignored.
+func (c *DiskCache) Set(key string, data []byte) {
+	if !validCacheKey(key) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if max := c.lru.opts.MaxSize; max > 0 && int64(len(data)) > max {
+		return
+	}
+
+	f, err := os.CreateTemp(c.dir, ".entry-*")
+	if err != nil {
+		return
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name()) // nolint:errcheck
+		return
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name()) // nolint:errcheck
+		return
+	}
+	if err := os.Rename(f.Name(), c.path(key)); err != nil {
+		os.Remove(f.Name()) // nolint:errcheck
+		return
+	}
+
+	// The previous entry, if any, was replaced on disk.
+	c.lru.remove(key)
+	c.lru.add(&cacheEntry{
+		key:     key,
+		size:    int64(len(data)),
+		created: time.Now(),
+	})
+}
+
+func (c *DiskCache) path(key string) string {
+	return filepath.Join(c.dir, key+".cache")
+}
+
+// cacheEntry represents an entry of a cache. The data of the entry is
+// only stored for in-memory caches.
+type cacheEntry struct {
+	key     string
+	data    []byte
+	size    int64
+	created time.Time
+}
+
+// lruIndex keeps track of the entries of a cache, in the order in which they
+// were used, and evicts the entries which exceed the limits of the cache.
+// It is not safe for concurrent use.
+type lruIndex struct {
+	opts    CacheOpts
+	items   map[string]*list.Element
+	order   *list.List
+	size    int64
+	onEvict func(*cacheEntry)
+}
+
+func newLRUIndex(opts *CacheOpts, onEvict func(*cacheEntry)) *lruIndex {
+	l := &lruIndex{
+		items:   map[string]*list.Element{},
+		order:   list.New(),
+		onEvict: onEvict,
+	}
+	if opts != nil {
+		l.opts = *opts
+	}
+
+	return l
+}
+
+func (l *lruIndex) get(key string) (*cacheEntry, bool) {
+	elem, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	if l.expired(entry) {
+		l.removeElement(elem)
+		l.evict(entry)
+		return nil, false
+	}
+
+	l.order.MoveToFront(elem)
+	return entry, true
+}
+
+func (l *lruIndex) add(entry *cacheEntry) {
+	if elem, ok := l.items[entry.key]; ok {
+		l.removeElement(elem)
+	}
+	if l.opts.MaxSize > 0 && entry.size > l.opts.MaxSize {
+		return
+	}
+
+	l.items[entry.key] = l.order.PushFront(entry)
+	l.size += entry.size
+
+	// Evict the least recently used entries, if the limits are exceeded.
+	for l.exceeded() {
+		elem := l.order.Back()
+		l.removeElement(elem)
+		l.evict(elem.Value.(*cacheEntry))
+	}
+}
+
+func (l *lruIndex) remove(key string) {
+	if elem, ok := l.items[key]; ok {
+		l.removeElement(elem)
+	}
+}
+
+func (l *lruIndex) removeElement(elem *list.Element) {
+	entry := l.order.Remove(elem).(*cacheEntry)
+	delete(l.items, entry.key)
+	l.size -= entry.size
+}
+
+func (l *lruIndex) evict(entry *cacheEntry) {
+	if l.onEvict != nil {
+		l.onEvict(entry)
+	}
+}
+
+func (l *lruIndex) expired(entry *cacheEntry) bool {
+	return l.opts.TTL > 0 && time.Since(entry.created) > l.opts.TTL
+}
+
+func (l *lruIndex) exceeded() bool {
+	if l.opts.MaxEntries > 0 && l.order.Len() > l.opts.MaxEntries {
+		return true
+	}
+
+	return l.opts.MaxSize > 0 && l.size > l.opts.MaxSize
+}
+
+// cacheHTTPClient is used for retrieving the validators of remote documents.
+var cacheHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// cacheKey returns the key used for caching the output document of the
+// conversion of the specified objects, performed using the provided library
+// version and converter options. The key is derived from the library version,
+// the SOURCE_DATE_EPOCH environment variable, for reproducible output, the
+// converter options, the object options and the content of the objects
+// and of their header, footer and style sheet documents. Local documents are
+// identified by their content, while remote documents are identified by their
+// ETag or Last-Modified headers, if remote documents are allowed. If the
+// conversion cannot be cached, such as when a remote document has no
+// validators, an empty key is returned.
+func cacheKey(version string, converterOpts *ConverterOpts, objects []*Object, remote bool) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "wkhtmltox %s\n", version)
+	if err := json.NewEncoder(h).Encode(converterOpts); err != nil {
+		return ""
+	}
+	if converterOpts.Reproducible {
+		fmt.Fprintf(h, "SOURCE_DATE_EPOCH=%s\n", os.Getenv("SOURCE_DATE_EPOCH"))
+	}
+
+	for _, o := range objects {
+		opts := *o.ObjectOpts
+		sources := []*string{
+			&opts.Location,
+			&opts.Header.CustomLocation,
+			&opts.Footer.CustomLocation,
+			&opts.UserStylesheetLocation,
+		}
+		for _, source := range sources {
+			if *source == "" {
+				continue
+			}
+
+			digest, ok := sourceDigest(*source, remote)
+			if !ok {
+				return ""
+			}
+
+			// The location of temporary objects is random, so only
+			// their content is used.
+			if source == &opts.Location && o.temporary {
+				*source = digest
+			} else {
+				*source += "#" + digest
+			}
+		}
+
+		fmt.Fprintf(h, "object toc=%t\n", o.toc)
+		if err := json.NewEncoder(h).Encode(opts); err != nil {
+			return ""
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// sourceDigest returns a string which identifies the content of the document
+// at the specified location. Remote documents are only identified if allowed.
+func sourceDigest(location string, remote bool) (string, bool) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return "", false
+	}
+
+	path := location
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		if !remote {
+			return "", false
+		}
+		return remoteDigest(location)
+	case "file":
+		path = u.Path
+	case "":
+	default:
+		// Windows paths containing drive letters.
+		if len(u.Scheme) != 1 {
+			return "", false
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", false
+	}
+
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), true
+}
+
+// remoteDigest returns a string which identifies the content of the remote
+// document at the specified URL, based on its ETag or Last-Modified headers.
+func remoteDigest(location string) (string, bool) {
+	res, err := cacheHTTPClient.Head(location)
+	if err != nil {
+		return "", false
+	}
+	res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return "", false
+	}
+	if etag := res.Header.Get("ETag"); etag != "" {
+		return "etag:" + etag, true
+	}
+	if modified := res.Header.Get("Last-Modified"); modified != "" {
+		return "modified:" + modified, true
+	}
+
+	return "", false
+}
+
+// validCacheKey checks that the specified key can be used as a file name.
+func validCacheKey(key string) bool {
+	return key != "" && !strings.ContainsAny(key, `/\.`)
+}
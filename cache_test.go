@@ -0,0 +1,232 @@
+package pdf
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheEviction(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     *CacheOpts
+		present  []string
+		evicted  []string
+		accessed string
+	}{
+		{
+			name:    "unlimited",
+			present: []string{"a", "b", "c", "d"},
+		},
+		{
+			name:    "max entries",
+			opts:    &CacheOpts{MaxEntries: 2},
+			present: []string{"c", "d"},
+			evicted: []string{"a", "b"},
+		},
+		{
+			name:     "max entries after access",
+			opts:     &CacheOpts{MaxEntries: 3},
+			accessed: "a",
+			present:  []string{"a", "c", "d"},
+			evicted:  []string{"b"},
+		},
+		{
+			name:    "max size",
+			opts:    &CacheOpts{MaxSize: 9},
+			present: []string{"c", "d"},
+			evicted: []string{"a", "b"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := NewMemoryCache(test.opts)
+			c.Set("a", []byte("aaaa"))
+			c.Set("b", []byte("bbbb"))
+			if test.accessed != "" {
+				c.Get(test.accessed)
+			}
+			c.Set("c", []byte("cccc"))
+			c.Set("d", []byte("dddd"))
+
+			for _, key := range test.present {
+				if _, ok := c.Get(key); !ok {
+					t.Errorf("expected entry %s to be present", key)
+				}
+			}
+			for _, key := range test.evicted {
+				if _, ok := c.Get(key); ok {
+					t.Errorf("expected entry %s to be evicted", key)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryCacheCopies(t *testing.T) {
+	c := NewMemoryCache(&CacheOpts{MaxSize: 4})
+
+	data := []byte("data")
+	c.Set("key", data)
+	data[0] = 'x'
+
+	cached, ok := c.Get("key")
+	if !ok || string(cached) != "data" {
+		t.Fatalf("expected stored data to be copied, got %q", cached)
+	}
+	cached[0] = 'x'
+	if cached, _ := c.Get("key"); string(cached) != "data" {
+		t.Errorf("expected returned data to be copied, got %q", cached)
+	}
+
+	c.Set("large", []byte("too large"))
+	if _, ok := c.Get("large"); ok {
+		t.Error("expected entries larger than the cache not to be stored")
+	}
+	if _, ok := c.Get("key"); !ok {
+		t.Error("expected large entries not to evict existing entries")
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	c := NewMemoryCache(&CacheOpts{TTL: 20 * time.Millisecond})
+	c.Set("key", []byte("data"))
+	if _, ok := c.Get("key"); !ok {
+		t.Fatal("expected entry to be present")
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	if _, ok := c.Get("key"); ok {
+		t.Error("expected entry to expire")
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	dir := t.TempDir()
+
+	c, err := NewDiskCache(dir, &CacheOpts{MaxEntries: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"a", "b", "c"} {
+		c.Set(key, []byte("data "+key))
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.Set("../invalid", []byte("data"))
+
+	// Evicted entries are removed from disk.
+	if _, err := os.Stat(filepath.Join(dir, "a.cache")); !os.IsNotExist(err) {
+		t.Errorf("expected evicted entry to be removed, got %v", err)
+	}
+
+	// Entries are reloaded, in the order in which they were stored.
+	if c, err = NewDiskCache(dir, &CacheOpts{MaxEntries: 2}); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"b", "c"} {
+		if data, ok := c.Get(key); !ok || string(data) != "data "+key {
+			t.Errorf("expected entry %s to be reloaded, got %q", key, data)
+		}
+	}
+	c.Set("d", []byte("data d"))
+	if _, ok := c.Get("b"); ok {
+		t.Error("expected least recently used reloaded entry to be evicted")
+	}
+
+	// Expired entries are removed when reloading.
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "c.cache"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if c, err = NewDiskCache(dir, &CacheOpts{TTL: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Get("c"); ok {
+		t.Error("expected expired entry not to be reloaded")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "c.cache")); !os.IsNotExist(err) {
+		t.Errorf("expected expired entry to be removed, got %v", err)
+	}
+	if _, ok := c.Get("d"); !ok {
+		t.Error("expected entry d to be reloaded")
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	dir := t.TempDir()
+	page := filepath.Join(dir, "page.html")
+	writePage := func(content string) {
+		if err := os.WriteFile(page, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var etag string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if etag != "" {
+			w.Header().Set("ETag", etag)
+		}
+	}))
+	defer server.Close()
+
+	key := func(location string, remote bool, modify func(*ConverterOpts, *ObjectOpts)) string {
+		converterOpts, objectOpts := NewConverterOpts(), NewObjectOpts()
+		objectOpts.Location = location
+		if modify != nil {
+			modify(converterOpts, objectOpts)
+		}
+		return cacheKey("0.12.6", converterOpts, []*Object{{ObjectOpts: objectOpts}}, remote)
+	}
+
+	writePage("<h1>Hello</h1>")
+	base := key(page, false, nil)
+	if base == "" || !validCacheKey(base) {
+		t.Fatalf("invalid cache key %q", base)
+	}
+	if k := key(page, false, nil); k != base {
+		t.Error("expected identical conversions to have the same key")
+	}
+	if k := key("file://"+filepath.ToSlash(page), false, nil); k == "" {
+		t.Error("expected file URLs to be cached")
+	}
+	if k := key(page, false, func(c *ConverterOpts, _ *ObjectOpts) { c.Title = "Title" }); k == base {
+		t.Error("expected converter options to change the key")
+	}
+	if k := key(page, false, func(_ *ConverterOpts, o *ObjectOpts) { o.Zoom = Float64(2) }); k == base {
+		t.Error("expected object options to change the key")
+	}
+	if k := cacheKey("0.12.5", NewConverterOpts(), []*Object{{ObjectOpts: &ObjectOpts{Location: page}}}, false); k == base {
+		t.Error("expected library version to change the key")
+	}
+
+	writePage("<h1>Changed</h1>")
+	if k := key(page, false, nil); k == base {
+		t.Error("expected document content to change the key")
+	}
+	if k := key(filepath.Join(dir, "missing.html"), false, nil); k != "" {
+		t.Error("expected missing documents not to be cached")
+	}
+
+	// Remote documents are only cached if enabled and if they have
+	// validators.
+	etag = `"v1"`
+	if k := key(server.URL, false, nil); k != "" {
+		t.Error("expected remote documents not to be cached by default")
+	}
+	remote := key(server.URL, true, nil)
+	if remote == "" {
+		t.Fatal("expected remote documents with validators to be cached")
+	}
+	etag = `"v2"`
+	if k := key(server.URL, true, nil); k == "" || k == remote {
+		t.Error("expected remote document validators to change the key")
+	}
+	etag = ""
+	if k := key(server.URL, true, nil); k != "" {
+		t.Error("expected remote documents without validators not to be cached")
+	}
+}
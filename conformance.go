@@ -0,0 +1,145 @@
+package pdf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/adrg/go-wkhtmltopdf/internal/pdfa"
+	"github.com/adrg/go-wkhtmltopdf/internal/pdfdoc"
+)
+
+// Conformance represents a PDF/A conformance level of the output document.
+type Conformance string
+
+// Conformance values.
+const (
+	PDFA1B Conformance = "PDF/A-1b"
+	PDFA2B Conformance = "PDF/A-2b"
+	PDFA3B Conformance = "PDF/A-3b"
+)
+
+func (c Conformance) part() (int, error) {
+	switch c {
+	case PDFA1B:
+		return 1, nil
+	case PDFA2B:
+		return 2, nil
+	case PDFA3B:
+		return 3, nil
+	}
+
+	return 0, fmt.Errorf("invalid conformance level: %q", string(c))
+}
+
+// Violation describes a conformance requirement which is not fulfilled by
+// a document.
+type Violation struct {
+	// The reference of the PDF object which violates the requirement.
+	// The field is empty for violations which concern the whole document.
+	// E.g.: "12 0 R".
+	Object string `json:"object,omitempty" yaml:"object,omitempty"`
+
+	// The description of the violated requirement.
+	Message string `json:"message" yaml:"message"`
+}
+
+// String returns a textual representation of the violation.
+func (v Violation) String() string {
+	if v.Object == "" {
+		return v.Message
+	}
+
+	return fmt.Sprintf("%s (object %s)", v.Message, v.Object)
+}
+
+// ConformanceError is returned by the converter if the output document
+// cannot be made to conform to the requested conformance level.
+type ConformanceError struct {
+	Conformance Conformance
+	Violations  []Violation
+}
+
+// Error returns the description of the error.
+func (e *ConformanceError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		messages = append(messages, v.String())
+	}
+
+	return fmt.Sprintf("output document does not conform to %s: %s",
+		e.Conformance, strings.Join(messages, "; "))
+}
+
+// Validate checks the PDF document read from r against the requirements of
+// the specified conformance level and returns the detected violations. The
+// validation covers the document structure, metadata, fonts, actions,
+// annotations and transparency, but does not analyze page content streams.
+// An empty list of violations is returned for conforming documents.
+func Validate(r io.Reader, conformance Conformance) ([]Violation, error) {
+	part, err := conformance.part()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := pdfdoc.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return validate(doc, part)
+}
+
+func validate(doc *pdfdoc.Document, part int) ([]Violation, error) {
+	results, err := pdfa.Validate(doc, part)
+	if err != nil {
+		return nil, err
+	}
+
+	return newViolations(results), nil
+}
+
+func newViolations(results []pdfa.Violation) []Violation {
+	violations := make([]Violation, 0, len(results))
+	for _, result := range results {
+		v := Violation{Message: result.Message}
+		if result.Object.Num > 0 {
+			v.Object = result.Object.String()
+		}
+		violations = append(violations, v)
+	}
+
+	return violations
+}
+
+// makeConformant adjusts the specified document in order to conform to the
+// requested conformance level. A ConformanceError is returned if the
+// document still violates any requirements after being adjusted.
+func makeConformant(doc *pdfdoc.Document, conformance Conformance) error {
+	part, err := conformance.part()
+	if err != nil {
+		return err
+	}
+	if err := pdfa.Convert(doc, part); err != nil {
+		var fontErr *pdfa.FontError
+		if errors.As(err, &fontErr) {
+			return &ConformanceError{Conformance: conformance, Violations: newViolations(fontErr.Violations)}
+		}
+		return err
+	}
+
+	violations, err := validate(doc, part)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return &ConformanceError{Conformance: conformance, Violations: violations}
+	}
+
+	return nil
+}
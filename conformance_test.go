@@ -0,0 +1,83 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/adrg/go-wkhtmltopdf/internal/pdfdoc"
+)
+
+func TestMakeConformant(t *testing.T) {
+	tests := []struct {
+		name       string
+		font       string
+		violations []Violation
+	}{
+		{
+			name: "embedded font",
+			font: "<< /Type /Font /Subtype /TrueType /BaseFont /ABCDEF+DejaVuSans /FontDescriptor 5 0 R >>",
+		},
+		{
+			name:       "non-embedded font",
+			font:       "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+			violations: []Violation{{Object: "4 0 R", Message: "font Helvetica is not embedded"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := pdfdoc.Parse(testDocument([]string{
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+				"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Resources << /Font << /F1 4 0 R >> >> >>",
+				test.font,
+				"<< /Type /FontDescriptor /FontName /ABCDEF+DejaVuSans /FontFile2 6 0 R >>",
+				"<< /Length 4 >>\nstream\nfont\nendstream",
+			}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = makeConformant(doc, PDFA2B)
+			if test.violations == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			var conformanceErr *ConformanceError
+			if !errors.As(err, &conformanceErr) {
+				t.Fatalf("expected conformance error, got %v", err)
+			}
+			if !reflect.DeepEqual(conformanceErr.Violations, test.violations) {
+				t.Errorf("expected violations %v, got %v", test.violations, conformanceErr.Violations)
+			}
+		})
+	}
+}
+
+// testDocument returns a PDF document containing the specified objects.
+// The first object must be the document catalog.
+func testDocument(objects []string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f\r\n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n\r\n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return buf.Bytes()
+}
@@ -26,191 +26,27 @@ static inline void converter_initialize_callbacks(wkhtmltopdf_converter* c) {
 import "C"
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"strings"
+	"sync"
+	"time"
 	"unsafe"
 )
 
-// Colorspace represents the color mode of the output document content.
-type Colorspace string
-
-// Colorspace values.
-const (
-	Color     Colorspace = "Color"
-	Grayscale Colorspace = "Grayscale"
-)
-
-// Orientation represents the orientation of the output document pages.
-type Orientation string
-
-// Page orientation values.
-const (
-	Portrait  Orientation = "Portrait"
-	Landscape Orientation = "Landscape"
-)
-
-// PaperSize represents the size of the output document pages.
-type PaperSize string
-
-// Paper size values.
-const (
-	A0        PaperSize = "A0"        // 841 x 1189 mm
-	A1        PaperSize = "A1"        // 594 x 841 mm
-	A2        PaperSize = "A2"        // 420 x 594 mm
-	A3        PaperSize = "A3"        // 297 x 420 mm
-	A4        PaperSize = "A4"        // 210 x 297 mm
-	A5        PaperSize = "A5"        // 148 x 210 mm
-	A6        PaperSize = "A6"        // 105 x 148 mm
-	A7        PaperSize = "A7"        // 74 x 105 mm
-	A8        PaperSize = "A8"        // 52 x 74 mm
-	A9        PaperSize = "A9"        // 37 x 52 mm
-	B0        PaperSize = "B0"        // 1000 x 1414 mm
-	B1        PaperSize = "B1"        // 707 x 1000 mm
-	B2        PaperSize = "B2"        // 500 x 707 mm
-	B3        PaperSize = "B3"        // 353 x 500 mm
-	B4        PaperSize = "B4"        // 250 x 353 mm
-	B5        PaperSize = "B5"        // 176 x 250 mm
-	B6        PaperSize = "B6"        // 125 x 176 mm
-	B7        PaperSize = "B7"        // 88 x 125 mm
-	B8        PaperSize = "B8"        // 62 x 88 mm
-	B9        PaperSize = "B9"        // 33 x 62 mm
-	B10       PaperSize = "B10"       // 31 x 44 mm
-	C5E       PaperSize = "C5E"       // 163 x 229 mm
-	Comm10E   PaperSize = "Comm10E"   // 105 x 241 mm
-	DLE       PaperSize = "DLE"       // 110 x 220 mm
-	Executive PaperSize = "Executive" // 190.5 x 254 mm
-	Folio     PaperSize = "Folio"     // 210 x 330 mm
-	Ledger    PaperSize = "Ledger"    // 431.8 x 279.4 mm
-	Legal     PaperSize = "Legal"     // 215.9 x 355.6 mm
-	Letter    PaperSize = "Letter"    // 215.9 x 279.4 mm
-	Tabloid   PaperSize = "Tabloid"   // 279.4 x 431.8 mm
-)
-
-// ConverterOpts defines a set of options to be used in the conversion process.
-type ConverterOpts struct {
-	// The paper size of the output document.
-	// E.g.: A4.
-	PaperSize PaperSize `json:"paperSize" yaml:"paperSize"`
-
-	// The width of the output document.
-	// E.g.: "4cm".
-	Width string `json:"width" yaml:"width"`
-
-	// The height of the output document.
-	// E.g. "12in".
-	Height string `json:"height" yaml:"height"`
-
-	// The orientation of the output document.
-	// E.g.: Portrait.
-	Orientation Orientation `json:"orientation" yaml:"orientation"`
-
-	// The color mode of the output document.
-	// E.g.: Color.
-	Colorspace Colorspace `json:"colorspace" yaml:"colorspace"`
-
-	// DPI of the output document.
-	// E.g.: 96.
-	DPI uint64 `json:"dpi" yaml:"dpi"`
-
-	// A number added to all page numbers when rendering headers, footers and
-	// tables of contents.
-	PageOffset int64 `json:"pageOffset" yaml:"pageOffset"`
-
-	// Copies of the converted documents to be included in the output document.
-	// E.g.: 1.
-	Copies uint64 `json:"copies" yaml:"copies"`
-
-	// Specifies whether copies should be collated.
-	Collate bool `json:"collate" yaml:"collate"`
-
-	// The title of the output document.
-	Title string `json:"title" yaml:"title"`
-
-	// Specifies whether outlines should be generated for the output document.
-	GenerateOutline bool `json:"generateOutline" yaml:"generateOutline"`
-
-	// The maximum number of nesting levels in outlines.
-	// E.g.: 4.
-	OutlineDepth uint64 `json:"outlineDepth" yaml:"outlineDepth"`
-
-	// A location to write an XML representation of the generated outlines.
-	OutlineDumpPath string `json:"outlineDumpPath" yaml:"outlineDumpPath"`
-
-	// Specifies whether the conversion process should use lossless compression.
-	UseCompression bool `json:"useCompression" yaml:"useCompression"`
-
-	// Size of the top margin. (e.g. "2cm")
-	// E.g.: "1cm".
-	MarginTop string `json:"marginTop" yaml:"marginTop"`
-
-	// Size of the bottom margin. (e.g. "2cm")
-	// E.g.: "1cm".
-	MarginBottom string `json:"marginBottom" yaml:"marginBottom"`
-
-	// Size of the left margin. (e.g. "2cm")
-	// E.g.: "10mm".
-	MarginLeft string `json:"marginLeft" yaml:"marginLeft"`
-
-	// Size of the right margin. (e.g. "2cm")
-	// E.g.: "10mm".
-	MarginRight string `json:"marginRight" yaml:"marginRight"`
-
-	// The maximum number of DPI for the images in the output document.
-	// E.g.: 600.
-	ImageDPI uint64 `json:"imageDPI" yaml:"imageDPI"`
-
-	// The compression factor to use for the JPEG images in the output document.
-	// E.g.: 100 (range 0-100).
-	ImageQuality uint64 `json:"imageQuality" yaml:"imageQuality"`
-
-	// Path of the file used to load and store cookies for web objects.
-	CookieJarPath string `json:"cookieJarPath" yaml:"cookieJarPath"`
-}
-
-// NewConverterOpts returns a new instance of converter options, configured
-// using sensible defaults.
-//
-//   Defaults options:
-//
-//   PaperSize:       A4
-//   Orientation:     Portrait
-//   Colorspace:      Color
-//   DPI:             96
-//   Copies:          1
-//   Collate:         true
-//   GenerateOutline: true
-//   UseCompression:  true
-//   MarginLeft:      "10mm"
-//   MarginRight:     "10mm"
-//   ImageDPI:        600
-//   ImageQuality:    100
-func NewConverterOpts() *ConverterOpts {
-	return &ConverterOpts{
-		PaperSize:       A4,
-		Orientation:     Portrait,
-		Colorspace:      Color,
-		DPI:             96,
-		Copies:          1,
-		Collate:         true,
-		GenerateOutline: true,
-		UseCompression:  true,
-		MarginLeft:      "10mm",
-		MarginRight:     "10mm",
-		ImageDPI:        600,
-		ImageQuality:    100,
-	}
-}
-
 // Converter represents an HTML to PDF converter. The contained options are
-// applied to all converted objects.
+// applied to all converted objects. Converter implements the Renderer
+// interface.
 type Converter struct {
 	*ConverterOpts
-	converter *C.wkhtmltopdf_converter
-	settings  *C.wkhtmltopdf_global_settings
-	objects   []*Object
-	phases    []string
+	*converterHandle
+	phases   []string
+	state    *runState
+	ran      bool
+	progress *progressTracker
 
 	// Warning is called when a warning is issued in the conversion process.
 	Warning func(msg string)
@@ -227,6 +63,45 @@ type Converter struct {
 
 	// Finished is called when the conversion process ends.
 	Finished func(success bool)
+
+	// OverallProgressChanged is called when the overall progress of the
+	// conversion changes. Unlike ProgressChanged, the progress is aggregated
+	// across all conversion phases, so it increases monotonically.
+	OverallProgressChanged func(progress Progress)
+
+	// ProgressEstimator determines the phase weights used for computing the
+	// overall progress. If not specified, DefaultProgressEstimator is used.
+	ProgressEstimator *ProgressEstimator
+
+	// Cache stores the output documents generated by Run. Conversions with
+	// identical options and documents are served from the cache, without
+	// calling the callbacks of the converter. If not specified, the output
+	// documents are not cached. See Cache for more information.
+	Cache Cache
+
+	// Specifies whether conversions of remote (HTTP and HTTPS) documents are
+	// cached. Remote documents are identified by their ETag or Last-Modified
+	// headers, which are retrieved by Run using HEAD requests, blocking the
+	// main thread. If not enabled, conversions of remote documents are not
+	// cached.
+	CacheRemote bool
+}
+
+var _ Renderer = (*Converter)(nil)
+
+// converterHandle contains the resources of a converter which are released
+// when the converter is destroyed. Handles are stored in the object registry,
+// which is used for dispatching the callbacks of the library and by Shutdown
+// for destroying the remaining converters. A handle references its converter
+// only while a conversion is in progress, so that converters which are not
+// destroyed can be garbage collected, and reported in debug builds.
+type converterHandle struct {
+	mu        sync.Mutex
+	converter *C.wkhtmltopdf_converter
+	settings  *C.wkhtmltopdf_global_settings
+	objects   []*Object
+	events    eventStream
+	running   *Converter
 }
 
 // NewConverter returns a new converter instance, configured using sensible
@@ -243,23 +118,107 @@ func NewConverterWithOpts(opts *ConverterOpts) (*Converter, error) {
 		opts = NewConverterOpts()
 	}
 
+	converter := &Converter{ConverterOpts: opts, converterHandle: &converterHandle{}}
+	if err := converter.init(); err != nil {
+		return nil, err
+	}
+	trackResource(converter, "converter", func(c *Converter) bool {
+		return c.converter == nil
+	})
+
+	return converter, nil
+}
+
+// NewConverterFromProfile returns a new converter instance, configured using
+// the profile with the specified name. See LoadProfiles for loading profiles.
+func NewConverterFromProfile(name string) (*Converter, error) {
+	opts, err := NewConverterOptsFromProfile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewConverterWithOpts(opts)
+}
+
+// NewConverterFromJob returns a new converter instance, configured using the
+// converter options of the specified job, with the objects of the job added
+// to it. It can be used instead of RunJob in order to set converter
+// callbacks or to retrieve conversion events before running the converter.
+// The caller is responsible for destroying the returned converter.
+func NewConverterFromJob(job *Job) (*Converter, error) {
+	if job == nil {
+		return nil, errors.New("the provided job cannot be nil")
+	}
+	if len(job.Objects) == 0 {
+		return nil, errors.New("must specify at least one object to convert")
+	}
+
+	opts := job.Converter
+	converter, err := NewConverterWithOpts(&opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, spec := range job.Objects {
+		object, err := spec.object()
+		if err != nil {
+			converter.Destroy()
+			return nil, fmt.Errorf("invalid object %d: %w", i, err)
+		}
+		if err := converter.Add(object); err != nil {
+			object.Destroy()
+			converter.Destroy()
+			return nil, err
+		}
+	}
+
+	return converter, nil
+}
+
+// RunJob creates a converter and the objects of the specified job, performs
+// the conversion, copies the output to the provided writer and releases all
+// the created resources. The context is checked before the conversion
+// starts, as the conversion itself cannot be interrupted. Due to a
+// limitation of the `wkhtmltox` library, this function must be called on
+// the main thread.
+func RunJob(ctx context.Context, job *Job, w io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	converter, err := NewConverterFromJob(job)
+	if err != nil {
+		return err
+	}
+	defer converter.Destroy()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return converter.Run(w)
+}
+
+func (c *Converter) init() error {
+	if err := lib.add(&lib.converters); err != nil {
+		return err
+	}
+
 	// Create converter settings.
 	settings := C.wkhtmltopdf_create_global_settings()
 	if settings == nil {
-		return nil, errors.New("could not create converter settings")
+		lib.remove(&lib.converters)
+		return errors.New("could not create converter settings")
 	}
 
-	// Create converter.
+	// Create converter. The converter takes ownership of the settings.
 	cConverter := C.wkhtmltopdf_create_converter(settings)
 	if cConverter == nil {
-		return nil, errors.New("could not create converter")
-	}
-
-	converter := &Converter{
-		ConverterOpts: opts,
-		converter:     cConverter,
-		settings:      settings,
+		C.wkhtmltopdf_destroy_global_settings(settings)
+		lib.remove(&lib.converters)
+		return errors.New("could not create converter")
 	}
+	c.converter, c.settings, c.ran = cConverter, settings, false
 
 	// Initialize converter callbacks.
 	C.converter_initialize_callbacks(cConverter)
@@ -267,82 +226,276 @@ func NewConverterWithOpts(opts *ConverterOpts) (*Converter, error) {
 	// Retrieve conversion phases.
 	phaseCount := int(C.wkhtmltopdf_phase_count(cConverter))
 
-	converter.phases = make([]string, phaseCount)
+	c.phases = make([]string, phaseCount)
 	for i := 0; i < phaseCount; i++ {
-		converter.phases[i] = C.GoString(C.wkhtmltopdf_phase_description(cConverter, C.int(i)))
+		c.phases[i] = C.GoString(C.wkhtmltopdf_phase_description(cConverter, C.int(i)))
 	}
 
 	// Add converter to object registry.
-	registry.add(objectID(cConverter), converter)
+	registry.add(objectID(cConverter), c.converterHandle)
 
-	return converter, nil
+	return nil
+}
+
+// Reset destroys the objects added to the converter and recreates the
+// underlying library converter, so that the converter can be reused for
+// performing another conversion. The converter options and callbacks are
+// preserved. Objects have to be added again before running the converter.
+func (c *Converter) Reset() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.converter == nil {
+		return errors.New("cannot use uninitialized or destroyed converter")
+	}
+
+	c.destroy()
+	return c.init()
+}
+
+// Events returns a channel which delivers the events of the conversion
+// process, as an alternative to the callback fields of the converter, which
+// are called synchronously on the main thread. The channel has a bounded
+// buffer, and when it is full, the oldest events are discarded, so that the
+// conversion is never blocked. The channel should be retrieved before the
+// conversion is performed, and it is closed when the converter is destroyed.
+func (c *Converter) Events() <-chan Event {
+	return c.events.channel()
+}
+
+// SetCallbacks sets the functions called during the conversion process.
+// It is equivalent to setting the callback fields of the converter.
+func (c *Converter) SetCallbacks(callbacks Callbacks) {
+	c.Warning = callbacks.Warning
+	c.Error = callbacks.Error
+	c.PhaseChanged = callbacks.PhaseChanged
+	c.ProgressChanged = callbacks.ProgressChanged
+	c.Finished = callbacks.Finished
+	c.OverallProgressChanged = callbacks.OverallProgressChanged
+}
+
+func (c *Converter) updateProgress(progress Progress, changed bool) {
+	if !changed {
+		return
+	}
+
+	c.emit(EventOverallProgressChanged, progress.PhaseIndex, func(e *Event) {
+		e.Progress = progress.PhasePercent
+		e.OverallProgress, e.ETA = progress.Percent, progress.ETA
+	})
+	if c.OverallProgressChanged != nil {
+		c.OverallProgressChanged(progress)
+	}
+}
+
+func (c *Converter) emit(eventType EventType, phaseIndex int, fn func(*Event)) {
+	event := Event{
+		Type:             eventType,
+		Time:             time.Now(),
+		PhaseIndex:       phaseIndex,
+		PhaseDescription: c.PhaseDescription(phaseIndex),
+	}
+	if fn != nil {
+		fn(&event)
+	}
+
+	c.events.emit(event)
 }
 
 // Add appends the specified object to the list of objects to be converted.
-func (c *Converter) Add(object *Object) {
+// The converter takes ownership of the object, which is destroyed along with
+// the converter. An object can only be added to a single converter, and only
+// before the conversion is performed.
+func (c *Converter) Add(object *Object) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.converter == nil {
+		return errors.New("cannot use uninitialized or destroyed converter")
+	}
+	if object == nil {
+		return errors.New("the provided object cannot be nil")
+	}
+	if object.settings == nil {
+		return errors.New("cannot use uninitialized or destroyed object")
+	}
+	if c.ran {
+		return ErrAlreadyRun
+	}
+
+	switch object.owner {
+	case nil:
+	case c.converterHandle:
+		return errors.New("object already added to the converter")
+	default:
+		return ErrObjectOwned
+	}
+
+	object.owner = c.converterHandle
 	c.objects = append(c.objects, object)
+	return nil
 }
 
 // Run performs the conversion and copies the output to the provided writer.
-// Due to a limitation of the `wkhtmltox` library, this method must be called
-// on the main thread.
+// A converter can only perform a single conversion. Subsequent calls return
+// ErrAlreadyRun, until the converter is reset using Reset. Due to a
+// limitation of the `wkhtmltox` library, this method must be called on the
+// main thread.
 func (c *Converter) Run(w io.Writer) error {
+	_, err := c.run(w, false)
+	return err
+}
+
+// RunWithResult performs the conversion, copies the output to the provided
+// writer and returns information about the conversion, such as the number
+// of pages of the output document and the pages occupied by each of the
+// converted objects. Due to a limitation of the `wkhtmltox` library, this
+// method must be called on the main thread.
+func (c *Converter) RunWithResult(w io.Writer) (*Result, error) {
+	return c.run(w, true)
+}
+
+func (c *Converter) run(w io.Writer, withResult bool) (*Result, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if c.converter == nil {
-		return errors.New("cannot use uninitialized or destroyed converter")
+		return nil, errors.New("cannot use uninitialized or destroyed converter")
 	}
 	if w == nil {
-		return errors.New("the provided writer cannot be nil")
+		return nil, errors.New("the provided writer cannot be nil")
+	}
+
+	if c.ran {
+		return nil, ErrAlreadyRun
+	}
+	if err := lib.begin(); err != nil {
+		return nil, err
 	}
+	defer lib.end()
 
-	// Set converter and object options.
 	if len(c.objects) == 0 {
-		return errors.New("must add at least one object to convert")
+		return nil, errors.New("must add at least one object to convert")
 	}
+
+	// Serve the output document from the cache, if possible. The conversion
+	// information returned by RunWithResult and the outline dump are only
+	// available when the conversion is performed, so they are not cached.
+	var key string
+	if c.Cache != nil && !withResult && c.OutlineDumpPath == "" {
+		if key = cacheKey(Version(), c.ConverterOpts, c.objects, c.CacheRemote); key != "" {
+			if data, ok := c.Cache.Get(key); ok {
+				c.ran = true
+				_, err := io.Copy(w, bytes.NewReader(data))
+				return nil, err
+			}
+		}
+	}
+
+	// Set converter and object options. The objects are passed to the library
+	// along with their options, so the converter cannot be run again, even
+	// if the conversion fails.
+	c.ran = true
 	if err := c.setOptions(); err != nil {
-		return err
+		return nil, err
 	}
 
-	// Convert objects.
+	// Collect conversion information, if requested. The outline of the
+	// output document is used to identify the pages of the converted
+	// objects, so it is dumped to a temporary file if required.
+	if withResult {
+		state := newRunState(c.phases)
+
+		state.outlinePath = c.OutlineDumpPath
+		if state.outlinePath == "" {
+			f, err := os.CreateTemp("", "wkhtmltopdf-outline-*.xml")
+			if err != nil {
+				return nil, err
+			}
+			f.Close()
+			defer os.Remove(f.Name())
+
+			if err := c.setOption("dumpOutline", f.Name()); err != nil {
+				return nil, err
+			}
+			state.outlinePath = f.Name()
+		}
+
+		c.state = state
+		defer func() { c.state = nil }()
+	}
+
+	// Track the overall progress of the conversion.
+	c.progress = newProgressTracker(c.ProgressEstimator, c.phases, len(c.objects))
+	defer func() { c.progress = nil }()
+
+	// Convert objects. The converter is referenced by its handle during the
+	// conversion, so that the callbacks of the library can be dispatched to it.
+	c.running = c
+	defer func() { c.running = nil }()
+
 	if C.wkhtmltopdf_convert(c.converter) != 1 {
-		return errors.New("could not convert the added objects")
+		return nil, errors.New("could not convert the added objects")
 	}
 
 	// Get conversion output buffer.
 	var output *C.uchar
 	size := C.wkhtmltopdf_get_output(c.converter, &output)
 	if size == 0 {
-		return errors.New("could not retrieve the converted file")
+		return nil, errors.New("could not retrieve the converted file")
+	}
+
+	// Post-process conversion output.
+	data, err := c.postProcess(C.GoBytes(unsafe.Pointer(output), C.int(size)))
+	if err != nil {
+		return nil, err
+	}
+	if key != "" {
+		c.Cache.Set(key, data)
 	}
 
 	// Copy output to the provided writer.
-	buf := bytes.NewBuffer(C.GoBytes(unsafe.Pointer(output), C.int(size)))
-	if _, err := io.Copy(w, buf); err != nil {
-		return err
+	if _, err := io.Copy(w, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	if c.state == nil {
+		return nil, nil
 	}
 
-	return nil
+	return c.state.result(data, c.OutputFormat, len(c.objects), c.PageOffset)
 }
 
-// Destroy releases all resources used by the converter.
+// Destroy releases all resources used by the converter, including the
+// objects added to it. Destroy must not be called from the callbacks of the
+// converter, as it waits for the conversion in progress to finish.
 func (c *Converter) Destroy() {
-	// Destroy settings.
-	if c.settings != nil {
-		C.wkhtmltopdf_destroy_global_settings(c.settings)
-		c.settings = nil
-	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.destroy()
+	c.events.close()
+}
 
-	// Destroy converter.
-	if c.converter != nil {
-		registry.remove(objectID(c.converter))
-		C.wkhtmltopdf_destroy_converter(c.converter)
-		c.converter = nil
+func (h *converterHandle) destroy() {
+	// Destroy converter. The library releases the converter settings along
+	// with the settings of the objects passed to the converter.
+	if h.converter != nil {
+		registry.remove(objectID(h.converter))
+		C.wkhtmltopdf_destroy_converter(h.converter)
+		h.converter, h.settings = nil, nil
+		lib.remove(&lib.converters)
 	}
 
 	// Destroy converter objects.
-	for _, o := range c.objects {
+	for _, o := range h.objects {
+		if o.added {
+			o.settings, o.added = nil, false
+			lib.remove(&lib.objects)
+		}
+		o.owner = nil
 		o.Destroy()
 	}
-	c.objects = nil
+	h.objects = nil
 }
 
 // Phases returns the list of phases undergone in the conversion process.
@@ -382,8 +535,63 @@ func (c *Converter) setOption(name, value string) error {
 	return nil
 }
 
-func (c *Converter) setOptions() error {
-	setter := c.setOption
+// Option returns the value of the global setting with the specified name,
+// as reported by the `wkhtmltox` library. The converter options are passed
+// to the library when the conversion is performed, so the returned values
+// reflect the options used by the last conversion.
+func (c *Converter) Option(name string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.option(name)
+}
+
+func (c *Converter) option(name string) (string, error) {
+	if c.settings == nil {
+		return "", errors.New("cannot use uninitialized or destroyed converter")
+	}
+	if name = strings.TrimSpace(name); name == "" {
+		return "", errors.New("converter option name cannot be empty")
+	}
+
+	n := C.CString(name)
+	defer C.free(unsafe.Pointer(n))
+
+	for size := 1024; size <= maxOptionValueSize; size *= 4 {
+		buf := (*C.char)(C.calloc(C.size_t(size), 1))
+		if C.wkhtmltopdf_get_global_setting(c.settings, n, buf, C.int(size)) != 1 {
+			C.free(unsafe.Pointer(buf))
+			return "", fmt.Errorf("could not get converter option `%s`", name)
+		}
+
+		value := C.GoString(buf)
+		C.free(unsafe.Pointer(buf))
+		if len(value) < size-1 {
+			return value, nil
+		}
+	}
+
+	return "", fmt.Errorf("value of converter option `%s` is too large", name)
+}
+
+// EffectiveSettings returns the values of the global settings used by the
+// `wkhtmltox` library, keyed by setting name. The settings are retrieved
+// from the library, so they reflect the options used by the last conversion.
+// The returned settings include all the scalar global settings documented
+// by the library, along with the raw options. List settings and settings
+// which cannot be retrieved from the library are omitted.
+func (c *Converter) EffectiveSettings() (map[string]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.settings == nil {
+		return nil, errors.New("cannot use uninitialized or destroyed converter")
+	}
+
+	return effectiveSettings(globalSettingNames, c.setOps(nil), c.option), nil
+}
+
+func (c *Converter) setOps(setter setterFunc) []*setOp {
 	opts := []*setOp{
 		newSetOp("size.pageSize", string(c.PaperSize), optTypeString, setter, false),
 		newSetOp("size.width", c.Width, optTypeString, setter, false),
@@ -391,6 +599,8 @@ func (c *Converter) setOptions() error {
 		newSetOp("orientation", string(c.Orientation), optTypeString, setter, false),
 		newSetOp("colorMode", string(c.Colorspace), optTypeString, setter, false),
 		newSetOp("dpi", c.DPI, optTypeUint, setter, false),
+		newSetOp("resolution", string(c.Resolution), optTypeString, setter, false),
+		newSetOp("viewportSize", c.ViewportSize, optTypeString, setter, false),
 		newSetOp("pageOffset", c.PageOffset, optTypeInt, setter, true),
 		newSetOp("copies", c.Copies, optTypeUint, setter, false),
 		newSetOp("collate", c.Collate, optTypeBool, setter, true),
@@ -406,10 +616,18 @@ func (c *Converter) setOptions() error {
 		newSetOp("imageDPI", c.ImageDPI, optTypeUint, setter, false),
 		newSetOp("imageQuality", c.ImageQuality, optTypeUint, setter, false),
 		newSetOp("load.cookieJar", c.CookieJarPath, optTypeString, setter, true),
+		newSetOp("outputFormat", string(c.OutputFormat), optTypeString, setter, false),
+		newSetOp("resolveRelativeLinks", !c.KeepRelativeLinks, optTypeBool, setter, true),
+		newSetOp("useGraphics", c.UseGraphics, optTypeBool, setter, true),
+		newSetOp("quiet", c.Quiet, optTypeBool, setter, true),
 		newSetOp("out", "", optTypeString, setter, true),
 	}
 
-	for _, opt := range opts {
+	return append(opts, newExtraSetOps(c.Extra, setter)...)
+}
+
+func (c *Converter) setOptions() error {
+	for _, opt := range c.setOps(c.setOption) {
 		if err := opt.execute(); err != nil {
 			return err
 		}
@@ -422,6 +640,7 @@ func (c *Converter) setOptions() error {
 		}
 
 		C.wkhtmltopdf_add_object(c.converter, o.settings, nil)
+		o.added = true
 	}
 
 	return nil
@@ -430,41 +649,83 @@ func (c *Converter) setOptions() error {
 //export converterWarningCb
 func converterWarningCb(cConverter *C.wkhtmltopdf_converter, msg *C.cchar) {
 	converter := getConverterByID(objectID(cConverter))
-	if converter != nil && converter.Warning != nil {
-		converter.Warning(C.GoString(msg))
+	if converter == nil {
+		return
+	}
+
+	message := C.GoString(msg)
+	converter.state.addWarning(message)
+	converter.emit(EventWarning, converter.CurrentPhaseIndex(), func(e *Event) {
+		e.Message = message
+	})
+	if converter.Warning != nil {
+		converter.Warning(message)
 	}
 }
 
 //export converterErrorCb
 func converterErrorCb(cConverter *C.wkhtmltopdf_converter, msg *C.cchar) {
 	converter := getConverterByID(objectID(cConverter))
-	if converter != nil && converter.Error != nil {
-		converter.Error(C.GoString(msg))
+	if converter == nil {
+		return
+	}
+
+	message := C.GoString(msg)
+	converter.emit(EventError, converter.CurrentPhaseIndex(), func(e *Event) {
+		e.Message = message
+	})
+	if converter.Error != nil {
+		converter.Error(message)
 	}
 }
 
 //export converterPhaseChangedCb
 func converterPhaseChangedCb(cConverter *C.wkhtmltopdf_converter) {
 	converter := getConverterByID(objectID(cConverter))
-	if converter != nil && converter.PhaseChanged != nil {
-		converter.PhaseChanged(converter.CurrentPhaseIndex())
+	if converter == nil {
+		return
+	}
+
+	phaseIndex := converter.CurrentPhaseIndex()
+	converter.state.changePhase(phaseIndex)
+	converter.emit(EventPhaseChanged, phaseIndex, nil)
+	if converter.PhaseChanged != nil {
+		converter.PhaseChanged(phaseIndex)
 	}
+	converter.updateProgress(converter.progress.update(phaseIndex, 0))
 }
 
 //export converterProgressChangedCb
 func converterProgressChangedCb(cConverter *C.wkhtmltopdf_converter, progress C.int) {
 	converter := getConverterByID(objectID(cConverter))
-	if converter != nil && converter.ProgressChanged != nil {
+	if converter == nil {
+		return
+	}
+
+	phaseIndex := converter.CurrentPhaseIndex()
+	converter.emit(EventProgressChanged, phaseIndex, func(e *Event) {
+		e.Progress = int(progress)
+	})
+	if converter.ProgressChanged != nil {
 		converter.ProgressChanged(int(progress))
 	}
+	converter.updateProgress(converter.progress.update(phaseIndex, int(progress)))
 }
 
 //export converterFinishedCb
 func converterFinishedCb(cConverter *C.wkhtmltopdf_converter, status C.int) {
 	converter := getConverterByID(objectID(cConverter))
-	if converter != nil && converter.Finished != nil {
+	if converter == nil {
+		return
+	}
+
+	converter.emit(EventFinished, converter.CurrentPhaseIndex(), func(e *Event) {
+		e.Success = status == 1
+	})
+	if converter.Finished != nil {
 		converter.Finished(status == 1)
 	}
+	converter.updateProgress(converter.progress.finish(status == 1))
 }
 
 func getConverterByID(id objectID) *Converter {
@@ -473,6 +734,10 @@ func getConverterByID(id objectID) *Converter {
 		return nil
 	}
 
-	converter, _ := object.(*Converter)
-	return converter
+	handle, _ := object.(*converterHandle)
+	if handle == nil {
+		return nil
+	}
+
+	return handle.running
 }
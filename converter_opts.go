@@ -0,0 +1,271 @@
+package pdf
+
+// Colorspace represents the color mode of the output document content.
+type Colorspace string
+
+// Colorspace values.
+const (
+	Color     Colorspace = "Color"
+	Grayscale Colorspace = "Grayscale"
+)
+
+// Orientation represents the orientation of the output document pages.
+type Orientation string
+
+// Page orientation values.
+const (
+	Portrait  Orientation = "Portrait"
+	Landscape Orientation = "Landscape"
+)
+
+// PaperSize represents the size of the output document pages.
+type PaperSize string
+
+// Paper size values.
+const (
+	A0        PaperSize = "A0"        // 841 x 1189 mm
+	A1        PaperSize = "A1"        // 594 x 841 mm
+	A2        PaperSize = "A2"        // 420 x 594 mm
+	A3        PaperSize = "A3"        // 297 x 420 mm
+	A4        PaperSize = "A4"        // 210 x 297 mm
+	A5        PaperSize = "A5"        // 148 x 210 mm
+	A6        PaperSize = "A6"        // 105 x 148 mm
+	A7        PaperSize = "A7"        // 74 x 105 mm
+	A8        PaperSize = "A8"        // 52 x 74 mm
+	A9        PaperSize = "A9"        // 37 x 52 mm
+	B0        PaperSize = "B0"        // 1000 x 1414 mm
+	B1        PaperSize = "B1"        // 707 x 1000 mm
+	B2        PaperSize = "B2"        // 500 x 707 mm
+	B3        PaperSize = "B3"        // 353 x 500 mm
+	B4        PaperSize = "B4"        // 250 x 353 mm
+	B5        PaperSize = "B5"        // 176 x 250 mm
+	B6        PaperSize = "B6"        // 125 x 176 mm
+	B7        PaperSize = "B7"        // 88 x 125 mm
+	B8        PaperSize = "B8"        // 62 x 88 mm
+	B9        PaperSize = "B9"        // 33 x 62 mm
+	B10       PaperSize = "B10"       // 31 x 44 mm
+	C5E       PaperSize = "C5E"       // 163 x 229 mm
+	Comm10E   PaperSize = "Comm10E"   // 105 x 241 mm
+	DLE       PaperSize = "DLE"       // 110 x 220 mm
+	Executive PaperSize = "Executive" // 190.5 x 254 mm
+	Folio     PaperSize = "Folio"     // 210 x 330 mm
+	Ledger    PaperSize = "Ledger"    // 431.8 x 279.4 mm
+	Legal     PaperSize = "Legal"     // 215.9 x 355.6 mm
+	Letter    PaperSize = "Letter"    // 215.9 x 279.4 mm
+	Tabloid   PaperSize = "Tabloid"   // 279.4 x 431.8 mm
+)
+
+// Resolution represents the resolution mode used for the output document.
+type Resolution string
+
+// Resolution values.
+const (
+	ScreenResolution  Resolution = "screen"
+	PrinterResolution Resolution = "printer"
+	HighResolution    Resolution = "high"
+)
+
+// OutputFormat represents the file format of the output document.
+type OutputFormat string
+
+// Output format values.
+const (
+	PDF        OutputFormat = "pdf"
+	PostScript OutputFormat = "ps"
+)
+
+// ConverterOpts defines a set of options to be used in the conversion process.
+type ConverterOpts struct {
+	// The paper size of the output document.
+	// E.g.: A4.
+	PaperSize PaperSize `json:"paperSize" yaml:"paperSize"`
+
+	// The width of the output document.
+	// E.g.: "4cm".
+	Width string `json:"width" yaml:"width"`
+
+	// The height of the output document.
+	// E.g. "12in".
+	Height string `json:"height" yaml:"height"`
+
+	// The orientation of the output document.
+	// E.g.: Portrait.
+	Orientation Orientation `json:"orientation" yaml:"orientation"`
+
+	// The color mode of the output document.
+	// E.g.: Color.
+	Colorspace Colorspace `json:"colorspace" yaml:"colorspace"`
+
+	// DPI of the output document. A value of 0 is not a valid DPI, so it
+	// is treated as unset, in which case the library default is used.
+	// E.g.: 96.
+	DPI uint64 `json:"dpi" yaml:"dpi"`
+
+	// The resolution mode used for the output document. The library uses
+	// HighResolution by default. Most likely, the option has no effect.
+	// E.g.: HighResolution.
+	Resolution Resolution `json:"resolution" yaml:"resolution"`
+
+	// The size of the viewport used to render the converted objects, useful
+	// for content which depends on the size of the window. If not specified,
+	// the viewport size is determined by the paper size.
+	// E.g.: "1280x1024".
+	ViewportSize string `json:"viewportSize" yaml:"viewportSize"`
+
+	// A number added to all page numbers when rendering headers, footers and
+	// tables of contents.
+	PageOffset int64 `json:"pageOffset" yaml:"pageOffset"`
+
+	// Copies of the converted documents to be included in the output document.
+	// The output document cannot contain 0 copies, so a value of 0 is treated
+	// as unset, in which case a single copy is included.
+	// E.g.: 1.
+	Copies uint64 `json:"copies" yaml:"copies"`
+
+	// Specifies whether copies should be collated.
+	Collate bool `json:"collate" yaml:"collate"`
+
+	// The title of the output document.
+	Title string `json:"title" yaml:"title"`
+
+	// Specifies whether outlines should be generated for the output document.
+	GenerateOutline bool `json:"generateOutline" yaml:"generateOutline"`
+
+	// The maximum number of nesting levels in outlines. If not specified,
+	// the library default is used.
+	// E.g.: pdf.Uint64(4).
+	OutlineDepth *uint64 `json:"outlineDepth" yaml:"outlineDepth"`
+
+	// A location to write an XML representation of the generated outlines.
+	// The generated outlines are also included in the result returned by
+	// Converter.RunWithResult, regardless of this option.
+	OutlineDumpPath string `json:"outlineDumpPath" yaml:"outlineDumpPath"`
+
+	// Custom outline items to be added to the output document. The items
+	// are combined with the generated outlines based on the outline mode.
+	Outline []OutlineItem `json:"outline" yaml:"outline"`
+
+	// Specifies how custom outline items are combined with the generated
+	// outlines. By default, the generated outlines are replaced.
+	// E.g.: OutlineAppend.
+	OutlineMode OutlineMode `json:"outlineMode" yaml:"outlineMode"`
+
+	// Specifies whether the conversion process should use lossless compression.
+	UseCompression bool `json:"useCompression" yaml:"useCompression"`
+
+	// Size of the top margin. (e.g. "2cm")
+	// E.g.: "1cm".
+	MarginTop string `json:"marginTop" yaml:"marginTop"`
+
+	// Size of the bottom margin. (e.g. "2cm")
+	// E.g.: "1cm".
+	MarginBottom string `json:"marginBottom" yaml:"marginBottom"`
+
+	// Size of the left margin. (e.g. "2cm")
+	// E.g.: "10mm".
+	MarginLeft string `json:"marginLeft" yaml:"marginLeft"`
+
+	// Size of the right margin. (e.g. "2cm")
+	// E.g.: "10mm".
+	MarginRight string `json:"marginRight" yaml:"marginRight"`
+
+	// The maximum number of DPI for the images in the output document.
+	// A value of 0 is not a valid DPI, so it is treated as unset, in which
+	// case the library default is used. The library applies the same limit
+	// to all images, regardless of their size.
+	// E.g.: 600.
+	ImageDPI uint64 `json:"imageDPI" yaml:"imageDPI"`
+
+	// The compression factor to use for the JPEG images in the output document.
+	// If not specified, the library default is used.
+	// E.g.: pdf.Uint64(100) (range 0-100).
+	ImageQuality *uint64 `json:"imageQuality" yaml:"imageQuality"`
+
+	// Path of the file used to load and store cookies for web objects.
+	// The cookie jar is shared by all the objects of the conversion, as the
+	// library does not support per-object cookie jars.
+	CookieJarPath string `json:"cookieJarPath" yaml:"cookieJarPath"`
+
+	// The file format of the output document. PostScript output cannot be
+	// post-processed, so it cannot be used along with the options which
+	// alter the output document (e.g. Outline, Conformance).
+	// If not specified, PDF is used.
+	// E.g.: PostScript.
+	OutputFormat OutputFormat `json:"outputFormat" yaml:"outputFormat"`
+
+	// Specifies whether relative external links are kept as is in the output
+	// document, instead of being resolved to absolute links.
+	KeepRelativeLinks bool `json:"keepRelativeLinks" yaml:"keepRelativeLinks"`
+
+	// Specifies whether the graphics system of the X server is used for
+	// rendering. Requires the patched version of Qt.
+	UseGraphics bool `json:"useGraphics" yaml:"useGraphics"`
+
+	// Specifies whether the library should suppress its own output.
+	Quiet bool `json:"quiet" yaml:"quiet"`
+
+	// Raw global settings passed to the library as is, after the options
+	// above. Useful for settings which are not modeled by the other fields.
+	// E.g.: {"size.pageSize": "A5"}.
+	Extra map[string]string `json:"extra" yaml:"extra"`
+
+	// The PDF/A conformance level of the output document. If specified, the
+	// output document is post-processed in order to conform to the level:
+	// an sRGB output intent and XMP metadata are added, and the features
+	// which are not permitted by the level are removed. The content of the
+	// pages is not altered and font programs cannot be embedded by the
+	// post-processing, so the fonts must be embedded by the library. The
+	// conversion fails with a ConformanceError, instead of producing a
+	// non-conforming document, if the requirements of the level cannot be
+	// fulfilled (e.g. the document uses fonts which are not embedded).
+	// E.g.: PDFA2B.
+	Conformance Conformance `json:"conformance" yaml:"conformance"`
+
+	// Produce reproducible output documents. If enabled, the volatile fields
+	// of the output document, such as the creation and modification dates
+	// and the document identifier, are normalized, so that identical inputs
+	// produce identical output documents. The dates are set to the time
+	// specified by the SOURCE_DATE_EPOCH environment variable, if defined,
+	// and removed otherwise.
+	Reproducible bool `json:"reproducible" yaml:"reproducible"`
+}
+
+// NewConverterOpts returns a new instance of converter options, configured
+// using sensible defaults.
+//
+//	Defaults options:
+//
+//	PaperSize:       A4
+//	Orientation:     Portrait
+//	Colorspace:      Color
+//	DPI:             96
+//	Copies:          1
+//	Collate:         true
+//	GenerateOutline: true
+//	UseCompression:  true
+//	MarginLeft:      "10mm"
+//	MarginRight:     "10mm"
+//	ImageDPI:        600
+//	ImageQuality:    100
+//
+//	Options which are not set use the library defaults:
+//
+//	Resolution:      HighResolution
+//	OutputFormat:    PDF
+//	ViewportSize:    determined by the paper size
+func NewConverterOpts() *ConverterOpts {
+	return &ConverterOpts{
+		PaperSize:       A4,
+		Orientation:     Portrait,
+		Colorspace:      Color,
+		DPI:             96,
+		Copies:          1,
+		Collate:         true,
+		GenerateOutline: true,
+		UseCompression:  true,
+		MarginLeft:      "10mm",
+		MarginRight:     "10mm",
+		ImageDPI:        600,
+		ImageQuality:    Uint64(100),
+	}
+}
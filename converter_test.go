@@ -0,0 +1,46 @@
+//go:build cgo
+
+package pdf
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestConverterEvents(t *testing.T) {
+	converter, err := NewConverter()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ch := converter.Events()
+	for i := 0; i < eventBufferSize+10; i++ {
+		converter.emit(EventWarning, 0, func(e *Event) {
+			e.Message = fmt.Sprint("warning ", i)
+		})
+	}
+
+	// The events channel is closed when the converter is destroyed.
+	converter.Destroy()
+
+	var count int
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event, ok := <-ch:
+			if !ok {
+				if count != eventBufferSize {
+					t.Errorf("expected %d events, got %d", eventBufferSize, count)
+				}
+				return
+			}
+			if expected := fmt.Sprint("warning ", count+10); event.Message != expected {
+				t.Fatalf("expected message %q, got %q", expected, event.Message)
+			}
+			count++
+		case <-timeout:
+			t.Fatal("events channel was not closed")
+		}
+	}
+}
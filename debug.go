@@ -0,0 +1,36 @@
+//go:build pdfdebug
+
+package pdf
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"strings"
+)
+
+// trackResource sets a finalizer on the specified resource, which reports the
+// resource if it is garbage collected without being released, along with the
+// location it was created at.
+func trackResource[T any](resource *T, kind string, released func(*T) bool) {
+	pcs := make([]uintptr, 32)
+	pcs = pcs[:runtime.Callers(3, pcs)]
+
+	runtime.SetFinalizer(resource, func(r *T) {
+		if released(r) {
+			return
+		}
+
+		var sb strings.Builder
+		frames := runtime.CallersFrames(pcs)
+		for {
+			frame, more := frames.Next()
+			fmt.Fprintf(&sb, "\n\t%s\n\t\t%s:%d", frame.Function, frame.File, frame.Line)
+			if !more {
+				break
+			}
+		}
+
+		log.Printf("pdf: %s garbage collected without being destroyed, created at:%s", kind, sb.String())
+	})
+}
@@ -0,0 +1,110 @@
+//go:build pdfdebug && cgo
+
+package pdf
+
+import (
+	"bytes"
+	"log"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLeakDetection(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer Destroy()
+
+	var buf syncBuffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&buf)
+
+	leakResources(t)
+
+	expected := []string{
+		"pdf: object garbage collected without being destroyed",
+		"pdf: converter garbage collected without being destroyed",
+		"leakResources",
+	}
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); {
+		runtime.GC()
+		if containsAll(buf.String(), expected) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if output := buf.String(); !containsAll(output, expected) {
+		t.Fatalf("expected leaked resources to be reported, got:\n%s", output)
+	}
+
+	// Destroyed resources are not reported.
+	buf.Reset()
+	converter, err := NewConverter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	converter.Destroy()
+	for i := 0; i < 3; i++ {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+	if output := buf.String(); output != "" {
+		t.Errorf("expected destroyed resources not to be reported, got:\n%s", output)
+	}
+}
+
+//go:noinline
+func leakResources(t *testing.T) {
+	if _, err := NewObject("page.html"); err != nil {
+		t.Fatal(err)
+	}
+
+	converter, err := NewConverter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	object, err := NewObject("page.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := converter.Add(object); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func containsAll(s string, substrs []string) bool {
+	for _, substr := range substrs {
+		if !strings.Contains(s, substr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// syncBuffer is a bytes.Buffer which can be written to by finalizers.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func (b *syncBuffer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf.Reset()
+}
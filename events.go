@@ -0,0 +1,113 @@
+package pdf
+
+import (
+	"sync"
+	"time"
+)
+
+// eventBufferSize is the capacity of converter event channels.
+const eventBufferSize = 256
+
+// EventType defines the types of conversion events.
+type EventType string
+
+// Event type values.
+const (
+	EventWarning         EventType = "warning"
+	EventError           EventType = "error"
+	EventPhaseChanged    EventType = "phaseChanged"
+	EventProgressChanged EventType = "progressChanged"
+	EventFinished        EventType = "finished"
+
+	EventOverallProgressChanged EventType = "overallProgressChanged"
+)
+
+// Event contains information about an event which occurred in the
+// conversion process.
+type Event struct {
+	// The type of the event.
+	Type EventType `json:"type" yaml:"type"`
+
+	// The time the event occurred at.
+	Time time.Time `json:"time" yaml:"time"`
+
+	// The index of the current conversion phase.
+	PhaseIndex int `json:"phaseIndex" yaml:"phaseIndex"`
+
+	// The description of the current conversion phase.
+	PhaseDescription string `json:"phaseDescription" yaml:"phaseDescription"`
+
+	// The progress of the current conversion phase, as a percentage.
+	// Only set for progress events.
+	Progress int `json:"progress" yaml:"progress"`
+
+	// The overall progress of the conversion, as a percentage, and the
+	// estimated time until the conversion is finished. Only set for overall
+	// progress events. See Progress for more details.
+	OverallProgress float64       `json:"overallProgress" yaml:"overallProgress"`
+	ETA             time.Duration `json:"eta" yaml:"eta"`
+
+	// The message of warning and error events.
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+
+	// Specifies whether the conversion succeeded. Only set for finished events.
+	Success bool `json:"success" yaml:"success"`
+}
+
+// eventStream delivers conversion events through a bounded channel.
+// Sending events never blocks. If the channel is full, the oldest event
+// is discarded in favor of the new one.
+type eventStream struct {
+	mu     sync.Mutex
+	ch     chan Event
+	closed bool
+}
+
+func (s *eventStream) channel() <-chan Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ch == nil {
+		s.ch = make(chan Event, eventBufferSize)
+		if s.closed {
+			close(s.ch)
+		}
+	}
+
+	return s.ch
+}
+
+func (s *eventStream) emit(event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ch == nil || s.closed {
+		return
+	}
+
+	for {
+		select {
+		case s.ch <- event:
+			return
+		default:
+		}
+
+		// Discard the oldest event.
+		select {
+		case <-s.ch:
+		default:
+		}
+	}
+}
+
+func (s *eventStream) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.closed {
+		s.closed = true
+		if s.ch != nil {
+			close(s.ch)
+		}
+	}
+}
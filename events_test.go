@@ -0,0 +1,105 @@
+package pdf
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestEventStreamDiscardsOldest(t *testing.T) {
+	var stream eventStream
+
+	// Events emitted before the channel is retrieved are discarded.
+	stream.emit(Event{Type: EventWarning})
+
+	ch := stream.channel()
+	if ch != stream.channel() {
+		t.Fatal("expected the same channel to be returned")
+	}
+
+	const count = eventBufferSize + 100
+	for i := 0; i < count; i++ {
+		stream.emit(Event{Type: EventProgressChanged, Progress: i})
+	}
+	stream.close()
+	stream.close()
+
+	// Events emitted after the stream is closed are discarded.
+	stream.emit(Event{Type: EventFinished})
+
+	var received []int
+	for event := range ch {
+		received = append(received, event.Progress)
+	}
+	if len(received) != eventBufferSize {
+		t.Fatalf("expected %d events, got %d", eventBufferSize, len(received))
+	}
+	for i, progress := range received {
+		if expected := count - eventBufferSize + i; progress != expected {
+			t.Fatalf("expected event %d to have progress %d, got %d", i, expected, progress)
+		}
+	}
+}
+
+func TestEventStreamConcurrent(t *testing.T) {
+	const (
+		emitters = 4
+		count    = 2 * eventBufferSize
+	)
+
+	var stream eventStream
+	ch := stream.channel()
+
+	// Read events concurrently, until the channel is closed.
+	received := make(chan []Event)
+	go func() {
+		var events []Event
+		for event := range ch {
+			events = append(events, event)
+		}
+		received <- events
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < emitters; i++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			for j := 0; j < count; j++ {
+				stream.emit(Event{Type: EventProgressChanged, PhaseIndex: index, Progress: j})
+			}
+		}(i)
+	}
+	wg.Wait()
+	stream.close()
+
+	events := <-received
+	if len(events) == 0 || len(events) > emitters*count {
+		t.Fatalf("unexpected number of events: %d", len(events))
+	}
+
+	// Discarding events preserves the order of the events of each emitter.
+	last := make([]int, emitters)
+	for i := range last {
+		last[i] = -1
+	}
+	for _, event := range events {
+		if event.Progress <= last[event.PhaseIndex] {
+			t.Fatalf("events of emitter %d out of order: %d after %d", event.PhaseIndex, event.Progress, last[event.PhaseIndex])
+		}
+		last[event.PhaseIndex] = event.Progress
+	}
+
+	// The channel retrieved after the stream is closed is closed.
+	if _, ok := <-stream.channel(); ok {
+		t.Error("expected closed channel")
+	}
+}
+
+func TestEventStreamClosedBeforeChannel(t *testing.T) {
+	var stream eventStream
+	stream.close()
+
+	if _, ok := <-stream.channel(); ok {
+		t.Error("expected closed channel")
+	}
+}
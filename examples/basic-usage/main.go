@@ -1,3 +1,5 @@
+//go:build cgo
+
 package main
 
 import (
@@ -47,7 +49,7 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	object3.Zoom = 1.5
+	object3.Zoom = pdf.Float64(1.5)
 	object3.TOC.Title = "Table of Contents"
 
 	// Create converter.
@@ -58,9 +60,11 @@ func main() {
 	defer converter.Destroy()
 
 	// Add created objects to the converter.
-	converter.Add(object)
-	converter.Add(object2)
-	converter.Add(object3)
+	for _, o := range []*pdf.Object{object, object2, object3} {
+		if err := converter.Add(o); err != nil {
+			log.Fatal(err)
+		}
+	}
 
 	// Set converter options.
 	converter.Title = "Sample document"
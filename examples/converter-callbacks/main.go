@@ -1,3 +1,5 @@
+//go:build cgo
+
 package main
 
 import (
@@ -50,7 +52,9 @@ func main() {
 	object.Footer.ContentRight = "[page]"
 	object.Footer.DisplaySeparator = true
 
-	converter.Add(object)
+	if err := converter.Add(object); err != nil {
+		log.Fatal(err)
+	}
 
 	// Create output file.
 	outFile, err := os.Create("out.pdf")
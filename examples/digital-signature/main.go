@@ -0,0 +1,80 @@
+//go:build cgo
+
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+
+	pdf "github.com/adrg/go-wkhtmltopdf"
+	"github.com/adrg/go-wkhtmltopdf/sign"
+)
+
+func main() {
+	// Initialize library.
+	if err := pdf.Init(); err != nil {
+		log.Fatal(err)
+	}
+	defer pdf.Destroy()
+
+	// Load signing certificate chain and private key.
+	signer, err := sign.LoadPKCS12("certificate.p12", "password")
+	if err != nil {
+		log.Fatal(err)
+	}
+	signer.Reason = "Contract approval"
+	signer.Location = "Amsterdam"
+
+	// Display the signature on the first page. Omit the appearance
+	// in order to create an invisible signature.
+	signer.Appearance = &sign.Appearance{
+		Page: 1,
+		Rect: [4]float64{50, 50, 250, 110},
+	}
+
+	// Optionally, timestamp the signature using an RFC 3161 time
+	// stamping authority.
+	signer.Timestamper = sign.NewTimestampClient("http://timestamp.digicert.com")
+
+	// Create object.
+	object, err := pdf.NewObject("https://google.com")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Create converter.
+	converter, err := pdf.NewConverter()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer converter.Destroy()
+
+	// Add object to the converter.
+	if err := converter.Add(object); err != nil {
+		log.Fatal(err)
+	}
+
+	// Run converter. Due to a limitation of the `wkhtmltox` library, the
+	// conversion must be performed on the main thread.
+	out := bytes.NewBuffer(nil)
+	if err := converter.Run(out); err != nil {
+		log.Fatal(err)
+	}
+
+	// Create output file.
+	outFile, err := os.Create("signed.pdf")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func() {
+		if err := outFile.Close(); err != nil {
+			log.Println(err)
+		}
+	}()
+
+	// Sign the converted document.
+	if err := signer.Sign(outFile, out); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,303 @@
+//go:build cgo
+
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	pdf "github.com/adrg/go-wkhtmltopdf"
+)
+
+// jobRetention is the amount of time finished jobs are kept for.
+const jobRetention = time.Hour
+
+// Job status values.
+const (
+	statusQueued    = "queued"
+	statusRunning   = "running"
+	statusSucceeded = "succeeded"
+	statusFailed    = "failed"
+)
+
+// job represents an asynchronous conversion job.
+type job struct {
+	sync.Mutex
+
+	id      string
+	status  string
+	err     error
+	events  []pdf.Event
+	result  []byte
+	updated chan struct{}
+}
+
+type jobStatus struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (j *job) statusInfo() *jobStatus {
+	j.Lock()
+	defer j.Unlock()
+
+	status := &jobStatus{ID: j.id, Status: j.status}
+	if j.err != nil {
+		status.Error = j.err.Error()
+	}
+	return status
+}
+
+// update applies the provided function to the job and notifies the
+// clients waiting for job updates.
+func (j *job) update(f func()) {
+	j.Lock()
+	f()
+	close(j.updated)
+	j.updated = make(chan struct{})
+	j.Unlock()
+}
+
+func (j *job) finished() bool {
+	return j.status == statusSucceeded || j.status == statusFailed
+}
+
+// run performs the conversion described by the specified job specification.
+func (j *job) run(spec *pdf.Job) {
+	j.update(func() { j.status = statusRunning })
+
+	var forwarding sync.WaitGroup
+
+	out := bytes.NewBuffer(nil)
+	err := callFunc(func() error {
+		converter, err := pdf.NewConverterFromJob(spec)
+		if err != nil {
+			return err
+		}
+		defer converter.Destroy()
+
+		// Forward conversion events to the clients. The events channel is
+		// closed when the converter is destroyed.
+		events := converter.Events()
+		forwarding.Add(1)
+		go func() {
+			defer forwarding.Done()
+			for event := range events {
+				event := event
+				j.update(func() { j.events = append(j.events, event) })
+			}
+		}()
+
+		// Run converter. Due to a limitation of the `wkhtmltox` library,
+		// the conversion must be performed on the main thread.
+		return converter.Run(out)
+	})
+	forwarding.Wait()
+
+	j.update(func() {
+		if err != nil {
+			j.status, j.err = statusFailed, err
+			return
+		}
+		j.status, j.result = statusSucceeded, out.Bytes()
+	})
+}
+
+// jobStore contains the conversion jobs.
+type jobStore struct {
+	sync.RWMutex
+	jobs map[string]*job
+}
+
+func newJobStore() *jobStore {
+	return &jobStore{jobs: map[string]*job{}}
+}
+
+// submit creates a new job using the provided specification and runs it
+// in the background.
+func (s *jobStore) submit(spec *pdf.Job) (*job, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	j := &job{
+		id:      hex.EncodeToString(id),
+		status:  statusQueued,
+		updated: make(chan struct{}),
+	}
+
+	s.Lock()
+	s.jobs[j.id] = j
+	s.Unlock()
+
+	go func() {
+		j.run(spec)
+
+		// Remove job after the retention period.
+		time.AfterFunc(jobRetention, func() {
+			s.Lock()
+			delete(s.jobs, j.id)
+			s.Unlock()
+		})
+	}()
+
+	return j, nil
+}
+
+func (s *jobStore) get(id string) (*job, bool) {
+	s.RLock()
+	defer s.RUnlock()
+
+	j, ok := s.jobs[id]
+	return j, ok
+}
+
+// ServeHTTP handles the job endpoints:
+//   - POST /jobs submits a conversion job and returns its ID.
+//   - GET /jobs/{id} returns the status of a job.
+//   - GET /jobs/{id}/events streams the events of a job as server-sent events.
+//   - GET /jobs/{id}/result returns the output document of a job.
+func (s *jobStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/jobs"), "/")
+	if path == "" {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		s.handleSubmit(w, r)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, endpoint, _ := strings.Cut(path, "/")
+	j, ok := s.get(id)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch endpoint {
+	case "":
+		writeJSON(w, http.StatusOK, j.statusInfo())
+	case "events":
+		s.handleEvents(w, r, j)
+	case "result":
+		s.handleResult(w, j)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *jobStore) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	// Decode job specification. Any option fields specified in the request
+	// body will overwrite the defaults.
+	spec := &pdf.Job{}
+	if err := json.NewDecoder(r.Body).Decode(spec); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(spec.Objects) == 0 {
+		http.Error(w, "must specify at least one object to convert", http.StatusBadRequest)
+		return
+	}
+
+	j, err := s.submit(spec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", "/jobs/"+j.id)
+	writeJSON(w, http.StatusAccepted, j.statusInfo())
+}
+
+func (s *jobStore) handleEvents(w http.ResponseWriter, r *http.Request, j *job) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	// Send all the job events, including the ones which occurred before
+	// the client connected, until the job is finished.
+	for sent := 0; ; {
+		j.Lock()
+		events, finished, updated := j.events[sent:], j.finished(), j.updated
+		j.Unlock()
+
+		for _, event := range events {
+			if err := writeEvent(w, string(event.Type), event); err != nil {
+				return
+			}
+		}
+		sent += len(events)
+
+		if finished {
+			writeEvent(w, "done", j.statusInfo()) // nolint:errcheck
+			flusher.Flush()
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-updated:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (s *jobStore) handleResult(w http.ResponseWriter, j *job) {
+	j.Lock()
+	status, result := j.status, j.result
+	j.Unlock()
+
+	switch status {
+	case statusSucceeded:
+		w.Header().Set("Content-Disposition", "attachment; filename=download.pdf")
+		w.Header().Set("Content-Type", "application/pdf")
+		if _, err := w.Write(result); err != nil {
+			log.Println(err)
+		}
+	case statusFailed:
+		writeJSON(w, http.StatusInternalServerError, j.statusInfo())
+	default:
+		writeJSON(w, http.StatusConflict, j.statusInfo())
+	}
+}
+
+func writeEvent(w http.ResponseWriter, name string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, payload)
+	return err
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Println(err)
+	}
+}
@@ -1,3 +1,5 @@
+//go:build cgo
+
 package main
 
 import (
@@ -54,8 +56,8 @@ func callFunc(f func() error) error {
 }
 
 type requestData struct {
-	ConverterOpts *pdf.ConverterOpts `json:"converterOpts"`
-	ObjectOpts    *pdf.ObjectOpts    `json:"objectOpts"`
+	ConverterOpts json.RawMessage `json:"converterOpts"`
+	ObjectOpts    json.RawMessage `json:"objectOpts"`
 }
 
 func startServer() {
@@ -66,42 +68,60 @@ func startServer() {
 			return
 		}
 
-		// Set default options. Any option fields specified in the request
-		// body will overwrite the defaults.
-		data := &requestData{
-			ConverterOpts: pdf.NewConverterOpts(),
-			ObjectOpts:    pdf.NewObjectOpts(),
-		}
-
 		// Decode request body.
+		data := &requestData{}
 		if err := json.NewDecoder(r.Body).Decode(data); err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Set default options. The options specified in the request body
+		// are applied as JSON merge patches over the defaults. Options set
+		// to null are reset to their default values.
+		converterOpts, objectOpts := pdf.NewConverterOpts(), pdf.NewObjectOpts()
+		if data.ConverterOpts != nil {
+			if err := converterOpts.ApplyMergePatch(data.ConverterOpts); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if data.ObjectOpts != nil {
+			if err := objectOpts.ApplyMergePatch(data.ObjectOpts); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
 		}
 
 		// Convert the page at the specified URL to PDF.
 		out := bytes.NewBuffer(nil)
 		if err := callFunc(func() error {
 			// Create object with options.
-			object, err := pdf.NewObjectWithOpts(data.ObjectOpts)
+			object, err := pdf.NewObjectWithOpts(objectOpts)
 			if err != nil {
 				return err
 			}
 
 			// Create converter with options.
-			converter, err := pdf.NewConverterWithOpts(data.ConverterOpts)
+			converter, err := pdf.NewConverterWithOpts(converterOpts)
 			if err != nil {
-				log.Fatal(err)
+				object.Destroy()
+				return err
 			}
 			defer converter.Destroy()
 
-			// Add object to the converter.
-			converter.Add(object)
+			// Add object to the converter. The converter only takes
+			// ownership of the object if it is added successfully.
+			if err := converter.Add(object); err != nil {
+				object.Destroy()
+				return err
+			}
 
 			// Run converter. Due to a limitation of the `wkhtmltox` library,
 			// the conversion must be performed on the main thread.
 			return converter.Run(out)
 		}); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
 		}
 
 		// Serve converted file.
@@ -112,5 +132,10 @@ func startServer() {
 		}
 	})
 
+	// Handle asynchronous conversion jobs.
+	jobs := newJobStore()
+	http.Handle("/jobs", jobs)
+	http.Handle("/jobs/", jobs)
+
 	log.Fatal(http.ListenAndServe(":8080", nil))
 }
@@ -1,3 +1,5 @@
+//go:build cgo
+
 package main
 
 import (
@@ -79,12 +81,17 @@ func startServer() {
 			// Create converter.
 			converter, err := pdf.NewConverter()
 			if err != nil {
-				log.Fatal(err)
+				object.Destroy()
+				return err
 			}
 			defer converter.Destroy()
 
-			// Add object to the converter.
-			converter.Add(object)
+			// Add object to the converter. The converter only takes
+			// ownership of the object if it is added successfully.
+			if err := converter.Add(object); err != nil {
+				object.Destroy()
+				return err
+			}
 			converter.Title = url
 			converter.PaperSize = pdf.A4
 
@@ -93,6 +100,7 @@ func startServer() {
 			return converter.Run(out)
 		}); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
 		}
 
 		// Serve converted file.
@@ -1,6 +1,9 @@
+//go:build cgo
+
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"os"
@@ -10,29 +13,35 @@ import (
 )
 
 // For the full list of options, see pkg.go.dev/github.com/adrg/go-wkhtmltopdf.
-// NOTE: pdf.ConverterOpts and pdf.ObjectOpts also support YAML unmarshalling.
+// NOTE: pdf.Job also supports YAML unmarshalling.
 var jsonInput = strings.NewReader(`{
-	"converterOpts": {
+	"converter": {
 		"title": "google.com",
 		"paperSize": "A4",
 		"orientation": "Portrait",
 		"marginLeft": "10mm",
 		"marginRight": "10mm"
 	},
-	"objectOpts": {
-		"location": "https://google.com",
-		"footer": {
-			"contentCenter": "[page]",
-			"fontSize": 14
+	"objects": [
+		{
+			"type": "cover",
+			"html": "<h1>google.com</h1>"
+		},
+		{
+			"type": "toc"
+		},
+		{
+			"url": "https://google.com",
+			"options": {
+				"footer": {
+					"contentCenter": "[page]",
+					"fontSize": 14
+				}
+			}
 		}
-	}
+	]
 }`)
 
-type inputData struct {
-	ConverterOpts *pdf.ConverterOpts `json:"converterOpts"`
-	ObjectOpts    *pdf.ObjectOpts    `json:"objectOpts"`
-}
-
 func main() {
 	// Initialize library.
 	if err := pdf.Init(); err != nil {
@@ -40,33 +49,13 @@ func main() {
 	}
 	defer pdf.Destroy()
 
-	// Set default options. Any option fields specified in the JSON
-	// input data will overwrite the defaults.
-	input := &inputData{
-		ConverterOpts: pdf.NewConverterOpts(),
-		ObjectOpts:    pdf.NewObjectOpts(),
-	}
-
-	if err := json.NewDecoder(jsonInput).Decode(input); err != nil {
+	// Decode job. Any option fields specified in the JSON input data
+	// will overwrite the defaults.
+	job := &pdf.Job{}
+	if err := json.NewDecoder(jsonInput).Decode(job); err != nil {
 		log.Fatal(err)
 	}
 
-	// Create object.
-	object, err := pdf.NewObjectWithOpts(input.ObjectOpts)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	// Create converter.
-	converter, err := pdf.NewConverterWithOpts(input.ConverterOpts)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer converter.Destroy()
-
-	// Add object to the converter.
-	converter.Add(object)
-
 	// Create output file.
 	outFile, err := os.Create("out.pdf")
 	if err != nil {
@@ -78,9 +67,9 @@ func main() {
 		}
 	}()
 
-	// Run converter. Due to a limitation of the `wkhtmltox` library, the
+	// Run job. Due to a limitation of the `wkhtmltox` library, the
 	// conversion must be performed on the main thread.
-	if err := converter.Run(outFile); err != nil {
+	if err := pdf.RunJob(context.Background(), job, outFile); err != nil {
 		log.Fatal(err)
 	}
 }
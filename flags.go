@@ -0,0 +1,284 @@
+package pdf
+
+//go:generate go run gen_optiondocs.go
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// RegisterFlags registers flags for the converter options on the specified
+// flag set. The flag names are derived from the JSON names of the options,
+// converted to kebab case and preceded by the specified prefix
+// (e.g. -margin-top). The current values of the options are used as flag
+// defaults. Extra settings are specified as repeated key=value flags and
+// custom outline items as a JSON array. The names of the converter and
+// object flags do not overlap, so both can be registered on the same flag
+// set using the same prefix. The extra object settings are registered as
+// -object-extra.
+func (opts *ConverterOpts) RegisterFlags(fs *flag.FlagSet, prefix string) {
+	registerFlags(fs, prefix, opts)
+}
+
+// LoadEnv overrides the converter options with the values of the
+// environment variables named after the options, converted to upper snake
+// case and preceded by the specified prefix (e.g. PDF_MARGIN_TOP).
+// Extra settings are specified as comma separated key=value pairs and
+// custom outline items as a JSON array. The extra object settings are read
+// from the OBJECT_EXTRA variable.
+func (opts *ConverterOpts) LoadEnv(prefix string) error {
+	return loadEnv(prefix, opts)
+}
+
+// RegisterFlags registers flags for the object options on the specified
+// flag set (e.g. -footer-content-center). See ConverterOpts.RegisterFlags
+// for the naming rules of the flags.
+func (opts *ObjectOpts) RegisterFlags(fs *flag.FlagSet, prefix string) {
+	registerFlags(fs, prefix, opts)
+}
+
+// LoadEnv overrides the object options with the values of the environment
+// variables named after the options (e.g. PDF_FOOTER_CONTENT_CENTER).
+// See ConverterOpts.LoadEnv for the naming rules of the variables.
+func (opts *ObjectOpts) LoadEnv(prefix string) error {
+	return loadEnv(prefix, opts)
+}
+
+// optionField contains information about an option field.
+type optionField struct {
+	path  []string
+	doc   string
+	value reflect.Value
+}
+
+// optionFields returns the option fields of the specified options, in
+// declaration order. The fields of nested option structs are flattened.
+// The field names are taken from the flag tags, if present, or from the
+// JSON tags otherwise.
+func optionFields(opts interface{}) []*optionField {
+	var fields []*optionField
+
+	var walk func(v reflect.Value, path []string)
+	walk = func(v reflect.Value, path []string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+			if !sf.IsExported() || name == "" || name == "-" {
+				continue
+			}
+
+			if flagName := sf.Tag.Get("flag"); flagName != "" {
+				name = flagName
+			}
+
+			fieldPath := append(append([]string{}, path...), name)
+			if sf.Type.Kind() == reflect.Struct {
+				walk(v.Field(i), fieldPath)
+				continue
+			}
+
+			fields = append(fields, &optionField{
+				path:  fieldPath,
+				doc:   optionDocs[t.Name()+"."+sf.Name],
+				value: v.Field(i),
+			})
+		}
+	}
+	walk(reflect.ValueOf(opts).Elem(), nil)
+
+	return fields
+}
+
+func registerFlags(fs *flag.FlagSet, prefix string, opts interface{}) {
+	for _, field := range optionFields(opts) {
+		var value flag.Value = &optionValue{value: field.value}
+		if field.value.Kind() == reflect.Bool {
+			value = &boolOptionValue{optionValue{value: field.value}}
+		}
+
+		fs.Var(value, prefix+field.name("-", unicode.ToLower), field.doc)
+	}
+}
+
+func loadEnv(prefix string, opts interface{}) error {
+	for _, field := range optionFields(opts) {
+		name := prefix + field.name("_", unicode.ToUpper)
+
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		// Extra settings are specified as comma separated key=value pairs.
+		fv := &optionValue{value: field.value}
+		if field.value.Kind() == reflect.Map {
+			for _, kv := range strings.Split(value, ",") {
+				if err := fv.Set(kv); err != nil {
+					return fmt.Errorf("invalid value for environment variable %s: %w", name, err)
+				}
+			}
+			continue
+		}
+		if err := fv.Set(value); err != nil {
+			return fmt.Errorf("invalid value for environment variable %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// name returns the name of the option field, with the words of the field
+// path separated by the specified separator and converted using the
+// provided function (e.g. imageDPI becomes image-dpi or IMAGE_DPI).
+func (f *optionField) name(sep string, conv func(rune) rune) string {
+	var words []string
+	for _, part := range f.path {
+		runes := []rune(part)
+
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			if !unicode.IsUpper(runes[i]) {
+				continue
+			}
+			if !unicode.IsUpper(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+		words = append(words, string(runes[start:]))
+	}
+
+	return strings.Map(conv, strings.Join(words, sep))
+}
+
+// optionValue is a flag.Value implementation for option fields.
+type optionValue struct {
+	value reflect.Value
+}
+
+func (ov *optionValue) String() string {
+	if !ov.value.IsValid() {
+		return ""
+	}
+
+	v := ov.value
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Map:
+		pairs := make([]string, 0, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			pairs = append(pairs, fmt.Sprintf("%s=%s", iter.Key(), iter.Value()))
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ",")
+	case reflect.Slice:
+		if v.Len() == 0 {
+			return ""
+		}
+		data, _ := json.Marshal(v.Interface())
+		return string(data)
+	}
+
+	return fmt.Sprint(v.Interface())
+}
+
+func (ov *optionValue) Set(s string) error {
+	v := ov.value
+	if v.Kind() == reflect.Ptr {
+		ptr := reflect.New(v.Type().Elem())
+		if err := (&optionValue{value: ptr.Elem()}).Set(s); err != nil {
+			return err
+		}
+		v.Set(ptr)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Map:
+		key, value, ok := strings.Cut(s, "=")
+		if !ok {
+			return fmt.Errorf("invalid key=value pair %q", s)
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		v.SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(value))
+	default:
+		ptr := reflect.New(v.Type())
+		if err := json.Unmarshal([]byte(s), ptr.Interface()); err != nil {
+			return err
+		}
+		v.Set(ptr.Elem())
+	}
+
+	return nil
+}
+
+// boolOptionValue is a flag.Value implementation for boolean option fields.
+type boolOptionValue struct {
+	optionValue
+}
+
+func (bv *boolOptionValue) String() string {
+	if !bv.value.IsValid() {
+		return "false"
+	}
+
+	return bv.optionValue.String()
+}
+
+// IsBoolFlag allows boolean options to be specified without a value.
+func (bv *boolOptionValue) IsBoolFlag() bool {
+	return true
+}
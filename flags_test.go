@@ -0,0 +1,190 @@
+package pdf
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"testing"
+	"unicode"
+)
+
+func TestOptionFieldNames(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	NewObjectOpts().RegisterFlags(fs, "obj-")
+
+	tests := []struct {
+		path []string
+		flag string
+		env  string
+	}{
+		{path: []string{"imageDPI"}, flag: "image-dpi", env: "IMAGE_DPI"},
+		{path: []string{"marginTop"}, flag: "margin-top", env: "MARGIN_TOP"},
+		{path: []string{"footer", "contentCenter"}, flag: "footer-content-center", env: "FOOTER_CONTENT_CENTER"},
+		{path: []string{"userStylesheetLocation"}, flag: "user-stylesheet-location", env: "USER_STYLESHEET_LOCATION"},
+		{path: []string{"title"}, flag: "title", env: "TITLE"},
+	}
+
+	for _, test := range tests {
+		f := &optionField{path: test.path}
+		if name := f.name("-", unicode.ToLower); name != test.flag {
+			t.Errorf("expected flag name %q, got %q", test.flag, name)
+		}
+		if name := f.name("_", unicode.ToUpper); name != test.env {
+			t.Errorf("expected environment variable name %q, got %q", test.env, name)
+		}
+	}
+
+	for _, name := range []string{"obj-footer-content-center", "obj-toc-font-scale", "obj-zoom", "obj-object-extra"} {
+		if fs.Lookup(name) == nil {
+			t.Errorf("flag %s was not registered", name)
+		}
+	}
+	if f := fs.Lookup("obj-print-background"); f == nil || f.Usage == "" || f.DefValue != "true" {
+		t.Errorf("unexpected print background flag: %+v", f)
+	}
+}
+
+func TestRegisterConverterAndObjectFlags(t *testing.T) {
+	t.Setenv("PDF_EXTRA", "a=1")
+	t.Setenv("PDF_OBJECT_EXTRA", "b=2")
+
+	converterOpts, objectOpts := NewConverterOpts(), NewObjectOpts()
+	if err := converterOpts.LoadEnv("PDF_"); err != nil {
+		t.Fatal(err)
+	}
+	if err := objectOpts.LoadEnv("PDF_"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The flags of both options can be registered on the same flag set,
+	// without a prefix.
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	converterOpts.RegisterFlags(fs, "")
+	objectOpts.RegisterFlags(fs, "")
+
+	if err := fs.Parse([]string{"-extra", "c=3", "-object-extra", "d=4"}); err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]string{"a": "1", "c": "3"}; !reflect.DeepEqual(converterOpts.Extra, expected) {
+		t.Errorf("expected converter extra settings %v, got %v", expected, converterOpts.Extra)
+	}
+	if expected := map[string]string{"b": "2", "d": "4"}; !reflect.DeepEqual(objectOpts.Extra, expected) {
+		t.Errorf("expected object extra settings %v, got %v", expected, objectOpts.Extra)
+	}
+}
+
+func TestFlagEnvPrecedence(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		args     []string
+		expected func(opts *ConverterOpts)
+	}{
+		{
+			name:     "defaults",
+			expected: func(opts *ConverterOpts) {},
+		},
+		{
+			name: "environment",
+			env: map[string]string{
+				"PDF_TITLE":         "From env",
+				"PDF_MARGIN_TOP":    "2cm",
+				"PDF_COLLATE":       "false",
+				"PDF_IMAGE_QUALITY": "0",
+				"PDF_EXTRA":         "a=1,b=2",
+			},
+			expected: func(opts *ConverterOpts) {
+				opts.Title, opts.MarginTop, opts.Collate = "From env", "2cm", false
+				opts.ImageQuality = Uint64(0)
+				opts.Extra = map[string]string{"a": "1", "b": "2"}
+			},
+		},
+		{
+			name: "flags override environment",
+			env: map[string]string{
+				"PDF_TITLE":      "From env",
+				"PDF_MARGIN_TOP": "2cm",
+				"PDF_EXTRA":      "a=1",
+			},
+			args: []string{"-title", "From flags", "-collate=false", "-outline-depth", "3", "-extra", "b=2"},
+			expected: func(opts *ConverterOpts) {
+				opts.Title, opts.MarginTop, opts.Collate = "From flags", "2cm", false
+				opts.OutlineDepth = Uint64(3)
+				opts.Extra = map[string]string{"a": "1", "b": "2"}
+			},
+		},
+		{
+			name: "outline",
+			args: []string{"-outline", `[{"title": "Intro", "page": 1}]`, "-page-offset", "-2"},
+			expected: func(opts *ConverterOpts) {
+				opts.Outline = []OutlineItem{{Title: "Intro", Page: 1}}
+				opts.PageOffset = -2
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for key, value := range test.env {
+				t.Setenv(key, value)
+			}
+
+			// Environment variables are loaded first, so that they are used
+			// as flag defaults, which are overridden by the parsed flags.
+			opts := NewConverterOpts()
+			if err := opts.LoadEnv("PDF_"); err != nil {
+				t.Fatal(err)
+			}
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			opts.RegisterFlags(fs, "")
+			if err := fs.Parse(test.args); err != nil {
+				t.Fatal(err)
+			}
+
+			expected := NewConverterOpts()
+			test.expected(expected)
+			if !reflect.DeepEqual(opts, expected) {
+				t.Errorf("expected %+v, got %+v", expected, opts)
+			}
+		})
+	}
+}
+
+func TestFlagErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+	}{
+		{name: "invalid uint flag", args: []string{"-copies", "-1"}},
+		{name: "invalid bool flag", args: []string{"-collate=maybe"}},
+		{name: "invalid extra flag", args: []string{"-extra", "novalue"}},
+		{name: "invalid outline flag", args: []string{"-outline", "{"}},
+		{name: "invalid float env", env: map[string]string{"PDF_ZOOM": "large"}},
+		{name: "invalid extra env", env: map[string]string{"PDF_EXTRA": "a=1,b"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for key, value := range test.env {
+				t.Setenv(key, value)
+			}
+
+			converterOpts, objectOpts := NewConverterOpts(), NewObjectOpts()
+			err := converterOpts.LoadEnv("PDF_")
+			if err == nil {
+				err = objectOpts.LoadEnv("PDF_")
+			}
+			if err == nil {
+				fs := flag.NewFlagSet("test", flag.ContinueOnError)
+				fs.SetOutput(io.Discard)
+				converterOpts.RegisterFlags(fs, "")
+				err = fs.Parse(test.args)
+			}
+			if err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
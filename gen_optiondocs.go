@@ -0,0 +1,195 @@
+//go:build ignore
+
+// This program generates optiondocs.go, which contains the documentation of
+// the option fields and the defined values of the option types, used for
+// flag usage text and JSON Schema generation. It is invoked by running
+// go generate. The output file can be changed using the -o flag.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// optionTypes contains the names of the option types to be documented.
+var optionTypes = map[string]bool{
+	"ConverterOpts": true,
+	"ObjectOpts":    true,
+	"TOC":           true,
+	"Header":        true,
+	"OutlineItem":   true,
+}
+
+func main() {
+	output := flag.String("o", "optiondocs.go", "output file")
+	flag.Parse()
+
+	fset := token.NewFileSet()
+
+	docs := map[string]string{}
+	enums := map[string][]string{}
+	for _, file := range []string{"converter_opts.go", "object_opts.go", "outline.go", "conformance.go"} {
+		f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// Collect the string types, which have their values enumerated.
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if ident, ok := ts.Type.(*ast.Ident); ok && ident.Name == "string" {
+					enums[ts.Name.Name] = nil
+				}
+			}
+		}
+
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+
+			switch gd.Tok {
+			case token.CONST:
+				for _, spec := range gd.Specs {
+					vs := spec.(*ast.ValueSpec)
+					typ, ok := vs.Type.(*ast.Ident)
+					if !ok {
+						continue
+					}
+					if _, ok := enums[typ.Name]; !ok {
+						continue
+					}
+
+					for _, value := range vs.Values {
+						if lit, ok := value.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+							val, err := strconv.Unquote(lit.Value)
+							if err != nil {
+								log.Fatal(err)
+							}
+							enums[typ.Name] = append(enums[typ.Name], val)
+						}
+					}
+				}
+			case token.TYPE:
+				for _, spec := range gd.Specs {
+					ts := spec.(*ast.TypeSpec)
+					st, ok := ts.Type.(*ast.StructType)
+					if !ok || !optionTypes[ts.Name.Name] {
+						continue
+					}
+					addFieldDocs(fset, docs, ts.Name.Name, st)
+				}
+			}
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintln(buf, "// Code generated by gen_optiondocs.go; DO NOT EDIT.")
+	fmt.Fprintln(buf)
+	fmt.Fprintln(buf, "package pdf")
+	fmt.Fprintln(buf)
+	fmt.Fprintln(buf, "// optionDocs contains the summaries of the option field docs.")
+	fmt.Fprintln(buf, "var optionDocs = map[string]string{")
+	for _, key := range sortedKeys(docs) {
+		fmt.Fprintf(buf, "%s: %s,\n", strconv.Quote(key), strconv.Quote(docs[key]))
+	}
+	fmt.Fprintln(buf, "}")
+	fmt.Fprintln(buf)
+	fmt.Fprintln(buf, "// optionEnums contains the defined values of the option types.")
+	fmt.Fprintln(buf, "var optionEnums = map[string][]string{")
+	for _, key := range sortedKeys(enums) {
+		if len(enums[key]) == 0 {
+			continue
+		}
+
+		values := make([]string, 0, len(enums[key]))
+		for _, value := range enums[key] {
+			values = append(values, strconv.Quote(value))
+		}
+		fmt.Fprintf(buf, "%s: {%s},\n", strconv.Quote(key), strings.Join(values, ", "))
+	}
+	fmt.Fprintln(buf, "}")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*output, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// addFieldDocs adds the documentation of the fields of the specified struct.
+func addFieldDocs(fset *token.FileSet, docs map[string]string, typeName string, st *ast.StructType) {
+	// Undocumented fields share the documentation of the preceding field,
+	// if they are declared on adjacent lines.
+	var doc string
+	var prevLine int
+	for _, field := range st.Fields.List {
+		line := fset.Position(field.Pos()).Line
+		if field.Doc != nil {
+			doc = summary(field.Doc.Text())
+		} else if line != prevLine+1 {
+			doc = ""
+		}
+		prevLine = fset.Position(field.End()).Line
+
+		for _, name := range field.Names {
+			if name.IsExported() && doc != "" {
+				docs[typeName+"."+name.Name] = doc
+			}
+		}
+	}
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// summary returns the first sentence of the specified field documentation.
+func summary(doc string) string {
+	var lines []string
+	for _, line := range strings.Split(doc, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "-") ||
+			strings.HasPrefix(strings.ToLower(line), "e.g.") {
+			break
+		}
+		lines = append(lines, line)
+	}
+
+	text := strings.Join(lines, " ")
+	for offset := 0; ; {
+		idx := strings.Index(text[offset:], ". ")
+		if idx < 0 {
+			return text
+		}
+		if idx += offset; !strings.HasSuffix(text[:idx], "e.g") &&
+			!strings.HasSuffix(text[:idx], "i.e") {
+			return text[:idx+1]
+		}
+		offset = idx + 2
+	}
+}
@@ -1,3 +1,10 @@
 module github.com/adrg/go-wkhtmltopdf
 
 go 1.19
+
+require (
+	gopkg.in/yaml.v3 v3.0.1
+	software.sslmate.com/src/go-pkcs12 v0.7.3
+)
+
+require golang.org/x/crypto v0.11.0 // indirect
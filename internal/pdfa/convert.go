@@ -0,0 +1,333 @@
+package pdfa
+
+import (
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/adrg/go-wkhtmltopdf/internal/pdfdoc"
+)
+
+// Convert adjusts the specified document in order to conform to the B level
+// of the specified PDF/A part. The function embeds an sRGB output intent
+// and XMP metadata matching the document information dictionary, and
+// removes the features which are not permitted by the specification.
+// Font programs cannot be embedded by the conversion, so a FontError is
+// returned, without altering the document, if the document uses fonts
+// which are not embedded.
+func Convert(doc *pdfdoc.Document, part int) error {
+	if part < 1 || part > 3 {
+		return fmt.Errorf("invalid PDF/A part: %d", part)
+	}
+	if doc.Trailer["Encrypt"] != nil {
+		return errors.New("encrypted documents cannot be converted to PDF/A")
+	}
+	if err := checkFontsEmbedded(doc); err != nil {
+		return err
+	}
+
+	catalog, catalogRef, err := doc.Catalog()
+	if err != nil {
+		return err
+	}
+
+	// Remove forbidden features from all document objects.
+	if err := visit(doc, func(ref pdfdoc.Ref, d pdfdoc.Dict, stream *pdfdoc.Stream) bool {
+		return removeForbidden(doc, d, stream, part)
+	}); err != nil {
+		return err
+	}
+
+	// Remove forbidden annotations.
+	pages, err := doc.Pages()
+	if err != nil {
+		return err
+	}
+	for _, pageRef := range pages {
+		removeAnnotations(doc, pageRef, part)
+	}
+
+	// Adjust composite fonts.
+	if err := adjustFonts(doc, part); err != nil {
+		return err
+	}
+
+	// Adjust document catalog.
+	catalog = catalog.Clone()
+	delete(catalog, "AA")
+	if part == 1 {
+		delete(catalog, "OCProperties")
+	}
+
+	if names := doc.Dict(catalog["Names"]); names != nil {
+		names = names.Clone()
+		delete(names, "JavaScript")
+		if part == 1 {
+			delete(names, "EmbeddedFiles")
+		}
+		setDict(doc, catalog, "Names", names)
+	}
+	if form := doc.Dict(catalog["AcroForm"]); form != nil {
+		form = form.Clone()
+		delete(form, "NeedAppearances")
+		delete(form, "XFA")
+		setDict(doc, catalog, "AcroForm", form)
+	}
+
+	if !hasOutputIntent(doc, catalog) {
+		profile := pdfdoc.NewStream(pdfdoc.Dict{"N": int64(3)}, SRGBProfile(), true)
+		catalog["OutputIntents"] = pdfdoc.Array{
+			pdfdoc.Dict{
+				"Type":                      pdfdoc.Name("OutputIntent"),
+				"S":                         pdfdoc.Name("GTS_PDFA1"),
+				"OutputConditionIdentifier": pdfdoc.String("sRGB IEC61966-2.1"),
+				"RegistryName":              pdfdoc.String("http://www.color.org"),
+				"Info":                      pdfdoc.String("sRGB IEC61966-2.1"),
+				"DestOutputProfile":         doc.Add(profile),
+			},
+		}
+	}
+
+	// Add XMP metadata, consistent with the document information dictionary.
+	meta := updateInfo(doc)
+	metadata := pdfdoc.NewStream(pdfdoc.Dict{
+		"Type":    pdfdoc.Name("Metadata"),
+		"Subtype": pdfdoc.Name("XML"),
+	}, xmpPacket(meta, part), false)
+
+	if ref, ok := catalog["Metadata"].(pdfdoc.Ref); ok {
+		doc.Set(ref, metadata)
+	} else {
+		catalog["Metadata"] = doc.Add(metadata)
+	}
+	doc.Set(catalogRef, catalog)
+
+	// Add document identifier, if missing.
+	if _, ok := doc.Trailer["ID"].(pdfdoc.Array); !ok {
+		id := md5.Sum(doc.Data())
+		doc.Trailer["ID"] = pdfdoc.Array{pdfdoc.HexString(id[:]), pdfdoc.HexString(id[:])}
+	}
+
+	// Adjust document version.
+	switch {
+	case part == 1 && doc.Version > "1.4":
+		doc.Version = "1.4"
+	case part > 1 && doc.Version > "1.7":
+		doc.Version = "1.7"
+	}
+
+	return nil
+}
+
+// removeForbidden removes the features which are not permitted by the
+// specified PDF/A part from the dictionary. The function returns true if
+// the dictionary is modified.
+func removeForbidden(doc *pdfdoc.Document, d pdfdoc.Dict, stream *pdfdoc.Stream, part int) bool {
+	changed := false
+	del := func(keys ...pdfdoc.Name) {
+		for _, key := range keys {
+			if _, ok := d[key]; ok {
+				delete(d, key)
+				changed = true
+			}
+		}
+	}
+
+	// Additional actions are not permitted.
+	del("AA")
+
+	// Remove forbidden actions.
+	for _, key := range []pdfdoc.Name{"A", "OpenAction", "Next"} {
+		if action := doc.Dict(d[key]); action != nil && forbiddenAction(action, part) {
+			del(key)
+		}
+	}
+
+	if stream != nil {
+		// Streams cannot reference external files.
+		del("F", "FFilter", "FDecodeParms")
+
+		switch d.Name("Subtype") {
+		case "Image":
+			if interpolate, _ := d["Interpolate"].(bool); interpolate {
+				del("Interpolate")
+			}
+			del("Alternates", "OPI")
+		case "Form":
+			del("OPI", "PS")
+		}
+	}
+
+	// Make annotations visible and printable.
+	if isAnnotation(d) {
+		if flags := annotationFlags(d); d["F"] != flags {
+			d["F"] = flags
+			changed = true
+		}
+	}
+
+	// PDF/A-1 does not allow transparency. The transparency group of pages
+	// only affects content which makes use of transparency features.
+	if part == 1 && d.Name("Type") == "Page" && isTransparencyGroup(doc, d["Group"]) {
+		del("Group")
+	}
+
+	return changed
+}
+
+// removeAnnotations removes the annotations which are not permitted by the
+// specified PDF/A part from the page with the specified reference.
+func removeAnnotations(doc *pdfdoc.Document, pageRef pdfdoc.Ref, part int) {
+	page := doc.Dict(pageRef)
+	if page == nil {
+		return
+	}
+
+	annots := doc.Array(page["Annots"])
+	filtered := make(pdfdoc.Array, 0, len(annots))
+	for _, annot := range annots {
+		if d := doc.Dict(annot); d != nil && !annotationPermitted(d.Name("Subtype"), part) {
+			// Validate checks all document objects, so the annotation is
+			// removed from the document, not only from the page.
+			if ref, ok := annot.(pdfdoc.Ref); ok {
+				doc.Delete(ref)
+			}
+			continue
+		}
+		filtered = append(filtered, annot)
+	}
+	if len(filtered) == len(annots) {
+		return
+	}
+
+	if ref, ok := page["Annots"].(pdfdoc.Ref); ok {
+		doc.Set(ref, filtered)
+		return
+	}
+
+	page = page.Clone()
+	page["Annots"] = filtered
+	doc.Set(pageRef, page)
+}
+
+// adjustFonts adds the entries required by the specified PDF/A part to the
+// descendant fonts of the composite fonts in the document.
+func adjustFonts(doc *pdfdoc.Document, part int) error {
+	for _, ref := range doc.Refs() {
+		font := doc.Dict(ref)
+		if font.Name("Type") != "Font" || font.Name("Subtype") != "Type0" {
+			continue
+		}
+
+		for _, descendant := range doc.Array(font["DescendantFonts"]) {
+			descRef, ok := descendant.(pdfdoc.Ref)
+			if !ok {
+				continue
+			}
+			cidFont := doc.Dict(descRef)
+			if cidFont == nil || cidFont.Name("Subtype") != "CIDFontType2" {
+				continue
+			}
+
+			// Type 2 CIDFonts must specify the CID to glyph mapping.
+			if cidFont["CIDToGIDMap"] == nil {
+				cidFont = cidFont.Clone()
+				cidFont["CIDToGIDMap"] = pdfdoc.Name("Identity")
+				doc.Set(descRef, cidFont)
+			}
+
+			// Subset fonts must identify the included CIDs in PDF/A-1.
+			descriptorRef, ok := cidFont["FontDescriptor"].(pdfdoc.Ref)
+			if part != 1 || !ok || !isSubset(cidFont.Name("BaseFont")) {
+				continue
+			}
+			descriptor := doc.Dict(descriptorRef)
+			if descriptor == nil || descriptor["CIDSet"] != nil {
+				continue
+			}
+			if set := cidSet(doc, cidFont); set != nil {
+				descriptor = descriptor.Clone()
+				descriptor["CIDSet"] = doc.Add(set)
+				doc.Set(descriptorRef, descriptor)
+			}
+		}
+	}
+
+	return nil
+}
+
+// updateInfo normalizes the dates of the document information dictionary
+// and returns the properties which must be included in the XMP metadata.
+func updateInfo(doc *pdfdoc.Document) metadata {
+	info := doc.Info()
+	if info == nil {
+		return metadata{}
+	}
+	info = info.Clone()
+
+	text := func(key pdfdoc.Name) string {
+		s, _ := info.Text(key)
+		return pdfdoc.DecodeText(s)
+	}
+	date := func(key pdfdoc.Name) time.Time {
+		s, ok := info.Text(key)
+		if !ok {
+			return time.Time{}
+		}
+
+		t, err := pdfdoc.ParseDate(s)
+		if err != nil {
+			delete(info, key)
+			return time.Time{}
+		}
+
+		// Use the same representation for both the information dictionary
+		// and the XMP metadata.
+		info[key] = pdfdoc.String(pdfdoc.FormatDate(t))
+		return t
+	}
+
+	meta := metadata{
+		Title:        text("Title"),
+		Author:       text("Author"),
+		Subject:      text("Subject"),
+		Keywords:     text("Keywords"),
+		Creator:      text("Creator"),
+		Producer:     text("Producer"),
+		CreationDate: date("CreationDate"),
+		ModDate:      date("ModDate"),
+	}
+
+	if ref, ok := doc.Trailer["Info"].(pdfdoc.Ref); ok {
+		doc.Set(ref, info)
+	} else {
+		doc.Trailer["Info"] = info
+	}
+
+	return meta
+}
+
+// hasOutputIntent returns true if the document contains a PDF/A output
+// intent with an embedded destination profile.
+func hasOutputIntent(doc *pdfdoc.Document, catalog pdfdoc.Dict) bool {
+	for _, intent := range doc.Array(catalog["OutputIntents"]) {
+		if d := doc.Dict(intent); d != nil && d.Name("S") == "GTS_PDFA1" && d["DestOutputProfile"] != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setDict stores the specified dictionary in the parent dictionary, under
+// the given key. If the key references an indirect object, the referenced
+// object is updated instead.
+func setDict(doc *pdfdoc.Document, parent pdfdoc.Dict, key pdfdoc.Name, d pdfdoc.Dict) {
+	if ref, ok := parent[key].(pdfdoc.Ref); ok {
+		doc.Set(ref, d)
+		return
+	}
+
+	parent[key] = d
+}
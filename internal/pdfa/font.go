@@ -0,0 +1,166 @@
+package pdfa
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/adrg/go-wkhtmltopdf/internal/pdfdoc"
+)
+
+// FontError is returned by Convert if the document uses fonts whose programs
+// are not embedded. The font programs are not available to Convert, so the
+// fonts cannot be embedded by the conversion.
+type FontError struct {
+	// The violations reported for the fonts which are not embedded.
+	Violations []Violation
+}
+
+// Error returns the description of the error.
+func (e *FontError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		messages = append(messages, v.String())
+	}
+
+	return fmt.Sprintf("cannot embed font programs: %s", strings.Join(messages, "; "))
+}
+
+// isFont returns true if the specified dictionary is a font dictionary
+// which is used directly by content streams. The descendant fonts of
+// composite fonts are checked along with their parent fonts.
+func isFont(d pdfdoc.Dict) bool {
+	subtype := d.Name("Subtype")
+	return d.Name("Type") == "Font" && subtype != "CIDFontType0" && subtype != "CIDFontType2"
+}
+
+// checkFontsEmbedded returns a FontError if the document uses fonts whose
+// programs are not embedded.
+func checkFontsEmbedded(doc *pdfdoc.Document) error {
+	var violations []Violation
+	seen := map[Violation]bool{}
+	err := visit(doc, func(ref pdfdoc.Ref, d pdfdoc.Dict, stream *pdfdoc.Stream) bool {
+		if isFont(d) && !fontEmbedded(doc, d) {
+			v := Violation{Object: ref, Message: fmt.Sprintf("font %s is not embedded", d.Name("BaseFont"))}
+			if !seen[v] {
+				seen[v] = true
+				violations = append(violations, v)
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return &FontError{Violations: violations}
+	}
+
+	return nil
+}
+
+// fontDescriptor returns the font descriptor of the specified font. For
+// composite fonts, the descriptor of the descendant font is returned.
+func fontDescriptor(doc *pdfdoc.Document, font pdfdoc.Dict) pdfdoc.Dict {
+	if font.Name("Subtype") == "Type0" {
+		if descendants := doc.Array(font["DescendantFonts"]); len(descendants) > 0 {
+			font = doc.Dict(descendants[0])
+		}
+	}
+	if font == nil {
+		return nil
+	}
+
+	return doc.Dict(font["FontDescriptor"])
+}
+
+// fontEmbedded returns true if the program of the specified font is
+// embedded in the document.
+func fontEmbedded(doc *pdfdoc.Document, font pdfdoc.Dict) bool {
+	if font.Name("Subtype") == "Type3" {
+		return true
+	}
+
+	desc := fontDescriptor(doc, font)
+	if desc == nil {
+		return false
+	}
+
+	return desc["FontFile"] != nil || desc["FontFile2"] != nil || desc["FontFile3"] != nil
+}
+
+// isSubset returns true if the name of the font has a subset tag prefix.
+// E.g.: "ABCDEF+DejaVuSans".
+func isSubset(name pdfdoc.Name) bool {
+	if len(name) < 7 || name[6] != '+' {
+		return false
+	}
+	for i := 0; i < 6; i++ {
+		if name[i] < 'A' || name[i] > 'Z' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// trueTypeGlyphCount returns the number of glyphs of the specified
+// TrueType font program, as specified by its maximum profile table.
+func trueTypeGlyphCount(data []byte) (int, bool) {
+	if len(data) < 12 {
+		return 0, false
+	}
+
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	for i := 0; i < numTables; i++ {
+		entry := 12 + 16*i
+		if entry+16 > len(data) {
+			return 0, false
+		}
+		if string(data[entry:entry+4]) != "maxp" {
+			continue
+		}
+
+		offset := int(binary.BigEndian.Uint32(data[entry+8:]))
+		if offset < 0 || offset+6 > len(data) {
+			return 0, false
+		}
+		return int(binary.BigEndian.Uint16(data[offset+4:])), true
+	}
+
+	return 0, false
+}
+
+// cidSet returns a CIDSet stream identifying the CIDs of a TrueType based
+// CIDFont which uses the identity CID to glyph mapping. As the CIDs are
+// equal to the glyph indices, all the glyphs of the embedded font program
+// are marked as present.
+func cidSet(doc *pdfdoc.Document, cidFont pdfdoc.Dict) *pdfdoc.Stream {
+	if cidFont.Name("Subtype") != "CIDFontType2" || cidFont.Name("CIDToGIDMap") != "Identity" {
+		return nil
+	}
+	desc := doc.Dict(cidFont["FontDescriptor"])
+	if desc == nil {
+		return nil
+	}
+
+	program, ok := doc.Resolve(desc["FontFile2"]).(*pdfdoc.Stream)
+	if !ok {
+		return nil
+	}
+	data, err := program.Decode()
+	if err != nil {
+		return nil
+	}
+	count, ok := trueTypeGlyphCount(data)
+	if !ok || count == 0 {
+		return nil
+	}
+
+	set := make([]byte, (count+7)/8)
+	for i := 0; i < count; i++ {
+		set[i/8] |= 0x80 >> (i % 8)
+	}
+
+	return pdfdoc.NewStream(nil, set, true)
+}
@@ -0,0 +1,133 @@
+package pdfa
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"sync"
+)
+
+var (
+	srgbOnce    sync.Once
+	srgbProfile []byte
+)
+
+// SRGBProfile returns an ICC version 2 display profile describing the sRGB
+// IEC61966-2.1 color space. The profile is generated on first use.
+func SRGBProfile() []byte {
+	srgbOnce.Do(func() {
+		srgbProfile = newSRGBProfile()
+	})
+
+	return srgbProfile
+}
+
+type iccTag struct {
+	sig  string
+	data []byte
+}
+
+func newSRGBProfile() []byte {
+	// The sRGB tone reproduction curve, sampled at 1024 points.
+	trc := &bytes.Buffer{}
+	trc.WriteString("curv\x00\x00\x00\x00")
+	binary.Write(trc, binary.BigEndian, uint32(1024)) // nolint:errcheck
+	for i := 0; i < 1024; i++ {
+		v := float64(i) / 1023
+		if v <= 0.04045 {
+			v /= 12.92
+		} else {
+			v = math.Pow((v+0.055)/1.055, 2.4)
+		}
+		binary.Write(trc, binary.BigEndian, uint16(math.Round(v*65535))) // nolint:errcheck
+	}
+
+	// Colorant and white point values, adapted to the D50 illuminant.
+	tags := []iccTag{
+		{"desc", iccTextDescription("sRGB IEC61966-2.1")},
+		{"cprt", iccText("No copyright, use freely")},
+		{"wtpt", iccXYZ(0.9642, 1.0, 0.8249)},
+		{"rXYZ", iccXYZ(0.4361, 0.2225, 0.0139)},
+		{"gXYZ", iccXYZ(0.3851, 0.7169, 0.0971)},
+		{"bXYZ", iccXYZ(0.1431, 0.0606, 0.7141)},
+		{"rTRC", trc.Bytes()},
+		{"gTRC", trc.Bytes()},
+		{"bTRC", trc.Bytes()},
+	}
+
+	// Compute tag offsets. Tags sharing the same data point to the same
+	// location in the profile.
+	offset := 128 + 4 + 12*len(tags)
+	offsets := make([]int, len(tags))
+	body := &bytes.Buffer{}
+	for i, tag := range tags {
+		if i > 0 && bytes.Equal(tag.data, tags[i-1].data) {
+			offsets[i] = offsets[i-1]
+			continue
+		}
+
+		offsets[i] = offset + body.Len()
+		body.Write(tag.data)
+		for body.Len()%4 != 0 {
+			body.WriteByte(0)
+		}
+	}
+	size := offset + body.Len()
+
+	// Write profile header.
+	buf := &bytes.Buffer{}
+	be := func(v interface{}) {
+		binary.Write(buf, binary.BigEndian, v) // nolint:errcheck
+	}
+	be(uint32(size))
+	buf.WriteString("\x00\x00\x00\x00") // Preferred CMM.
+	be(uint32(0x02100000))              // Version 2.1.
+	buf.WriteString("mntrRGB XYZ ")     // Device class, color space and PCS.
+	be([6]uint16{2000, 1, 1, 0, 0, 0})  // Creation date.
+	buf.WriteString("acsp")
+	buf.Write(make([]byte, 4+4+4+4+8+4)) // Platform, flags, device and rendering intent.
+	buf.Write(iccXYZ(0.9642, 1.0, 0.8249)[8:])
+	buf.Write(make([]byte, 128-buf.Len()))
+
+	// Write tag table.
+	be(uint32(len(tags)))
+	for i, tag := range tags {
+		buf.WriteString(tag.sig)
+		be(uint32(offsets[i]))
+		be(uint32(len(tag.data)))
+	}
+	buf.Write(body.Bytes())
+
+	return buf.Bytes()
+}
+
+func iccS15Fixed16(v float64) uint32 {
+	return uint32(int32(math.Round(v * 65536)))
+}
+
+func iccXYZ(x, y, z float64) []byte {
+	buf := make([]byte, 20)
+	copy(buf, "XYZ ")
+	binary.BigEndian.PutUint32(buf[8:], iccS15Fixed16(x))
+	binary.BigEndian.PutUint32(buf[12:], iccS15Fixed16(y))
+	binary.BigEndian.PutUint32(buf[16:], iccS15Fixed16(z))
+
+	return buf
+}
+
+func iccText(s string) []byte {
+	return append([]byte("text\x00\x00\x00\x00"+s), 0)
+}
+
+func iccTextDescription(s string) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString("desc\x00\x00\x00\x00")
+	binary.Write(buf, binary.BigEndian, uint32(len(s)+1)) // nolint:errcheck
+	buf.WriteString(s)
+	buf.WriteByte(0)
+
+	// Empty Unicode and ScriptCode descriptions.
+	buf.Write(make([]byte, 4+4+2+1+67))
+
+	return buf.Bytes()
+}
@@ -0,0 +1,186 @@
+/*
+Package pdfa implements the conversion of PDF documents to the PDF/A-1b,
+PDF/A-2b and PDF/A-3b archival formats, as well as the validation of
+documents against the requirements of the same conformance levels.
+
+The conversion does not rewrite content streams. Requirements which
+cannot be fulfilled without altering the rendered content of the document
+(e.g. transparency used in a PDF/A-1 document) are reported by Validate.
+Documents using fonts which are not embedded cannot be converted, as the
+font programs are not available to the conversion.
+*/
+package pdfa
+
+import (
+	"fmt"
+
+	"github.com/adrg/go-wkhtmltopdf/internal/pdfdoc"
+)
+
+// Violation describes a requirement of a conformance level which is not
+// fulfilled by a document.
+type Violation struct {
+	// The object which violates the requirement. The zero value is used
+	// for violations which concern the document as a whole.
+	Object pdfdoc.Ref
+
+	// The description of the violated requirement.
+	Message string
+}
+
+// String returns a textual representation of the violation.
+func (v Violation) String() string {
+	if v.Object.Num == 0 {
+		return v.Message
+	}
+
+	return fmt.Sprintf("%s (object %s)", v.Message, v.Object)
+}
+
+// actionTypes contains the types of all the actions defined by the PDF
+// specification, mapped to whether they are permitted in PDF/A documents.
+var actionTypes = map[pdfdoc.Name]bool{
+	"GoTo":        true,
+	"GoToR":       true,
+	"GoToE":       true,
+	"Thread":      true,
+	"URI":         true,
+	"Named":       true,
+	"SubmitForm":  true,
+	"Launch":      false,
+	"Sound":       false,
+	"Movie":       false,
+	"Hide":        false,
+	"ResetForm":   false,
+	"ImportData":  false,
+	"JavaScript":  false,
+	"SetOCGState": false,
+	"Rendition":   false,
+	"Trans":       false,
+	"GoTo3DView":  false,
+}
+
+// namedActions contains the named actions permitted in PDF/A documents.
+var namedActions = map[pdfdoc.Name]bool{
+	"NextPage":  true,
+	"PrevPage":  true,
+	"FirstPage": true,
+	"LastPage":  true,
+}
+
+// forbiddenAction returns true if the specified dictionary is an action
+// which is not permitted in documents of the specified PDF/A part.
+func forbiddenAction(action pdfdoc.Dict, part int) bool {
+	typ := action.Name("S")
+
+	permitted, ok := actionTypes[typ]
+	switch {
+	case !ok:
+		return false
+	case typ == "GoToE":
+		// Embedded go-to actions were introduced after PDF 1.4.
+		return part == 1
+	case typ == "Named":
+		return !namedActions[action.Name("N")]
+	}
+
+	return !permitted
+}
+
+// annotationPermitted returns true if annotations of the specified type
+// are permitted in documents of the specified PDF/A part.
+func annotationPermitted(subtype pdfdoc.Name, part int) bool {
+	switch subtype {
+	case "Movie", "Sound", "Screen", "3D", "RichMedia":
+		return false
+	case "FileAttachment":
+		return part == 3
+	case "Redact", "Projection":
+		return part > 1
+	}
+
+	return true
+}
+
+// annotationFlags returns the flags of the annotation, adjusted so that the
+// annotation is printed and is not hidden.
+func annotationFlags(annot pdfdoc.Dict) int64 {
+	const (
+		invisible = 1 << 0
+		hidden    = 1 << 1
+		print     = 1 << 2
+		noView    = 1 << 5
+	)
+
+	flags, _ := pdfdoc.Int(annot["F"])
+	return (flags | print) &^ (invisible | hidden | noView)
+}
+
+// isAnnotation returns true if the specified dictionary is an annotation.
+func isAnnotation(d pdfdoc.Dict) bool {
+	if d.Name("Type") == "Annot" {
+		return true
+	}
+
+	_, ok := d["Rect"].(pdfdoc.Array)
+	return ok && d.Name("Subtype") != "" && d.Name("Type") == ""
+}
+
+// isTransparencyGroup returns true if the specified object is a
+// transparency group attributes dictionary.
+func isTransparencyGroup(doc *pdfdoc.Document, o pdfdoc.Object) bool {
+	group := doc.Dict(o)
+	return group != nil && group.Name("S") == "Transparency"
+}
+
+// visitFunc is called for each dictionary visited by the visit function.
+// The ref argument contains the reference of the indirect object which
+// contains the dictionary. If the dictionary belongs to a stream, the stream
+// argument is set. The function returns true if it modifies the dictionary.
+type visitFunc func(ref pdfdoc.Ref, d pdfdoc.Dict, stream *pdfdoc.Stream) bool
+
+// visit calls fn for all the dictionaries of the document, including the
+// direct dictionaries nested in other objects. Modified objects are updated
+// in the document.
+func visit(doc *pdfdoc.Document, fn visitFunc) error {
+	var walk func(ref pdfdoc.Ref, o pdfdoc.Object) bool
+	walk = func(ref pdfdoc.Ref, o pdfdoc.Object) bool {
+		changed := false
+		switch v := o.(type) {
+		case pdfdoc.Dict:
+			changed = fn(ref, v, nil)
+			for _, val := range v {
+				changed = walk(ref, val) || changed
+			}
+		case *pdfdoc.Stream:
+			changed = fn(ref, v.Dict, v)
+			for _, val := range v.Dict {
+				changed = walk(ref, val) || changed
+			}
+		case pdfdoc.Array:
+			for _, val := range v {
+				changed = walk(ref, val) || changed
+			}
+		}
+
+		return changed
+	}
+
+	for _, ref := range doc.Refs() {
+		obj, err := doc.Get(ref)
+		if err != nil {
+			return err
+		}
+		if stream, ok := obj.(*pdfdoc.Stream); ok {
+			if typ := stream.Dict.Name("Type"); typ == "ObjStm" || typ == "XRef" {
+				continue
+			}
+		}
+
+		if walk(ref, obj) {
+			doc.Set(ref, obj)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,440 @@
+package pdfa
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adrg/go-wkhtmltopdf/internal/pdfdoc"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		part    int
+		version string
+		annots  []pdfdoc.Ref
+	}{
+		{part: 1, version: "1.4", annots: []pdfdoc.Ref{{Num: 8}}},
+		{part: 2, version: "1.7", annots: []pdfdoc.Ref{{Num: 8}}},
+		{part: 3, version: "1.7", annots: []pdfdoc.Ref{{Num: 8}, {Num: 10}}},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("PDF/A-%d", test.part), func(t *testing.T) {
+			doc := convertedDocument(t, test.part)
+			if doc.Version != test.version {
+				t.Errorf("expected version %s, got %s", test.version, doc.Version)
+			}
+			if id, ok := doc.Trailer["ID"].(pdfdoc.Array); !ok || len(id) != 2 {
+				t.Error("missing document identifier")
+			}
+
+			violations, err := Validate(doc, test.part)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(violations) > 0 {
+				t.Errorf("unexpected violations: %v", violations)
+			}
+
+			// Check document catalog.
+			catalog, _, err := doc.Catalog()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, key := range []pdfdoc.Name{"AA", "OpenAction"} {
+				if catalog[key] != nil {
+					t.Errorf("catalog entry %s was not removed", key)
+				}
+			}
+			if names := doc.Dict(catalog["Names"]); names["JavaScript"] != nil {
+				t.Error("JavaScript name tree was not removed")
+			}
+			if form := doc.Dict(catalog["AcroForm"]); form["NeedAppearances"] != nil {
+				t.Error("NeedAppearances flag was not removed")
+			}
+
+			intents := doc.Array(catalog["OutputIntents"])
+			if len(intents) != 1 {
+				t.Fatalf("expected 1 output intent, got %d", len(intents))
+			}
+			profile, ok := doc.Resolve(doc.Dict(intents[0])["DestOutputProfile"]).(*pdfdoc.Stream)
+			if !ok {
+				t.Fatal("output intent does not contain an ICC profile")
+			}
+			if data, err := profile.Decode(); err != nil || !bytes.Equal(data, SRGBProfile()) {
+				t.Errorf("unexpected output intent profile (%v)", err)
+			}
+
+			metadata, ok := doc.Resolve(catalog["Metadata"]).(*pdfdoc.Stream)
+			if !ok {
+				t.Fatal("missing XMP metadata")
+			}
+			data, err := metadata.Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if part, conformance := xmpIdentification(data); part != fmt.Sprint(test.part) || conformance != "B" {
+				t.Errorf("expected PDF/A-%dB identification, got PDF/A-%s%s", test.part, part, conformance)
+			}
+			for _, expected := range []string{"Quarterly report", "Jane Doe", "2024-01-01T12:00:00+02:00"} {
+				if !bytes.Contains(data, []byte(expected)) {
+					t.Errorf("XMP metadata does not contain %q", expected)
+				}
+			}
+
+			// Check document information.
+			info := doc.Info()
+			if date, _ := info.Text("CreationDate"); date != "D:20240101120000+02'00'" {
+				t.Errorf("unexpected creation date %q", date)
+			}
+			if _, ok := info["ModDate"]; ok {
+				t.Error("invalid modification date was not removed")
+			}
+
+			// Check page.
+			pages, err := doc.Pages()
+			if err != nil {
+				t.Fatal(err)
+			}
+			page := doc.Dict(pages[0])
+			if page["AA"] != nil {
+				t.Error("page additional actions were not removed")
+			}
+			if hasGroup := page["Group"] != nil; hasGroup != (test.part > 1) {
+				t.Errorf("unexpected page transparency group presence: %t", hasGroup)
+			}
+
+			var annots []pdfdoc.Ref
+			for _, annot := range doc.Array(page["Annots"]) {
+				ref, _ := annot.(pdfdoc.Ref)
+				annots = append(annots, ref)
+			}
+			if !reflect.DeepEqual(annots, test.annots) {
+				t.Errorf("expected annotations %v, got %v", test.annots, annots)
+			}
+			if doc.Resolve(pdfdoc.Ref{Num: 9}) != nil {
+				t.Error("removed annotation is still present in the document")
+			}
+			if flags, _ := pdfdoc.Int(doc.Dict(pdfdoc.Ref{Num: 8})["F"]); flags != 4 {
+				t.Errorf("expected link annotation flags 4, got %d", flags)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		part     int
+		mutate   func(doc *pdfdoc.Document)
+		expected string
+	}{
+		{
+			name:     "version",
+			part:     2,
+			mutate:   func(doc *pdfdoc.Document) { doc.Version = "2.0" },
+			expected: "PDF version 2.0 is not permitted in PDF/A-2 documents",
+		},
+		{
+			name:     "encryption",
+			part:     2,
+			mutate:   func(doc *pdfdoc.Document) { doc.Trailer["Encrypt"] = pdfdoc.Dict{} },
+			expected: "encryption is not permitted",
+		},
+		{
+			name: "missing output intent",
+			part: 2,
+			mutate: func(doc *pdfdoc.Document) {
+				updateObject(doc, pdfdoc.Ref{Num: 1}, func(d pdfdoc.Dict) { delete(d, "OutputIntents") })
+			},
+			expected: "the document must contain a PDF/A output intent with an embedded ICC profile (object 1 0 R)",
+		},
+		{
+			name: "metadata identification",
+			part: 3,
+			mutate: func(doc *pdfdoc.Document) {
+				catalog, _, _ := doc.Catalog()
+				doc.Set(catalog["Metadata"].(pdfdoc.Ref), pdfdoc.NewStream(nil, xmpPacket(metadata{}, 2), false))
+			},
+			expected: "the XMP metadata must identify the document as PDF/A-3b (object 15 0 R)",
+		},
+		{
+			name: "javascript action",
+			part: 2,
+			mutate: func(doc *pdfdoc.Document) {
+				updateObject(doc, pdfdoc.Ref{Num: 8}, func(d pdfdoc.Dict) {
+					d["A"] = pdfdoc.Dict{"S": pdfdoc.Name("JavaScript")}
+				})
+			},
+			expected: "JavaScript actions are not permitted (object 8 0 R)",
+		},
+		{
+			name: "hidden annotation",
+			part: 2,
+			mutate: func(doc *pdfdoc.Document) {
+				updateObject(doc, pdfdoc.Ref{Num: 8}, func(d pdfdoc.Dict) { d["F"] = int64(6) })
+			},
+			expected: "annotations must be printable and must not be hidden (object 8 0 R)",
+		},
+		{
+			name: "non-embedded font",
+			part: 1,
+			mutate: func(doc *pdfdoc.Document) {
+				doc.Set(pdfdoc.Ref{Num: 4}, pdfdoc.Dict{
+					"Type":     pdfdoc.Name("Font"),
+					"Subtype":  pdfdoc.Name("Type1"),
+					"BaseFont": pdfdoc.Name("Helvetica"),
+				})
+			},
+			expected: "font Helvetica is not embedded (object 4 0 R)",
+		},
+		{
+			name: "LZW filter",
+			part: 2,
+			mutate: func(doc *pdfdoc.Document) {
+				updateObject(doc, pdfdoc.Ref{Num: 11}, func(d pdfdoc.Dict) { d["Filter"] = pdfdoc.Name("LZWDecode") })
+			},
+			expected: "the LZWDecode filter is not permitted (object 11 0 R)",
+		},
+		{
+			name: "transparency",
+			part: 1,
+			mutate: func(doc *pdfdoc.Document) {
+				updateObject(doc, pdfdoc.Ref{Num: 3}, func(d pdfdoc.Dict) {
+					d["Resources"] = pdfdoc.Dict{
+						"ExtGState": pdfdoc.Dict{"GS1": pdfdoc.Dict{"ca": 0.5}},
+					}
+				})
+			},
+			expected: "constant alpha values other than 1.0 are not permitted in PDF/A-1 documents (object 3 0 R)",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc := convertedDocument(t, test.part)
+			test.mutate(doc)
+
+			violations, err := Validate(doc, test.part)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var messages []string
+			for _, v := range violations {
+				messages = append(messages, v.String())
+			}
+			if !reflect.DeepEqual(messages, []string{test.expected}) {
+				t.Errorf("expected violations [%s], got %q", test.expected, messages)
+			}
+		})
+	}
+}
+
+func TestConvertNonEmbeddedFonts(t *testing.T) {
+	doc := testDocument(t)
+	doc.Set(pdfdoc.Ref{Num: 4}, pdfdoc.Dict{
+		"Type":     pdfdoc.Name("Font"),
+		"Subtype":  pdfdoc.Name("TrueType"),
+		"BaseFont": pdfdoc.Name("Arial"),
+	})
+	size := len(doc.Refs())
+
+	err := Convert(doc, 2)
+	var fontErr *FontError
+	if !errors.As(err, &fontErr) {
+		t.Fatalf("expected font error, got %v", err)
+	}
+	expected := []Violation{{Object: pdfdoc.Ref{Num: 4}, Message: "font Arial is not embedded"}}
+	if !reflect.DeepEqual(fontErr.Violations, expected) {
+		t.Errorf("expected violations %v, got %v", expected, fontErr.Violations)
+	}
+
+	// The document must not be labelled as PDF/A.
+	catalog, _, err := doc.Catalog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if catalog["Metadata"] != nil || catalog["OutputIntents"] != nil || len(doc.Refs()) != size {
+		t.Error("expected document not to be modified")
+	}
+}
+
+func TestInvalidPart(t *testing.T) {
+	for _, part := range []int{0, 4} {
+		doc := testDocument(t)
+		if err := Convert(doc, part); err == nil {
+			t.Errorf("expected conversion error for part %d", part)
+		}
+		if _, err := Validate(doc, part); err == nil {
+			t.Errorf("expected validation error for part %d", part)
+		}
+	}
+}
+
+func TestXMPIdentification(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		part        string
+		conformance string
+	}{
+		{
+			name:        "generated",
+			data:        string(xmpPacket(metadata{Title: "<Title> & more"}, 2)),
+			part:        "2",
+			conformance: "B",
+		},
+		{
+			name: "attributes",
+			data: `<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">` +
+				`<rdf:Description rdf:about="" xmlns:pdfaid="http://www.aiim.org/pdfa/ns/id/" pdfaid:part="1" pdfaid:conformance="A"/>` +
+				`</rdf:RDF></x:xmpmeta>`,
+			part:        "1",
+			conformance: "A",
+		},
+		{
+			name: "missing",
+			data: `<x:xmpmeta xmlns:x="adobe:ns:meta/"/>`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			part, conformance := xmpIdentification([]byte(test.data))
+			if part != test.part || conformance != test.conformance {
+				t.Errorf("expected %q %q, got %q %q", test.part, test.conformance, part, conformance)
+			}
+		})
+	}
+}
+
+func TestXMPPacket(t *testing.T) {
+	packet := string(xmpPacket(metadata{
+		Title:        "A <b> & c",
+		CreationDate: time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC),
+	}, 3))
+
+	for _, expected := range []string{"A &lt;b&gt; &amp; c", "2024-03-01T08:30:00Z", `<?xpacket end="w"?>`} {
+		if !strings.Contains(packet, expected) {
+			t.Errorf("XMP packet does not contain %q", expected)
+		}
+	}
+}
+
+func TestSRGBProfile(t *testing.T) {
+	profile := SRGBProfile()
+	if len(profile) < 128 {
+		t.Fatalf("profile too short: %d bytes", len(profile))
+	}
+	if size := binary.BigEndian.Uint32(profile); int(size) != len(profile) {
+		t.Errorf("profile header size %d does not match length %d", size, len(profile))
+	}
+	if sig := string(profile[36:40]); sig != "acsp" {
+		t.Errorf("expected profile signature acsp, got %q", sig)
+	}
+	if class, space := string(profile[12:16]), string(profile[16:20]); class != "mntr" || space != "RGB " {
+		t.Errorf("expected RGB display profile, got %q %q", class, space)
+	}
+}
+
+// convertedDocument returns the test document, converted to the specified
+// PDF/A part, written and parsed again.
+func convertedDocument(t *testing.T, part int) *pdfdoc.Document {
+	t.Helper()
+
+	doc := testDocument(t)
+	if err := Convert(doc, part); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := pdfdoc.Parse(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return doc
+}
+
+// testDocument returns a document which uses features that are not
+// permitted in PDF/A documents.
+func testDocument(t *testing.T) *pdfdoc.Document {
+	t.Helper()
+
+	objects := []string{
+		// 1: catalog.
+		"<< /Type /Catalog /Pages 2 0 R /OpenAction 6 0 R /AA << /WC 6 0 R >> " +
+			"/Names << /JavaScript 7 0 R /EmbeddedFiles 7 0 R >> /AcroForm << /Fields [] /NeedAppearances true >> >>",
+		// 2: page tree.
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		// 3: page.
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Resources << /Font << /F1 4 0 R >> >> " +
+			"/Annots [8 0 R 9 0 R 10 0 R] /Group << /S /Transparency >> /AA << /O 6 0 R >> >>",
+		// 4: embedded font.
+		"<< /Type /Font /Subtype /TrueType /BaseFont /ABCDEF+DejaVuSans /FontDescriptor 5 0 R >>",
+		// 5: font descriptor.
+		"<< /Type /FontDescriptor /FontName /ABCDEF+DejaVuSans /FontFile2 11 0 R >>",
+		// 6: JavaScript action.
+		"<< /S /JavaScript /JS (app.alert\\(1\\)) >>",
+		// 7: name tree.
+		"<< /Names [] >>",
+		// 8: hidden link annotation.
+		"<< /Type /Annot /Subtype /Link /Rect [0 0 10 10] /F 2 /A << /S /URI /URI (https://example.com) >> >>",
+		// 9: movie annotation.
+		"<< /Type /Annot /Subtype /Movie /Rect [0 0 10 10] >>",
+		// 10: file attachment annotation.
+		"<< /Type /Annot /Subtype /FileAttachment /Rect [0 0 10 10] /F 4 /AP << /N 12 0 R >> >>",
+		// 11: font program.
+		"<< /Length 4 >>\nstream\nfont\nendstream",
+		// 12: annotation appearance.
+		"<< /Type /XObject /Subtype /Form /BBox [0 0 10 10] /Length 0 >>\nstream\n\nendstream",
+		// 13: document information.
+		"<< /Title (Quarterly report) /Author (Jane Doe) /CreationDate (D:20240101120000+02'00) /ModDate (invalid) >>",
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f\r\n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n\r\n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 13 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	doc, err := pdfdoc.Parse(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return doc
+}
+
+func updateObject(doc *pdfdoc.Document, ref pdfdoc.Ref, fn func(d pdfdoc.Dict)) {
+	switch obj := doc.Resolve(ref).(type) {
+	case pdfdoc.Dict:
+		d := obj.Clone()
+		fn(d)
+		doc.Set(ref, d)
+	case *pdfdoc.Stream:
+		d := obj.Dict.Clone()
+		fn(d)
+		doc.Set(ref, &pdfdoc.Stream{Dict: d, Data: obj.Data})
+	}
+}
@@ -0,0 +1,262 @@
+package pdfa
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/adrg/go-wkhtmltopdf/internal/pdfdoc"
+)
+
+// Validate checks the specified document against the requirements of the
+// B level of the specified PDF/A part and returns the detected violations.
+// The validation covers the document structure, metadata, fonts, actions,
+// annotations and transparency. Content streams are not analyzed.
+func Validate(doc *pdfdoc.Document, part int) ([]Violation, error) {
+	if part < 1 || part > 3 {
+		return nil, fmt.Errorf("invalid PDF/A part: %d", part)
+	}
+
+	var violations []Violation
+	report := func(ref pdfdoc.Ref, format string, args ...interface{}) {
+		violations = append(violations, Violation{Object: ref, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Check file structure.
+	switch {
+	case part == 1 && doc.Version > "1.4":
+		report(pdfdoc.Ref{}, "PDF version %s is not permitted in PDF/A-1 documents", doc.Version)
+	case doc.Version > "1.7":
+		report(pdfdoc.Ref{}, "PDF version %s is not permitted in PDF/A-%d documents", doc.Version, part)
+	}
+	if doc.Trailer["Encrypt"] != nil {
+		report(pdfdoc.Ref{}, "encryption is not permitted")
+	}
+	if id, ok := doc.Trailer["ID"].(pdfdoc.Array); !ok || len(id) != 2 {
+		report(pdfdoc.Ref{}, "the trailer must contain a document identifier")
+	}
+
+	// Check document catalog.
+	catalog, catalogRef, err := doc.Catalog()
+	if err != nil {
+		return nil, err
+	}
+
+	if catalog["AA"] != nil {
+		report(catalogRef, "additional actions are not permitted in the document catalog")
+	}
+	if part == 1 && catalog["OCProperties"] != nil {
+		report(catalogRef, "optional content is not permitted in PDF/A-1 documents")
+	}
+	if names := doc.Dict(catalog["Names"]); names != nil {
+		if names["JavaScript"] != nil {
+			report(catalogRef, "JavaScript is not permitted")
+		}
+		if part == 1 && names["EmbeddedFiles"] != nil {
+			report(catalogRef, "embedded files are not permitted in PDF/A-1 documents")
+		}
+	}
+	if form := doc.Dict(catalog["AcroForm"]); form != nil {
+		if needAppearances, _ := form["NeedAppearances"].(bool); needAppearances {
+			report(catalogRef, "the NeedAppearances flag of interactive forms must not be set")
+		}
+		if form["XFA"] != nil {
+			report(catalogRef, "XFA forms are not permitted")
+		}
+	}
+	if !hasOutputIntent(doc, catalog) {
+		report(catalogRef, "the document must contain a PDF/A output intent with an embedded ICC profile")
+	}
+	violations = append(violations, validateMetadata(doc, catalog, catalogRef, part)...)
+
+	// Check document objects.
+	err = visit(doc, func(ref pdfdoc.Ref, d pdfdoc.Dict, stream *pdfdoc.Stream) bool {
+		if d["AA"] != nil {
+			report(ref, "additional actions are not permitted")
+		}
+		for _, key := range []pdfdoc.Name{"A", "OpenAction", "Next"} {
+			if action := doc.Dict(d[key]); action != nil && forbiddenAction(action, part) {
+				report(ref, "%s actions are not permitted", action.Name("S"))
+			}
+		}
+
+		if stream != nil {
+			validateStream(doc, ref, d, part, report)
+		}
+		if isAnnotation(d) {
+			validateAnnotation(ref, d, part, report)
+		}
+		if part == 1 && d.Name("Type") == "Page" && isTransparencyGroup(doc, d["Group"]) {
+			report(ref, "transparency groups are not permitted in PDF/A-1 documents")
+		}
+		if isFont(d) {
+			validateFont(doc, ref, d, part, report)
+		}
+		if states := doc.Dict(d["ExtGState"]); states != nil {
+			for _, state := range states {
+				stateRef := ref
+				if r, ok := state.(pdfdoc.Ref); ok {
+					stateRef = r
+				}
+				if gs := doc.Dict(state); gs != nil {
+					validateGraphicsState(stateRef, gs, part, report)
+				}
+			}
+		}
+
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Remove duplicate violations, which are reported for objects shared
+	// by multiple resource dictionaries.
+	seen := map[Violation]bool{}
+	unique := violations[:0]
+	for _, v := range violations {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+
+	return unique, nil
+}
+
+type reportFunc func(ref pdfdoc.Ref, format string, args ...interface{})
+
+func validateMetadata(doc *pdfdoc.Document, catalog pdfdoc.Dict, catalogRef pdfdoc.Ref, part int) []Violation {
+	metadata, ok := doc.Resolve(catalog["Metadata"]).(*pdfdoc.Stream)
+	if !ok {
+		return []Violation{{Object: catalogRef, Message: "the document catalog must contain XMP metadata"}}
+	}
+
+	ref, _ := catalog["Metadata"].(pdfdoc.Ref)
+	if part == 1 && metadata.Dict["Filter"] != nil {
+		return []Violation{{Object: ref, Message: "the XMP metadata stream must not be compressed in PDF/A-1 documents"}}
+	}
+
+	data, err := metadata.Decode()
+	if err != nil {
+		return []Violation{{Object: ref, Message: fmt.Sprintf("the XMP metadata stream cannot be decoded: %v", err)}}
+	}
+
+	var violations []Violation
+	if declared, conformance := xmpIdentification(data); declared != strconv.Itoa(part) || (conformance != "B" && conformance != "A") {
+		violations = append(violations, Violation{
+			Object:  ref,
+			Message: fmt.Sprintf("the XMP metadata must identify the document as PDF/A-%db", part),
+		})
+	}
+
+	return violations
+}
+
+func validateStream(doc *pdfdoc.Document, ref pdfdoc.Ref, d pdfdoc.Dict, part int, report reportFunc) {
+	for _, key := range []pdfdoc.Name{"F", "FFilter", "FDecodeParms"} {
+		if d[key] != nil {
+			report(ref, "streams must not reference external files")
+			break
+		}
+	}
+
+	filters := doc.Array(d["Filter"])
+	if name, ok := d["Filter"].(pdfdoc.Name); ok {
+		filters = pdfdoc.Array{name}
+	}
+	for _, filter := range filters {
+		switch filter {
+		case pdfdoc.Name("LZWDecode"):
+			report(ref, "the LZWDecode filter is not permitted")
+		case pdfdoc.Name("JPXDecode"):
+			if part == 1 {
+				report(ref, "the JPXDecode filter is not permitted in PDF/A-1 documents")
+			}
+		}
+	}
+
+	switch d.Name("Subtype") {
+	case "Image":
+		if interpolate, _ := d["Interpolate"].(bool); interpolate {
+			report(ref, "image interpolation is not permitted")
+		}
+		if d["Alternates"] != nil || d["OPI"] != nil {
+			report(ref, "alternate images and OPI are not permitted")
+		}
+		if part == 1 && d["SMask"] != nil {
+			report(ref, "soft masked images are not permitted in PDF/A-1 documents")
+		}
+	case "Form":
+		if d["OPI"] != nil || d["PS"] != nil {
+			report(ref, "form XObjects must not contain OPI or PostScript entries")
+		}
+		if part == 1 && isTransparencyGroup(doc, d["Group"]) {
+			report(ref, "transparency groups are not permitted in PDF/A-1 documents")
+		}
+	case "PS":
+		report(ref, "PostScript XObjects are not permitted")
+	}
+}
+
+func validateAnnotation(ref pdfdoc.Ref, d pdfdoc.Dict, part int, report reportFunc) {
+	subtype := d.Name("Subtype")
+	if !annotationPermitted(subtype, part) {
+		report(ref, "%s annotations are not permitted in PDF/A-%d documents", subtype, part)
+	}
+	if subtype != "Popup" {
+		if flags, _ := pdfdoc.Int(d["F"]); flags != annotationFlags(d) {
+			report(ref, "annotations must be printable and must not be hidden")
+		}
+	}
+	if part > 1 && subtype != "Popup" && subtype != "Link" && d["AP"] == nil {
+		report(ref, "%s annotations must have an appearance stream", subtype)
+	}
+}
+
+func validateFont(doc *pdfdoc.Document, ref pdfdoc.Ref, font pdfdoc.Dict, part int, report reportFunc) {
+	name := font.Name("BaseFont")
+	if !fontEmbedded(doc, font) {
+		report(ref, "font %s is not embedded", name)
+		return
+	}
+	if font.Name("Subtype") != "Type0" {
+		return
+	}
+
+	descendants := doc.Array(font["DescendantFonts"])
+	if len(descendants) == 0 {
+		return
+	}
+	cidFont := doc.Dict(descendants[0])
+	if cidFont.Name("Subtype") == "CIDFontType2" && cidFont["CIDToGIDMap"] == nil {
+		report(ref, "font %s must specify a CID to glyph mapping", name)
+	}
+	if desc := fontDescriptor(doc, font); part == 1 && isSubset(cidFont.Name("BaseFont")) && desc["CIDSet"] == nil {
+		report(ref, "subset font %s must contain a CIDSet in PDF/A-1 documents", name)
+	}
+}
+
+func validateGraphicsState(ref pdfdoc.Ref, gs pdfdoc.Dict, part int, report reportFunc) {
+	if gs["TR"] != nil {
+		report(ref, "transfer functions are not permitted")
+	}
+	if tr2, ok := gs["TR2"]; ok && tr2 != pdfdoc.Name("Default") {
+		report(ref, "transfer functions are not permitted")
+	}
+	if part > 1 {
+		return
+	}
+
+	if mask, ok := gs["SMask"]; ok && mask != pdfdoc.Name("None") {
+		report(ref, "soft masks are not permitted in PDF/A-1 documents")
+	}
+	for _, key := range []pdfdoc.Name{"CA", "ca"} {
+		if alpha, ok := pdfdoc.Float(gs[key]); ok && alpha != 1 {
+			report(ref, "constant alpha values other than 1.0 are not permitted in PDF/A-1 documents")
+			break
+		}
+	}
+	if mode, ok := gs["BM"].(pdfdoc.Name); ok && mode != "Normal" && mode != "Compatible" {
+		report(ref, "blend mode %s is not permitted in PDF/A-1 documents", mode)
+	}
+}
@@ -0,0 +1,129 @@
+package pdfa
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// metadata contains the document properties included in the XMP metadata
+// stream of the document.
+type metadata struct {
+	Title        string
+	Author       string
+	Subject      string
+	Keywords     string
+	Creator      string
+	Producer     string
+	CreationDate time.Time
+	ModDate      time.Time
+}
+
+// xmpPacket returns an XMP packet describing the specified properties and
+// PDF/A identification. The packet is padded in order to allow in-place
+// updates, as recommended by the XMP specification.
+func xmpPacket(m metadata, part int) []byte {
+	esc := func(s string) string {
+		var buf bytes.Buffer
+		xml.EscapeText(&buf, []byte(s)) // nolint:errcheck
+		return buf.String()
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("<?xpacket begin=\"\xef\xbb\xbf\" id=\"W5M0MpCehiHzreSzNTczkc9d\"?>\n")
+	buf.WriteString("<x:xmpmeta xmlns:x=\"adobe:ns:meta/\">\n")
+	buf.WriteString("<rdf:RDF xmlns:rdf=\"http://www.w3.org/1999/02/22-rdf-syntax-ns#\">\n")
+
+	buf.WriteString("<rdf:Description rdf:about=\"\" xmlns:pdfaid=\"http://www.aiim.org/pdfa/ns/id/\">\n")
+	fmt.Fprintf(&buf, "<pdfaid:part>%d</pdfaid:part>\n", part)
+	buf.WriteString("<pdfaid:conformance>B</pdfaid:conformance>\n")
+	buf.WriteString("</rdf:Description>\n")
+
+	buf.WriteString("<rdf:Description rdf:about=\"\" xmlns:dc=\"http://purl.org/dc/elements/1.1/\">\n")
+	buf.WriteString("<dc:format>application/pdf</dc:format>\n")
+	if m.Title != "" {
+		fmt.Fprintf(&buf, "<dc:title><rdf:Alt><rdf:li xml:lang=\"x-default\">%s</rdf:li></rdf:Alt></dc:title>\n", esc(m.Title))
+	}
+	if m.Author != "" {
+		fmt.Fprintf(&buf, "<dc:creator><rdf:Seq><rdf:li>%s</rdf:li></rdf:Seq></dc:creator>\n", esc(m.Author))
+	}
+	if m.Subject != "" {
+		fmt.Fprintf(&buf, "<dc:description><rdf:Alt><rdf:li xml:lang=\"x-default\">%s</rdf:li></rdf:Alt></dc:description>\n", esc(m.Subject))
+	}
+	buf.WriteString("</rdf:Description>\n")
+
+	buf.WriteString("<rdf:Description rdf:about=\"\" xmlns:xmp=\"http://ns.adobe.com/xap/1.0/\">\n")
+	if m.Creator != "" {
+		fmt.Fprintf(&buf, "<xmp:CreatorTool>%s</xmp:CreatorTool>\n", esc(m.Creator))
+	}
+	if !m.CreationDate.IsZero() {
+		fmt.Fprintf(&buf, "<xmp:CreateDate>%s</xmp:CreateDate>\n", m.CreationDate.Format(time.RFC3339))
+	}
+	if !m.ModDate.IsZero() {
+		fmt.Fprintf(&buf, "<xmp:ModifyDate>%s</xmp:ModifyDate>\n", m.ModDate.Format(time.RFC3339))
+		fmt.Fprintf(&buf, "<xmp:MetadataDate>%s</xmp:MetadataDate>\n", m.ModDate.Format(time.RFC3339))
+	}
+	buf.WriteString("</rdf:Description>\n")
+
+	buf.WriteString("<rdf:Description rdf:about=\"\" xmlns:pdf=\"http://ns.adobe.com/pdf/1.3/\">\n")
+	if m.Producer != "" {
+		fmt.Fprintf(&buf, "<pdf:Producer>%s</pdf:Producer>\n", esc(m.Producer))
+	}
+	if m.Keywords != "" {
+		fmt.Fprintf(&buf, "<pdf:Keywords>%s</pdf:Keywords>\n", esc(m.Keywords))
+	}
+	buf.WriteString("</rdf:Description>\n")
+
+	buf.WriteString("</rdf:RDF>\n</x:xmpmeta>\n")
+	buf.WriteString(strings.Repeat(strings.Repeat(" ", 99)+"\n", 20))
+	buf.WriteString("<?xpacket end=\"w\"?>")
+
+	return buf.Bytes()
+}
+
+// xmpIdentification returns the PDF/A part and conformance level declared
+// in the specified XMP packet. Both attribute and element forms of the
+// identification properties are recognized.
+func xmpIdentification(data []byte) (part string, conformance string) {
+	const ns = "http://www.aiim.org/pdfa/ns/id/"
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return part, conformance
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		for _, attr := range start.Attr {
+			if attr.Name.Space != ns {
+				continue
+			}
+			switch attr.Name.Local {
+			case "part":
+				part = attr.Value
+			case "conformance":
+				conformance = attr.Value
+			}
+		}
+		if start.Name.Space != ns {
+			continue
+		}
+
+		var value string
+		if err := dec.DecodeElement(&value, &start); err != nil {
+			return part, conformance
+		}
+		switch start.Name.Local {
+		case "part":
+			part = value
+		case "conformance":
+			conformance = value
+		}
+	}
+}
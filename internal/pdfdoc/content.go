@@ -0,0 +1,79 @@
+package pdfdoc
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Operation represents an operation of a content stream, consisting of an
+// operator and the operands preceding it.
+type Operation struct {
+	// The operator of the operation.
+	// E.g.: "Tj".
+	Operator string
+
+	// The operands of the operation.
+	Operands []Object
+}
+
+// ParseContent parses the specified content stream data into a list of
+// operations. The data of inline images is skipped.
+func ParseContent(data []byte) ([]Operation, error) {
+	var (
+		ops      []Operation
+		operands []Object
+	)
+
+	p := newParser(data, 0)
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			break
+		}
+
+		tok, err := p.token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch v := tok.(type) {
+		case keyword:
+			switch v {
+			case "true", "false", "null":
+				obj, _ := p.objectFrom(v)
+				operands = append(operands, obj)
+				continue
+			case "ID":
+				// Skip inline image data, up to the EI operator.
+				end := bytes.Index(p.data[p.pos:], []byte("EI"))
+				if end < 0 {
+					return nil, fmt.Errorf("%w: unterminated inline image", errSyntax)
+				}
+				p.pos += end + 2
+				v = "EI"
+			}
+
+			ops = append(ops, Operation{Operator: string(v), Operands: operands})
+			operands = nil
+		case delimiter:
+			switch v {
+			case "[", "<<":
+				obj, err := p.objectFrom(v)
+				if err != nil {
+					return nil, err
+				}
+				operands = append(operands, obj)
+			case "{", "}":
+				// PostScript procedures, used by CMaps, are ignored.
+			default:
+				return nil, fmt.Errorf("%w: unexpected token %v at offset %d", errSyntax, v, p.pos)
+			}
+		default:
+			// Content streams do not contain indirect references, so the
+			// operands are not parsed using objectFrom.
+			operands = append(operands, v)
+		}
+	}
+
+	return ops, nil
+}
@@ -0,0 +1,612 @@
+package pdfdoc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// ErrNotFound is returned when a requested object does not exist.
+var ErrNotFound = errors.New("object not found")
+
+type xrefEntry struct {
+	gen    int
+	offset int // Byte offset of the object, for uncompressed objects.
+	stream int // Object stream number, for compressed objects.
+	index  int // Index inside the object stream, for compressed objects.
+	free   bool
+}
+
+// Document represents a parsed PDF document.
+type Document struct {
+	// Version contains the PDF version from the header of the document.
+	// E.g.: "1.4".
+	Version string
+
+	// Trailer contains the trailer dictionary of the document.
+	Trailer Dict
+
+	data      []byte
+	startxref int
+	xref      map[int]xrefEntry
+	objects   map[int]Object
+	modified  map[int]bool
+	loading   map[int]bool
+	size      int
+}
+
+var (
+	headerRegexp  = regexp.MustCompile(`%PDF-(\d\.\d)`)
+	objectRegexp  = regexp.MustCompile(`(?m)(?:^|[\r\n\s])(\d+)\s+(\d+)\s+obj\b`)
+	startxrefWord = []byte("startxref")
+)
+
+// Parse parses the specified PDF document data.
+func Parse(data []byte) (*Document, error) {
+	loc := headerRegexp.FindSubmatchIndex(data[:min(1024, len(data))])
+	if loc == nil {
+		return nil, errors.New("missing PDF header")
+	}
+
+	d := &Document{
+		Version:  string(data[loc[2]:loc[3]]),
+		data:     data,
+		xref:     map[int]xrefEntry{},
+		objects:  map[int]Object{},
+		modified: map[int]bool{},
+		loading:  map[int]bool{},
+	}
+
+	// Reconstruct the cross-reference information if it is damaged.
+	if err := d.readXref(); err != nil || d.Dict(d.Trailer["Root"]) == nil {
+		if err := d.reconstructXref(); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := d.Trailer["Root"].(Ref); !ok {
+		return nil, errors.New("missing document catalog")
+	}
+
+	return d, nil
+}
+
+// Data returns the original document data.
+func (d *Document) Data() []byte {
+	return d.data
+}
+
+// Size returns the number of entries in the cross-reference table of the
+// document, which is one greater than the highest object number.
+func (d *Document) Size() int {
+	return d.size
+}
+
+// Refs returns the references of all the objects in the document, sorted
+// by object number.
+func (d *Document) Refs() []Ref {
+	refs := make([]Ref, 0, len(d.xref))
+	for num, entry := range d.xref {
+		if num == 0 || entry.free {
+			continue
+		}
+		refs = append(refs, Ref{Num: num, Gen: entry.gen})
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].Num < refs[j].Num })
+
+	return refs
+}
+
+// Get returns the object with the specified reference.
+func (d *Document) Get(ref Ref) (Object, error) {
+	if obj, ok := d.objects[ref.Num]; ok {
+		return obj, nil
+	}
+
+	entry, ok := d.xref[ref.Num]
+	if !ok || entry.free {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, ref)
+	}
+	if d.loading[ref.Num] {
+		return nil, fmt.Errorf("circular reference to object %s", ref)
+	}
+	d.loading[ref.Num] = true
+	defer delete(d.loading, ref.Num)
+
+	var (
+		obj Object
+		err error
+	)
+	if entry.stream > 0 {
+		obj, err = d.loadCompressed(entry.stream, entry.index, ref.Num)
+	} else {
+		obj, err = d.loadAt(entry.offset, ref.Num)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	d.objects[ref.Num] = obj
+	return obj, nil
+}
+
+// Resolve returns the object referenced by o, if o is a reference.
+// Otherwise, o is returned. Missing objects are resolved to nil.
+func (d *Document) Resolve(o Object) Object {
+	for i := 0; i < 32; i++ {
+		ref, ok := o.(Ref)
+		if !ok {
+			return o
+		}
+
+		obj, err := d.Get(ref)
+		if err != nil {
+			return nil
+		}
+		o = obj
+	}
+
+	return nil
+}
+
+// Dict returns the dictionary referenced by o. If o resolves to a stream,
+// the dictionary of the stream is returned.
+func (d *Document) Dict(o Object) Dict {
+	switch v := d.Resolve(o).(type) {
+	case Dict:
+		return v
+	case *Stream:
+		return v.Dict
+	}
+
+	return nil
+}
+
+// Array returns the array referenced by o.
+func (d *Document) Array(o Object) Array {
+	a, _ := d.Resolve(o).(Array)
+	return a
+}
+
+// Set replaces the object with the specified reference.
+func (d *Document) Set(ref Ref, o Object) {
+	d.objects[ref.Num] = o
+	d.modified[ref.Num] = true
+	d.xref[ref.Num] = xrefEntry{gen: ref.Gen}
+	if ref.Num >= d.size {
+		d.size = ref.Num + 1
+	}
+}
+
+// Add adds a new indirect object to the document and returns its reference.
+func (d *Document) Add(o Object) Ref {
+	ref := Ref{Num: d.size}
+	d.Set(ref, o)
+
+	return ref
+}
+
+// Delete removes the object with the specified reference.
+func (d *Document) Delete(ref Ref) {
+	delete(d.objects, ref.Num)
+	d.modified[ref.Num] = true
+	d.xref[ref.Num] = xrefEntry{gen: ref.Gen + 1, free: true}
+}
+
+// Catalog returns the document catalog and its reference.
+func (d *Document) Catalog() (Dict, Ref, error) {
+	ref, _ := d.Trailer["Root"].(Ref)
+
+	catalog := d.Dict(ref)
+	if catalog == nil {
+		return nil, ref, errors.New("invalid document catalog")
+	}
+
+	return catalog, ref, nil
+}
+
+// Info returns the document information dictionary, if the document has one.
+func (d *Document) Info() Dict {
+	return d.Dict(d.Trailer["Info"])
+}
+
+// Pages returns the references of the page objects of the document, in
+// page order.
+func (d *Document) Pages() ([]Ref, error) {
+	catalog, _, err := d.Catalog()
+	if err != nil {
+		return nil, err
+	}
+	root, ok := catalog["Pages"].(Ref)
+	if !ok {
+		return nil, errors.New("invalid page tree")
+	}
+
+	var (
+		pages   []Ref
+		visited = map[int]bool{}
+	)
+
+	var walk func(ref Ref) error
+	walk = func(ref Ref) error {
+		if visited[ref.Num] {
+			return fmt.Errorf("invalid page tree: cycle at object %s", ref)
+		}
+		visited[ref.Num] = true
+
+		node := d.Dict(ref)
+		if node == nil {
+			return fmt.Errorf("invalid page tree node %s", ref)
+		}
+		if node.Name("Type") == "Page" || node["Kids"] == nil {
+			pages = append(pages, ref)
+			return nil
+		}
+
+		for _, kid := range d.Array(node["Kids"]) {
+			if kidRef, ok := kid.(Ref); ok {
+				if err := walk(kidRef); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+	if err := walk(root); err != nil {
+		return nil, err
+	}
+
+	return pages, nil
+}
+
+// Inherited returns the value of a page attribute, taking into account the
+// attributes inherited from the ancestors of the page.
+func (d *Document) Inherited(page Dict, key Name) Object {
+	for i := 0; page != nil && i < 64; i++ {
+		if v, ok := page[key]; ok {
+			return v
+		}
+		page = d.Dict(page["Parent"])
+	}
+
+	return nil
+}
+
+func (d *Document) loadAt(offset, num int) (Object, error) {
+	if offset <= 0 || offset >= len(d.data) {
+		return nil, fmt.Errorf("invalid offset for object %d", num)
+	}
+
+	p := newParser(d.data, offset)
+	p.resolveLength = d.resolveLength
+
+	ref, obj, err := p.indirect()
+	if err != nil {
+		return nil, err
+	}
+	if ref.Num != num {
+		return nil, fmt.Errorf("object number mismatch: expected %d, found %d", num, ref.Num)
+	}
+
+	return obj, nil
+}
+
+func (d *Document) resolveLength(ref Ref) (int64, bool) {
+	obj, err := d.Get(ref)
+	if err != nil {
+		return 0, false
+	}
+
+	return Int(obj)
+}
+
+func (d *Document) loadCompressed(streamNum, index, num int) (Object, error) {
+	obj, err := d.Get(Ref{Num: streamNum})
+	if err != nil {
+		return nil, err
+	}
+	stream, ok := obj.(*Stream)
+	if !ok || stream.Dict.Name("Type") != "ObjStm" {
+		return nil, fmt.Errorf("invalid object stream %d", streamNum)
+	}
+
+	data, err := stream.Decode()
+	if err != nil {
+		return nil, err
+	}
+	n, _ := Int(stream.Dict["N"])
+	first, _ := Int(stream.Dict["First"])
+
+	// Read object number and offset pairs.
+	p := newParser(data, 0)
+	for i := 0; i < int(n); i++ {
+		objNum, err := p.token()
+		if err != nil {
+			return nil, err
+		}
+		offset, err := p.token()
+		if err != nil {
+			return nil, err
+		}
+		if v, ok := objNum.(int64); !ok || int(v) != num || i != index {
+			continue
+		}
+
+		off, ok := offset.(int64)
+		if !ok {
+			break
+		}
+
+		p.pos = int(first + off)
+		return p.object()
+	}
+
+	return nil, fmt.Errorf("%w: object %d in object stream %d", ErrNotFound, num, streamNum)
+}
+
+func (d *Document) readXref() error {
+	idx := bytes.LastIndex(d.data, startxrefWord)
+	if idx < 0 {
+		return errors.New("missing startxref keyword")
+	}
+
+	p := newParser(d.data, idx+len(startxrefWord))
+	tok, err := p.token()
+	if err != nil {
+		return err
+	}
+	offset, ok := tok.(int64)
+	if !ok {
+		return errors.New("invalid startxref offset")
+	}
+	d.startxref = int(offset)
+
+	visited := map[int]bool{}
+	for next := int(offset); next > 0; {
+		if visited[next] || next >= len(d.data) {
+			return errors.New("invalid cross-reference chain")
+		}
+		visited[next] = true
+
+		trailer, err := d.readXrefSection(next)
+		if err != nil {
+			return err
+		}
+		if d.Trailer == nil {
+			d.Trailer = trailer
+		}
+
+		// Read hybrid file cross-reference streams.
+		if stm, ok := Int(trailer["XRefStm"]); ok {
+			if _, err := d.readXrefSection(int(stm)); err != nil {
+				return err
+			}
+		}
+
+		prev, ok := Int(trailer["Prev"])
+		if !ok {
+			break
+		}
+		next = int(prev)
+	}
+
+	if size, ok := Int(d.Trailer["Size"]); ok && int(size) > d.size {
+		d.size = int(size)
+	}
+
+	return nil
+}
+
+func (d *Document) readXrefSection(offset int) (Dict, error) {
+	p := newParser(d.data, offset)
+	tok, err := p.token()
+	if err != nil {
+		return nil, err
+	}
+	if tok != keyword("xref") {
+		p.pos = offset
+		return d.readXrefStream(p)
+	}
+
+	for {
+		tok, err := p.token()
+		if err != nil {
+			return nil, err
+		}
+		if tok == keyword("trailer") {
+			break
+		}
+
+		start, ok1 := tok.(int64)
+		tok, err = p.token()
+		if err != nil {
+			return nil, err
+		}
+		count, ok2 := tok.(int64)
+		if !ok1 || !ok2 {
+			return nil, errors.New("invalid cross-reference subsection")
+		}
+
+		for i := 0; i < int(count); i++ {
+			off, err := p.token()
+			if err != nil {
+				return nil, err
+			}
+			gen, err := p.token()
+			if err != nil {
+				return nil, err
+			}
+			typ, err := p.token()
+			if err != nil {
+				return nil, err
+			}
+
+			o, ok1 := off.(int64)
+			g, ok2 := gen.(int64)
+			if !ok1 || !ok2 || (typ != keyword("n") && typ != keyword("f")) {
+				return nil, errors.New("invalid cross-reference entry")
+			}
+
+			num := int(start) + i
+			d.setXref(num, xrefEntry{gen: int(g), offset: int(o), free: typ == keyword("f")})
+		}
+	}
+
+	obj, err := p.object()
+	if err != nil {
+		return nil, err
+	}
+	trailer, ok := obj.(Dict)
+	if !ok {
+		return nil, errors.New("invalid trailer dictionary")
+	}
+
+	return trailer, nil
+}
+
+func (d *Document) readXrefStream(p *parser) (Dict, error) {
+	_, obj, err := p.indirect()
+	if err != nil {
+		return nil, err
+	}
+	stream, ok := obj.(*Stream)
+	if !ok || stream.Dict.Name("Type") != "XRef" {
+		return nil, errors.New("invalid cross-reference stream")
+	}
+
+	data, err := stream.Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	var widths [3]int
+	w, _ := stream.Dict["W"].(Array)
+	if len(w) != 3 {
+		return nil, errors.New("invalid cross-reference stream field widths")
+	}
+	for i := range widths {
+		v, _ := Int(w[i])
+		widths[i] = int(v)
+	}
+	rowSize := widths[0] + widths[1] + widths[2]
+	if rowSize == 0 {
+		return nil, errors.New("invalid cross-reference stream field widths")
+	}
+
+	size, _ := Int(stream.Dict["Size"])
+	index := Array{int64(0), size}
+	if v, ok := stream.Dict["Index"].(Array); ok {
+		index = v
+	}
+
+	readField := func(row []byte, start, width int, def int) int {
+		if width == 0 {
+			return def
+		}
+
+		v := 0
+		for _, b := range row[start : start+width] {
+			v = v<<8 | int(b)
+		}
+		return v
+	}
+
+	for i := 0; i+1 < len(index); i += 2 {
+		start, _ := Int(index[i])
+		count, _ := Int(index[i+1])
+
+		for j := 0; j < int(count); j++ {
+			if len(data) < rowSize {
+				return nil, errors.New("truncated cross-reference stream")
+			}
+			row := data[:rowSize]
+			data = data[rowSize:]
+
+			num := int(start) + j
+			typ := readField(row, 0, widths[0], 1)
+			f2 := readField(row, widths[0], widths[1], 0)
+			f3 := readField(row, widths[0]+widths[1], widths[2], 0)
+
+			switch typ {
+			case 0:
+				d.setXref(num, xrefEntry{gen: f3, free: true})
+			case 1:
+				d.setXref(num, xrefEntry{gen: f3, offset: f2})
+			case 2:
+				d.setXref(num, xrefEntry{stream: f2, index: f3})
+			}
+		}
+	}
+
+	return stream.Dict, nil
+}
+
+// setXref registers a cross-reference entry, unless a newer entry for the
+// same object number was already registered.
+func (d *Document) setXref(num int, entry xrefEntry) {
+	if _, ok := d.xref[num]; ok {
+		return
+	}
+
+	d.xref[num] = entry
+	if num >= d.size {
+		d.size = num + 1
+	}
+}
+
+func (d *Document) reconstructXref() error {
+	d.xref = map[int]xrefEntry{}
+	d.objects = map[int]Object{}
+	d.Trailer = nil
+
+	// Register object definitions. Later definitions take precedence.
+	matches := objectRegexp.FindAllSubmatchIndex(d.data, -1)
+	for i := len(matches) - 1; i >= 0; i-- {
+		m := matches[i]
+		num, _ := strconv.Atoi(string(d.data[m[2]:m[3]]))
+		gen, _ := strconv.Atoi(string(d.data[m[4]:m[5]]))
+		d.setXref(num, xrefEntry{gen: gen, offset: m[2]})
+	}
+
+	// Use the last trailer dictionary which references a document catalog.
+	for idx := len(d.data); idx > 0; {
+		idx = bytes.LastIndex(d.data[:idx], []byte("trailer"))
+		if idx < 0 {
+			break
+		}
+
+		p := newParser(d.data, idx+len("trailer"))
+		if obj, err := p.object(); err == nil {
+			if trailer, ok := obj.(Dict); ok && trailer["Root"] != nil {
+				d.Trailer = trailer
+				break
+			}
+		}
+	}
+
+	// Look for a cross-reference stream or a catalog, if no trailer exists.
+	if d.Trailer == nil {
+		for _, ref := range d.Refs() {
+			dict := d.Dict(ref)
+			switch dict.Name("Type") {
+			case "XRef":
+				if dict["Root"] != nil {
+					d.Trailer = dict.Clone()
+				}
+			case "Catalog":
+				if d.Trailer == nil {
+					d.Trailer = Dict{"Root": ref}
+				}
+			}
+		}
+	}
+	if d.Trailer == nil {
+		return errors.New("could not find trailer dictionary")
+	}
+
+	d.startxref = 0
+	return nil
+}
@@ -0,0 +1,362 @@
+package pdfdoc
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDocumentWrite(t *testing.T) {
+	doc, err := Parse(minimalPDF(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info := doc.Add(Dict{"Title": String("Round trip")})
+	content := doc.Add(NewStream(nil, []byte("BT (Page) Tj ET"), true))
+	doc.Trailer["Info"] = info
+
+	pages, err := doc.Pages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := doc.Dict(pages[0]).Clone()
+	page["Contents"] = content
+	doc.Set(pages[0], page)
+
+	tests := []struct {
+		name  string
+		write func(*Document, *bytes.Buffer) error
+	}{
+		{name: "full", write: func(d *Document, buf *bytes.Buffer) error { return d.Write(buf) }},
+		{name: "update", write: func(d *Document, buf *bytes.Buffer) error { return d.WriteUpdate(buf) }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := test.write(doc, &buf); err != nil {
+				t.Fatal(err)
+			}
+
+			written, err := Parse(buf.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if written.Size() != doc.Size() {
+				t.Errorf("expected size %d, got %d", doc.Size(), written.Size())
+			}
+			if !reflect.DeepEqual(written.Refs(), doc.Refs()) {
+				t.Errorf("expected objects %v, got %v", doc.Refs(), written.Refs())
+			}
+			if title, _ := written.Info().Text("Title"); title != "Round trip" {
+				t.Errorf("expected title %q, got %q", "Round trip", title)
+			}
+
+			pages, err := written.Pages()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pages) != 2 {
+				t.Fatalf("expected 2 pages, got %d", len(pages))
+			}
+
+			stream, ok := written.Resolve(written.Dict(pages[0])["Contents"]).(*Stream)
+			if !ok {
+				t.Fatal("missing page content stream")
+			}
+			data, err := stream.Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != "BT (Page) Tj ET" {
+				t.Errorf("unexpected page content %q", data)
+			}
+		})
+	}
+}
+
+func TestDocumentWriteUpdate(t *testing.T) {
+	original := minimalPDF(1)
+	doc, err := Parse(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pages, err := doc.Pages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.Delete(pages[0])
+	added := doc.Add(Dict{"Type": Name("Page"), "Parent": Ref{Num: 2}})
+	doc.Set(Ref{Num: 2}, Dict{"Type": Name("Pages"), "Kids": Array{added}, "Count": int64(1)})
+
+	var buf bytes.Buffer
+	if err := doc.WriteUpdate(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), original) {
+		t.Fatal("incremental update does not preserve the original data")
+	}
+
+	updated, err := Parse(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prev, _ := Int(updated.Trailer["Prev"]); prev <= 0 {
+		t.Error("incremental update trailer does not reference the previous section")
+	}
+	if _, err := updated.Get(pages[0]); err == nil {
+		t.Errorf("expected deleted object %s to be free", pages[0])
+	}
+
+	updatedPages, err := updated.Pages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(updatedPages, []Ref{added}) {
+		t.Errorf("expected pages %v, got %v", []Ref{added}, updatedPages)
+	}
+}
+
+func TestParseCompressed(t *testing.T) {
+	// Objects 2 and 3 are stored in the object stream 4, and the document
+	// uses a cross-reference stream.
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.5\n")
+
+	catalog := buf.Len()
+	buf.WriteString("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+
+	objects := "<< /Type /Pages /Kids [3 0 R] /Count 1 >> << /Type /Page /Parent 2 0 R >>"
+	header := fmt.Sprintf("2 0 3 %d ", len("<< /Type /Pages /Kids [3 0 R] /Count 1 >> "))
+	objStm := NewStream(Dict{
+		"Type":  Name("ObjStm"),
+		"N":     int64(2),
+		"First": int64(len(header)),
+	}, []byte(header+objects), true)
+
+	objStmOffset := buf.Len()
+	if err := writeIndirect(&buf, Ref{Num: 4}, objStm); err != nil {
+		t.Fatal(err)
+	}
+
+	// Cross-reference entries use the field widths [1 2 1].
+	entries := []byte{
+		0, 0, 0, 0,
+		1, byte(catalog >> 8), byte(catalog), 0,
+		2, 0, 4, 0,
+		2, 0, 4, 1,
+		1, byte(objStmOffset >> 8), byte(objStmOffset), 0,
+	}
+	xref := buf.Len()
+	xrefStm := NewStream(Dict{
+		"Type": Name("XRef"),
+		"W":    Array{int64(1), int64(2), int64(1)},
+		"Size": int64(6),
+		"Root": Ref{Num: 1},
+	}, append(entries, 1, byte(xref>>8), byte(xref), 0), true)
+	if err := writeIndirect(&buf, Ref{Num: 5}, xrefStm); err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintf(&buf, "startxref\n%d\n%%%%EOF\n", xref)
+
+	doc, err := Parse(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pages, err := doc.Pages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(pages, []Ref{{Num: 3}}) {
+		t.Fatalf("expected pages [3 0 R], got %v", pages)
+	}
+	if typ := doc.Dict(pages[0]).Name("Type"); typ != "Page" {
+		t.Errorf("expected compressed page object, got type %q", typ)
+	}
+
+	// Full rewrites do not preserve object and cross-reference streams.
+	var out bytes.Buffer
+	if err := doc.Write(&out); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(out.Bytes(), []byte("/ObjStm")) || bytes.Contains(out.Bytes(), []byte("/XRef")) {
+		t.Error("rewritten document contains object or cross-reference streams")
+	}
+
+	rewritten, err := Parse(out.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pages, err := rewritten.Pages(); err != nil || len(pages) != 1 {
+		t.Errorf("expected 1 page in rewritten document, got %v (%v)", pages, err)
+	}
+}
+
+func TestParseDamaged(t *testing.T) {
+	tests := []struct {
+		name   string
+		damage func(string) string
+	}{
+		{
+			name: "invalid startxref",
+			damage: func(data string) string {
+				i := strings.LastIndex(data, "startxref\n")
+				return data[:i] + "startxref\n9999999\n%%EOF\n"
+			},
+		},
+		{
+			name: "shifted objects",
+			damage: func(data string) string {
+				return strings.Replace(data, "%PDF-1.7\n", "%PDF-1.7\n% padding\n", 1)
+			},
+		},
+		{
+			name: "missing xref",
+			damage: func(data string) string {
+				i := strings.Index(data, "xref\n")
+				return data[:i] + "trailer\n<< /Root 1 0 R >>\n%%EOF\n"
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := Parse([]byte(test.damage(string(minimalPDF(3)))))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pages, err := doc.Pages()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pages) != 3 {
+				t.Errorf("expected 3 pages, got %d", len(pages))
+			}
+		})
+	}
+
+	if _, err := Parse([]byte("not a PDF document")); err == nil {
+		t.Error("expected error for invalid document")
+	}
+}
+
+func TestPagesInherited(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+
+	var offsets []int
+	for _, obj := range []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 5 0 R] /Count 3 /MediaBox [0 0 100 100] /Rotate 90 >>",
+		"<< /Type /Pages /Parent 2 0 R /Kids [4 0 R 6 0 R] /Count 2 /MediaBox [0 0 200 200] >>",
+		"<< /Type /Page /Parent 3 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /Rotate 0 >>",
+		"<< /Type /Page /Parent 3 0 R /MediaBox [0 0 300 300] >>",
+	} {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), obj)
+	}
+	writeXrefTable(&buf, offsets)
+
+	doc, err := Parse(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages, err := doc.Pages()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		ref      Ref
+		mediaBox Array
+		rotate   int64
+	}{
+		{ref: Ref{Num: 4}, mediaBox: Array{int64(0), int64(0), int64(200), int64(200)}, rotate: 90},
+		{ref: Ref{Num: 6}, mediaBox: Array{int64(0), int64(0), int64(300), int64(300)}, rotate: 90},
+		{ref: Ref{Num: 5}, mediaBox: Array{int64(0), int64(0), int64(100), int64(100)}, rotate: 0},
+	}
+	if len(pages) != len(expected) {
+		t.Fatalf("expected %d pages, got %d", len(expected), len(pages))
+	}
+	for i, e := range expected {
+		if pages[i] != e.ref {
+			t.Errorf("page %d: expected %s, got %s", i+1, e.ref, pages[i])
+		}
+
+		page := doc.Dict(pages[i])
+		if mediaBox := doc.Inherited(page, "MediaBox"); !reflect.DeepEqual(mediaBox, e.mediaBox) {
+			t.Errorf("page %d: expected media box %v, got %v", i+1, e.mediaBox, mediaBox)
+		}
+		if rotate, _ := Int(doc.Inherited(page, "Rotate")); rotate != e.rotate {
+			t.Errorf("page %d: expected rotation %d, got %d", i+1, e.rotate, rotate)
+		}
+	}
+}
+
+func TestParseContentOperations(t *testing.T) {
+	data := []byte("q 1 0 0 1 72 720 cm BT /F1 12 Tf [(Hel) -20 (lo)] TJ ET\nBI /W 1 /H 1 ID \x00\xff EI Q")
+
+	ops, err := ParseContent(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Operation{
+		{Operator: "q"},
+		{Operator: "cm", Operands: []Object{int64(1), int64(0), int64(0), int64(1), int64(72), int64(720)}},
+		{Operator: "BT"},
+		{Operator: "Tf", Operands: []Object{Name("F1"), int64(12)}},
+		{Operator: "TJ", Operands: []Object{Array{String("Hel"), int64(-20), String("lo")}}},
+		{Operator: "ET"},
+		{Operator: "BI"},
+		{Operator: "EI", Operands: []Object{Name("W"), int64(1), Name("H"), int64(1)}},
+		{Operator: "Q"},
+	}
+	if !reflect.DeepEqual(ops, expected) {
+		t.Errorf("expected operations\n%v\ngot\n%v", expected, ops)
+	}
+}
+
+// minimalPDF returns a minimal PDF document with the specified number of
+// pages.
+func minimalPDF(pages int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+
+	var offsets []int
+	object := func(format string, args ...interface{}) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n", len(offsets))
+		fmt.Fprintf(&buf, format, args...)
+		buf.WriteString("\nendobj\n")
+	}
+
+	var kids []string
+	for i := 0; i < pages; i++ {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 3+i))
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), pages)
+	for i := 0; i < pages; i++ {
+		object("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] >>")
+	}
+	writeXrefTable(&buf, offsets)
+
+	return buf.Bytes()
+}
+
+func writeXrefTable(buf *bytes.Buffer, offsets []int) {
+	xref := buf.Len()
+	fmt.Fprintf(buf, "xref\n0 %d\n0000000000 65535 f\r\n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(buf, "%010d 00000 n\r\n", offset)
+	}
+	fmt.Fprintf(buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+}
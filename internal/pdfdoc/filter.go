@@ -0,0 +1,208 @@
+package pdfdoc
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrUnsupportedFilter is returned when decoding streams which use filters
+// not supported by the package.
+var ErrUnsupportedFilter = errors.New("unsupported stream filter")
+
+// Decode returns the decoded data of the stream. Only the FlateDecode,
+// ASCIIHexDecode and ASCII85Decode filters are supported.
+func (s *Stream) Decode() ([]byte, error) {
+	var filters, params Array
+	switch v := s.Dict["Filter"].(type) {
+	case Name:
+		filters = Array{v}
+	case Array:
+		filters = v
+	}
+	switch v := s.Dict["DecodeParms"].(type) {
+	case Dict:
+		params = Array{v}
+	case Array:
+		params = v
+	}
+
+	data := s.Data
+	for i, filter := range filters {
+		var param Dict
+		if i < len(params) {
+			param, _ = params[i].(Dict)
+		}
+
+		var err error
+		switch filter {
+		case Name("FlateDecode"), Name("Fl"):
+			data, err = flateDecode(data, param)
+		case Name("ASCIIHexDecode"), Name("AHx"):
+			data, err = asciiHexDecode(data)
+		case Name("ASCII85Decode"), Name("A85"):
+			data, err = ascii85Decode(data)
+		default:
+			err = fmt.Errorf("%w: %v", ErrUnsupportedFilter, filter)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+// NewStream returns a new stream containing the specified data. If compress
+// is true, the data is encoded using the FlateDecode filter.
+func NewStream(dict Dict, data []byte, compress bool) *Stream {
+	if dict == nil {
+		dict = Dict{}
+	}
+	if !compress {
+		return &Stream{Dict: dict, Data: data}
+	}
+
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(data) // nolint:errcheck
+	zw.Close()     // nolint:errcheck
+
+	dict["Filter"] = Name("FlateDecode")
+	return &Stream{Dict: dict, Data: buf.Bytes()}
+}
+
+func flateDecode(data []byte, param Dict) ([]byte, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	// Tolerate truncated streams, as long as some data could be read.
+	out, err := io.ReadAll(zr)
+	if err != nil && len(out) == 0 {
+		return nil, err
+	}
+
+	return unpredict(out, param)
+}
+
+func unpredict(data []byte, param Dict) ([]byte, error) {
+	predictor, _ := Int(param["Predictor"])
+	if predictor < 10 {
+		if predictor == 2 {
+			return nil, fmt.Errorf("%w: TIFF predictor", ErrUnsupportedFilter)
+		}
+		return data, nil
+	}
+
+	columns, colors, bpc := int64(1), int64(1), int64(8)
+	if v, ok := Int(param["Columns"]); ok {
+		columns = v
+	}
+	if v, ok := Int(param["Colors"]); ok {
+		colors = v
+	}
+	if v, ok := Int(param["BitsPerComponent"]); ok {
+		bpc = v
+	}
+
+	bpp := int((colors*bpc + 7) / 8)
+	rowSize := int((columns*colors*bpc + 7) / 8)
+	if rowSize <= 0 {
+		return nil, errors.New("invalid predictor parameters")
+	}
+
+	var (
+		out  = make([]byte, 0, len(data))
+		prev = make([]byte, rowSize)
+	)
+	for len(data) > rowSize {
+		typ, row := data[0], append([]byte(nil), data[1:rowSize+1]...)
+		data = data[rowSize+1:]
+
+		for i := range row {
+			var left, upLeft byte
+			if i >= bpp {
+				left, upLeft = row[i-bpp], prev[i-bpp]
+			}
+			up := prev[i]
+
+			switch typ {
+			case 1:
+				row[i] += left
+			case 2:
+				row[i] += up
+			case 3:
+				row[i] += byte((int(left) + int(up)) / 2)
+			case 4:
+				row[i] += paeth(left, up, upLeft)
+			}
+		}
+
+		out = append(out, row...)
+		prev = row
+	}
+
+	return out, nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+
+	switch {
+	case pa <= pb && pa <= pc:
+		return a
+	case pb <= pc:
+		return b
+	}
+
+	return c
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+
+	return v
+}
+
+func asciiHexDecode(data []byte) ([]byte, error) {
+	var digits []byte
+	for _, c := range data {
+		if c == '>' {
+			break
+		}
+		if !isWhitespace(c) {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+
+	out := make([]byte, len(digits)/2)
+	_, err := hex.Decode(out, digits)
+	return out, err
+}
+
+func ascii85Decode(data []byte) ([]byte, error) {
+	if idx := bytes.Index(data, []byte("~>")); idx >= 0 {
+		data = data[:idx]
+	}
+
+	out := make([]byte, 4*len(data)+4)
+	n, _, err := ascii85.Decode(out, data, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return out[:n], nil
+}
@@ -0,0 +1,231 @@
+/*
+Package pdfdoc implements a minimal PDF object model, used to inspect and
+post-process the documents generated by the wkhtmltox library.
+
+The package supports reading documents which use classic cross-reference
+tables, as well as cross-reference and object streams. Documents are always
+written using classic cross-reference tables, either as full rewrites or as
+incremental updates.
+*/
+package pdfdoc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// Object represents a PDF object. The concrete type of an object is one of:
+// nil, bool, int64, float64, Name, String, HexString, Array, Dict, *Stream,
+// Ref or Raw.
+type Object interface{}
+
+// Name represents a PDF name object, without the leading slash.
+type Name string
+
+// String represents a PDF literal string object.
+type String string
+
+// HexString represents a PDF hexadecimal string object.
+type HexString string
+
+// Array represents a PDF array object.
+type Array []Object
+
+// Dict represents a PDF dictionary object.
+type Dict map[Name]Object
+
+// Raw represents preformatted content which is written to the output as is.
+// It is useful for creating placeholders which are filled in after the
+// document is written.
+type Raw []byte
+
+// Ref represents a reference to an indirect object.
+type Ref struct {
+	Num int
+	Gen int
+}
+
+// String returns the textual representation of the reference.
+func (r Ref) String() string {
+	return fmt.Sprintf("%d %d R", r.Num, r.Gen)
+}
+
+// Stream represents a PDF stream object. The data of the stream is stored
+// in its encoded form.
+type Stream struct {
+	Dict Dict
+	Data []byte
+}
+
+// Clone returns a shallow copy of the dictionary.
+func (d Dict) Clone() Dict {
+	c := make(Dict, len(d))
+	for k, v := range d {
+		c[k] = v
+	}
+
+	return c
+}
+
+// Name returns the value of the specified key, if it is a name.
+func (d Dict) Name(key Name) Name {
+	n, _ := d[key].(Name)
+	return n
+}
+
+// Text returns the string value of the specified key, if it is a string.
+func (d Dict) Text(key Name) (string, bool) {
+	return Text(d[key])
+}
+
+// Text returns the value of the object, if it is a literal or a hexadecimal
+// string.
+func Text(o Object) (string, bool) {
+	switch v := o.(type) {
+	case String:
+		return string(v), true
+	case HexString:
+		return string(v), true
+	}
+
+	return "", false
+}
+
+// Int returns the value of the object as an integer, if it is a number.
+func Int(o Object) (int64, bool) {
+	switch v := o.(type) {
+	case int64:
+		return v, true
+	case float64:
+		return int64(v), true
+	}
+
+	return 0, false
+}
+
+// Float returns the value of the object as a float, if it is a number.
+func Float(o Object) (float64, bool) {
+	switch v := o.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+
+	return 0, false
+}
+
+// WriteObject writes the textual representation of the object to w.
+func WriteObject(w io.Writer, o Object) error {
+	var buf bytes.Buffer
+	appendObject(&buf, o)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func appendObject(buf *bytes.Buffer, o Object) {
+	switch v := o.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case int:
+		buf.WriteString(strconv.Itoa(v))
+	case int64:
+		buf.WriteString(strconv.FormatInt(v, 10))
+	case float64:
+		buf.WriteString(formatReal(v))
+	case Name:
+		appendName(buf, v)
+	case String:
+		appendString(buf, v)
+	case HexString:
+		buf.WriteByte('<')
+		fmt.Fprintf(buf, "%X", []byte(v))
+		buf.WriteByte('>')
+	case Array:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(' ')
+			}
+			appendObject(buf, item)
+		}
+		buf.WriteByte(']')
+	case Dict:
+		appendDict(buf, v)
+	case *Stream:
+		dict := v.Dict.Clone()
+		dict["Length"] = int64(len(v.Data))
+
+		appendDict(buf, dict)
+		buf.WriteString("\nstream\n")
+		buf.Write(v.Data)
+		buf.WriteString("\nendstream")
+	case Ref:
+		buf.WriteString(v.String())
+	case Raw:
+		buf.Write(v)
+	default:
+		buf.WriteString("null")
+	}
+}
+
+func appendDict(buf *bytes.Buffer, d Dict) {
+	keys := make([]string, 0, len(d))
+	for k := range d {
+		keys = append(keys, string(k))
+	}
+	sort.Strings(keys)
+
+	buf.WriteString("<<")
+	for _, k := range keys {
+		appendName(buf, Name(k))
+		buf.WriteByte(' ')
+		appendObject(buf, d[Name(k)])
+	}
+	buf.WriteString(">>")
+}
+
+func appendName(buf *bytes.Buffer, n Name) {
+	buf.WriteByte('/')
+	for i := 0; i < len(n); i++ {
+		c := n[i]
+		if c < '!' || c > '~' || c == '#' || isDelimiter(c) {
+			fmt.Fprintf(buf, "#%02X", c)
+			continue
+		}
+		buf.WriteByte(c)
+	}
+}
+
+func appendString(buf *bytes.Buffer, s String) {
+	buf.WriteByte('(')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '(', ')', '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\n':
+			buf.WriteString(`\n`)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	buf.WriteByte(')')
+}
+
+func formatReal(f float64) string {
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if s == "-0" {
+		return "0"
+	}
+
+	return s
+}
@@ -0,0 +1,230 @@
+package pdfdoc
+
+import (
+	"bytes"
+	"encoding/ascii85"
+	"encoding/hex"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestObjectRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		object  Object
+		encoded string
+	}{
+		{name: "null", object: nil, encoded: "null"},
+		{name: "bool", object: true, encoded: "true"},
+		{name: "int", object: int64(-42), encoded: "-42"},
+		{name: "real", object: 3.25, encoded: "3.25"},
+		{name: "negative zero", object: -0.0, encoded: "0"},
+		{name: "name", object: Name("Type"), encoded: "/Type"},
+		{name: "escaped name", object: Name("A B#(c)"), encoded: "/A#20B#23#28c#29"},
+		{name: "string", object: String("a (b) c"), encoded: `(a \(b\) c)`},
+		{name: "escaped string", object: String("\\\r\n\x00"), encoded: "(\\\\\\r\\n\x00)"},
+		{name: "hex string", object: HexString("\x00\xab\xff"), encoded: "<00ABFF>"},
+		{name: "reference", object: Ref{Num: 12, Gen: 1}, encoded: "12 1 R"},
+		{
+			name:    "array",
+			object:  Array{int64(1), 2.5, Name("N"), Ref{Num: 3}, Array{}, String("s")},
+			encoded: "[1 2.5 /N 3 0 R [] (s)]",
+		},
+		{
+			name: "dictionary",
+			object: Dict{
+				"Type":  Name("Page"),
+				"Kids":  Array{Ref{Num: 4}, Ref{Num: 5}},
+				"Count": int64(2),
+				"Sub":   Dict{"A": true},
+			},
+			encoded: "<</Count 2/Kids [4 0 R 5 0 R]/Sub <</A true>>/Type /Page>>",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteObject(&buf, test.object); err != nil {
+				t.Fatal(err)
+			}
+			if encoded := buf.String(); encoded != test.encoded {
+				t.Errorf("expected encoding %q, got %q", test.encoded, encoded)
+			}
+
+			obj, err := newParser(buf.Bytes(), 0).object()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := test.object
+			if f, ok := expected.(float64); ok && f == 0 {
+				expected = int64(0)
+			}
+			if !reflect.DeepEqual(obj, expected) {
+				t.Errorf("expected %#v, got %#v", expected, obj)
+			}
+		})
+	}
+}
+
+func TestParseObject(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Object
+	}{
+		{input: "+17", expected: int64(17)},
+		{input: ".5", expected: 0.5},
+		{input: "-.002", expected: -0.002},
+		{input: "/Lime#20Green", expected: Name("Lime Green")},
+		{input: "(nested (paren) \\101\\)\\\nx)", expected: String("nested (paren) A)x")},
+		{input: "<4E6F7>", expected: HexString("Nop")},
+		{input: "<< /A 1 /B null >>", expected: Dict{"A": int64(1)}},
+		{input: "[1 0 R 2]", expected: Array{Ref{Num: 1}, int64(2)}},
+		{input: "% comment\n[1 %other\n 2]", expected: Array{int64(1), int64(2)}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			obj, err := newParser([]byte(test.input), 0).object()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(obj, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, obj)
+			}
+		})
+	}
+}
+
+func TestStreamDecode(t *testing.T) {
+	data := []byte("BT /F1 12 Tf (Hello) Tj ET")
+
+	tests := []struct {
+		name   string
+		stream *Stream
+	}{
+		{name: "uncompressed", stream: NewStream(nil, data, false)},
+		{name: "flate", stream: NewStream(nil, data, true)},
+		{
+			name: "ascii hex",
+			stream: &Stream{
+				Dict: Dict{"Filter": Name("ASCIIHexDecode")},
+				Data: []byte(hex.EncodeToString(data) + ">"),
+			},
+		},
+		{
+			name: "ascii85 and flate",
+			stream: func() *Stream {
+				flate := NewStream(nil, data, true)
+				var buf bytes.Buffer
+				w := ascii85.NewEncoder(&buf)
+				w.Write(flate.Data) // nolint:errcheck
+				w.Close()           // nolint:errcheck
+				buf.WriteString("~>")
+
+				return &Stream{
+					Dict: Dict{"Filter": Array{Name("ASCII85Decode"), Name("FlateDecode")}},
+					Data: buf.Bytes(),
+				}
+			}(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			decoded, err := test.stream.Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decoded, data) {
+				t.Errorf("expected %q, got %q", data, decoded)
+			}
+		})
+	}
+
+	unsupported := &Stream{Dict: Dict{"Filter": Name("DCTDecode")}}
+	if _, err := unsupported.Decode(); err == nil {
+		t.Error("expected error for unsupported filter")
+	}
+}
+
+func TestDateRoundTrip(t *testing.T) {
+	tests := []struct {
+		time    time.Time
+		encoded string
+	}{
+		{
+			time:    time.Date(2024, 2, 29, 13, 4, 5, 0, time.UTC),
+			encoded: "D:20240229130405Z",
+		},
+		{
+			time:    time.Date(2006, 12, 31, 15, 4, 5, 0, time.FixedZone("", 3600+30*60)),
+			encoded: "D:20061231150405+01'30'",
+		},
+		{
+			time:    time.Date(1999, 1, 1, 0, 0, 0, 0, time.FixedZone("", -5*3600)),
+			encoded: "D:19990101000000-05'00'",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.encoded, func(t *testing.T) {
+			encoded := FormatDate(test.time)
+			if encoded != test.encoded {
+				t.Errorf("expected %q, got %q", test.encoded, encoded)
+			}
+
+			parsed, err := ParseDate(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !parsed.Equal(test.time) {
+				t.Errorf("expected %v, got %v", test.time, parsed)
+			}
+		})
+	}
+
+	parsed, err := ParseDate("D:2023")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC); !parsed.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, parsed)
+	}
+	if _, err := ParseDate("20230101"); err == nil {
+		t.Error("expected error for invalid date")
+	}
+}
+
+func TestTextRoundTrip(t *testing.T) {
+	tests := []struct {
+		text    string
+		encoded String
+	}{
+		{text: "Plain ASCII", encoded: "Plain ASCII"},
+		{text: "Café", encoded: "\xfe\xff\x00C\x00a\x00f\x00\xe9"},
+		{text: "日本", encoded: "\xfe\xff\x65\xe5\x67\x2c"},
+		{text: "😀", encoded: "\xfe\xff\xd8\x3d\xde\x00"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			encoded := EncodeText(test.text)
+			if encoded != test.encoded {
+				t.Errorf("expected %q, got %q", test.encoded, encoded)
+			}
+			if decoded := DecodeText(string(encoded)); decoded != test.text {
+				t.Errorf("expected %q, got %q", test.text, decoded)
+			}
+		})
+	}
+
+	if decoded := DecodeText("\xef\xbb\xbfCaf\xc3\xa9"); decoded != "Café" {
+		t.Errorf("expected UTF-8 text to be decoded, got %q", decoded)
+	}
+	if decoded := DecodeText("Caf\xe9"); decoded != "Café" {
+		t.Errorf("expected Latin-1 text to be decoded, got %q", decoded)
+	}
+}
@@ -0,0 +1,450 @@
+package pdfdoc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var errSyntax = errors.New("invalid PDF syntax")
+
+// keyword represents a bare PDF keyword (e.g. obj, endobj, R, stream).
+type keyword string
+
+// delimiter represents one of the PDF delimiter tokens ([, ], <<, >>).
+type delimiter string
+
+type parser struct {
+	data []byte
+	pos  int
+
+	// resolveLength is used to resolve indirect stream lengths.
+	resolveLength func(ref Ref) (int64, bool)
+}
+
+func newParser(data []byte, pos int) *parser {
+	return &parser{data: data, pos: pos}
+}
+
+func isWhitespace(c byte) bool {
+	switch c {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+
+	return false
+}
+
+func isDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+
+	return false
+}
+
+func isRegular(c byte) bool {
+	return !isWhitespace(c) && !isDelimiter(c)
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if isWhitespace(c) {
+			p.pos++
+			continue
+		}
+		if c == '%' {
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' && p.data[p.pos] != '\r' {
+				p.pos++
+			}
+			continue
+		}
+		break
+	}
+}
+
+// token returns the next token in the input. The returned value is either
+// a keyword, a delimiter or a direct object value (number, name, string).
+func (p *parser) token() (interface{}, error) {
+	p.skipSpace()
+	if p.pos >= len(p.data) {
+		return nil, fmt.Errorf("%w: unexpected end of data", errSyntax)
+	}
+
+	switch c := p.data[p.pos]; c {
+	case '[', ']', '{', '}':
+		p.pos++
+		return delimiter(c), nil
+	case '<':
+		if p.pos+1 < len(p.data) && p.data[p.pos+1] == '<' {
+			p.pos += 2
+			return delimiter("<<"), nil
+		}
+		return p.hexString()
+	case '>':
+		if p.pos+1 < len(p.data) && p.data[p.pos+1] == '>' {
+			p.pos += 2
+			return delimiter(">>"), nil
+		}
+		return nil, fmt.Errorf("%w: unexpected '>' at offset %d", errSyntax, p.pos)
+	case '(':
+		return p.literalString()
+	case '/':
+		return p.name(), nil
+	case ')':
+		return nil, fmt.Errorf("%w: unexpected ')' at offset %d", errSyntax, p.pos)
+	}
+
+	start := p.pos
+	for p.pos < len(p.data) && isRegular(p.data[p.pos]) {
+		p.pos++
+	}
+	word := string(p.data[start:p.pos])
+
+	if c := word[0]; c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9') {
+		if i, err := strconv.ParseInt(word, 10, 64); err == nil {
+			return i, nil
+		}
+		if f, err := strconv.ParseFloat(word, 64); err == nil {
+			return f, nil
+		}
+	}
+
+	return keyword(word), nil
+}
+
+func (p *parser) name() Name {
+	p.pos++
+
+	var buf bytes.Buffer
+	for p.pos < len(p.data) && isRegular(p.data[p.pos]) {
+		c := p.data[p.pos]
+		if c == '#' && p.pos+2 < len(p.data) {
+			if v, err := strconv.ParseUint(string(p.data[p.pos+1:p.pos+3]), 16, 8); err == nil {
+				buf.WriteByte(byte(v))
+				p.pos += 3
+				continue
+			}
+		}
+		buf.WriteByte(c)
+		p.pos++
+	}
+
+	return Name(buf.String())
+}
+
+func (p *parser) hexString() (HexString, error) {
+	p.pos++
+
+	var digits []byte
+	for p.pos < len(p.data) && p.data[p.pos] != '>' {
+		if c := p.data[p.pos]; !isWhitespace(c) {
+			digits = append(digits, c)
+		}
+		p.pos++
+	}
+	if p.pos >= len(p.data) {
+		return "", fmt.Errorf("%w: unterminated hex string", errSyntax)
+	}
+	p.pos++
+
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+
+	out := make([]byte, len(digits)/2)
+	for i := range out {
+		v, err := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+		if err != nil {
+			return "", fmt.Errorf("%w: invalid hex string", errSyntax)
+		}
+		out[i] = byte(v)
+	}
+
+	return HexString(out), nil
+}
+
+func (p *parser) literalString() (String, error) {
+	p.pos++
+
+	var buf bytes.Buffer
+	for depth := 1; p.pos < len(p.data); {
+		c := p.data[p.pos]
+		p.pos++
+
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return String(buf.String()), nil
+			}
+		case '\r':
+			// End of line markers are normalized to a single line feed.
+			if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+				p.pos++
+			}
+			c = '\n'
+		case '\\':
+			if p.pos >= len(p.data) {
+				continue
+			}
+
+			e := p.data[p.pos]
+			p.pos++
+
+			switch e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+					p.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if e < '0' || e > '7' {
+					c = e
+					break
+				}
+
+				v := int(e - '0')
+				for i := 0; i < 2 && p.pos < len(p.data); i++ {
+					d := p.data[p.pos]
+					if d < '0' || d > '7' {
+						break
+					}
+					v = v*8 + int(d-'0')
+					p.pos++
+				}
+				c = byte(v)
+			}
+		}
+
+		buf.WriteByte(c)
+	}
+
+	return "", fmt.Errorf("%w: unterminated literal string", errSyntax)
+}
+
+// object parses the next direct object in the input, including references.
+func (p *parser) object() (Object, error) {
+	tok, err := p.token()
+	if err != nil {
+		return nil, err
+	}
+
+	return p.objectFrom(tok)
+}
+
+func (p *parser) objectFrom(tok interface{}) (Object, error) {
+	switch v := tok.(type) {
+	case int64:
+		// Check if the integer is the start of an indirect reference.
+		pos := p.pos
+		if gen, err := p.token(); err == nil {
+			if g, ok := gen.(int64); ok {
+				if kw, err := p.token(); err == nil && kw == keyword("R") {
+					return Ref{Num: int(v), Gen: int(g)}, nil
+				}
+			}
+		}
+		p.pos = pos
+
+		return v, nil
+	case float64, Name, String, HexString:
+		return v, nil
+	case keyword:
+		switch v {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+	case delimiter:
+		switch v {
+		case "[":
+			return p.array()
+		case "<<":
+			return p.dict()
+		}
+	}
+
+	return nil, fmt.Errorf("%w: unexpected token %v at offset %d", errSyntax, tok, p.pos)
+}
+
+func (p *parser) array() (Array, error) {
+	arr := Array{}
+	for {
+		tok, err := p.token()
+		if err != nil {
+			return nil, err
+		}
+		if tok == delimiter("]") {
+			return arr, nil
+		}
+
+		obj, err := p.objectFrom(tok)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, obj)
+	}
+}
+
+func (p *parser) dict() (Dict, error) {
+	dict := Dict{}
+	for {
+		tok, err := p.token()
+		if err != nil {
+			return nil, err
+		}
+		if tok == delimiter(">>") {
+			return dict, nil
+		}
+
+		key, ok := tok.(Name)
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid dictionary key at offset %d", errSyntax, p.pos)
+		}
+
+		val, err := p.object()
+		if err != nil {
+			return nil, err
+		}
+		if val != nil {
+			dict[key] = val
+		}
+	}
+}
+
+// indirect parses an indirect object definition (`num gen obj ... endobj`)
+// starting at the current position.
+func (p *parser) indirect() (Ref, Object, error) {
+	var ref Ref
+
+	num, err := p.token()
+	if err != nil {
+		return ref, nil, err
+	}
+	gen, err := p.token()
+	if err != nil {
+		return ref, nil, err
+	}
+	n, ok1 := num.(int64)
+	g, ok2 := gen.(int64)
+	if !ok1 || !ok2 {
+		return ref, nil, fmt.Errorf("%w: invalid object header at offset %d", errSyntax, p.pos)
+	}
+	if kw, err := p.token(); err != nil || kw != keyword("obj") {
+		return ref, nil, fmt.Errorf("%w: missing obj keyword at offset %d", errSyntax, p.pos)
+	}
+	ref = Ref{Num: int(n), Gen: int(g)}
+
+	tok, err := p.token()
+	if err != nil {
+		return ref, nil, err
+	}
+	if tok == keyword("endobj") {
+		return ref, nil, nil
+	}
+
+	obj, err := p.objectFrom(tok)
+	if err != nil {
+		return ref, nil, err
+	}
+
+	// Check for stream content.
+	pos := p.pos
+	if tok, err := p.token(); err == nil && tok == keyword("stream") {
+		dict, ok := obj.(Dict)
+		if !ok {
+			return ref, nil, fmt.Errorf("%w: stream without dictionary in object %d", errSyntax, n)
+		}
+
+		data, err := p.streamData(dict)
+		if err != nil {
+			return ref, nil, fmt.Errorf("object %d: %w", n, err)
+		}
+
+		return ref, &Stream{Dict: dict, Data: data}, nil
+	}
+	p.pos = pos
+
+	return ref, obj, nil
+}
+
+func (p *parser) streamData(dict Dict) ([]byte, error) {
+	// The stream keyword is followed by either CRLF or LF.
+	if p.pos < len(p.data) && p.data[p.pos] == '\r' {
+		p.pos++
+	}
+	if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+		p.pos++
+	}
+	start := p.pos
+
+	length := int64(-1)
+	switch v := dict["Length"].(type) {
+	case int64:
+		length = v
+	case Ref:
+		if p.resolveLength != nil {
+			if l, ok := p.resolveLength(v); ok {
+				length = l
+			}
+		}
+	}
+
+	// Use the declared length only if it is followed by the endstream keyword.
+	if length >= 0 && start+int(length) <= len(p.data) {
+		end := start + int(length)
+		rest := bytes.TrimLeft(p.data[end:min(end+32, len(p.data))], "\x00\t\n\f\r ")
+		if bytes.HasPrefix(rest, []byte("endstream")) {
+			p.pos = end
+			p.skipSpace()
+			p.pos += len("endstream")
+			return p.data[start:end], nil
+		}
+	}
+
+	// Fall back to searching for the endstream keyword.
+	idx := bytes.Index(p.data[start:], []byte("endstream"))
+	if idx < 0 {
+		return nil, fmt.Errorf("%w: missing endstream keyword", errSyntax)
+	}
+	end := start + idx
+	p.pos = end + len("endstream")
+
+	// Remove the end of line marker preceding the endstream keyword.
+	if end > start && p.data[end-1] == '\n' {
+		end--
+	}
+	if end > start && p.data[end-1] == '\r' {
+		end--
+	}
+
+	return p.data[start:end], nil
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
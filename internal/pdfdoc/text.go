@@ -0,0 +1,104 @@
+package pdfdoc
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var dateRegexp = regexp.MustCompile(`^D:(\d{4})(\d{2})?(\d{2})?(\d{2})?(\d{2})?(\d{2})?([Zz+-])?(\d{2})?'?(\d{2})?'?`)
+
+// FormatDate returns the PDF representation of the specified time.
+// E.g.: "D:20061231150405+01'00'".
+func FormatDate(t time.Time) string {
+	_, offset := t.Zone()
+	if offset == 0 {
+		return t.Format("D:20060102150405Z")
+	}
+
+	sign := '+'
+	if offset < 0 {
+		sign, offset = '-', -offset
+	}
+
+	return fmt.Sprintf("%s%c%02d'%02d'", t.Format("D:20060102150405"), sign, offset/3600, offset%3600/60)
+}
+
+// ParseDate parses the specified PDF date string.
+func ParseDate(s string) (time.Time, error) {
+	m := dateRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, fmt.Errorf("invalid PDF date: %q", s)
+	}
+
+	num := func(s string, def int) int {
+		if v, err := strconv.Atoi(s); err == nil {
+			return v
+		}
+		return def
+	}
+
+	loc := time.UTC
+	if m[7] == "+" || m[7] == "-" {
+		offset := num(m[8], 0)*3600 + num(m[9], 0)*60
+		if m[7] == "-" {
+			offset = -offset
+		}
+		loc = time.FixedZone("", offset)
+	}
+
+	return time.Date(num(m[1], 0), time.Month(num(m[2], 1)), num(m[3], 1),
+		num(m[4], 0), num(m[5], 0), num(m[6], 0), 0, loc), nil
+}
+
+// DecodeText decodes the specified PDF text string. Strings starting with
+// a UTF-16BE or UTF-8 byte order mark are decoded accordingly. Otherwise,
+// the string is considered to use the PDFDocEncoding, which is approximated
+// using Latin-1.
+func DecodeText(s string) string {
+	switch {
+	case len(s) >= 2 && s[0] == 0xfe && s[1] == 0xff:
+		units := make([]uint16, 0, len(s)/2-1)
+		for i := 2; i+1 < len(s); i += 2 {
+			units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+		}
+		return string(utf16.Decode(units))
+	case len(s) >= 3 && s[0] == 0xef && s[1] == 0xbb && s[2] == 0xbf:
+		return s[3:]
+	}
+
+	runes := make([]rune, len(s))
+	for i := 0; i < len(s); i++ {
+		runes[i] = rune(s[i])
+	}
+
+	return string(runes)
+}
+
+// EncodeText encodes the specified string as a PDF text string. ASCII
+// strings are returned unchanged, while other strings are encoded as
+// UTF-16BE, prefixed by a byte order mark.
+func EncodeText(s string) String {
+	ascii := true
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return String(s)
+	}
+
+	units := utf16.Encode([]rune(s))
+	buf := make([]byte, 2, 2+2*len(units))
+	buf[0], buf[1] = 0xfe, 0xff
+	for _, u := range units {
+		buf = append(buf, byte(u>>8), byte(u))
+	}
+
+	return String(buf)
+}
@@ -0,0 +1,205 @@
+package pdfdoc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// trailerSkipKeys contains the trailer keys which are not carried over when
+// writing a new cross-reference section.
+var trailerSkipKeys = []Name{
+	"Prev", "XRefStm", "Type", "W", "Index", "Filter", "DecodeParms", "Length", "DL",
+}
+
+type countingWriter struct {
+	w *bufio.Writer
+	n int
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += n
+	return n, err
+}
+
+// Write writes the complete document to w. The objects are written in
+// ascending object number order, followed by a classic cross-reference
+// table and the trailer dictionary. Object and cross-reference streams
+// present in the original document are not preserved.
+func (d *Document) Write(w io.Writer) error {
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	if _, err := fmt.Fprintf(cw, "%%PDF-%s\n%%\xe2\xe3\xcf\xd3\n", d.Version); err != nil {
+		return err
+	}
+
+	offsets := map[int]int{}
+	for _, ref := range d.Refs() {
+		obj, err := d.Get(ref)
+		if err != nil {
+			return err
+		}
+		if stream, ok := obj.(*Stream); ok {
+			if typ := stream.Dict.Name("Type"); typ == "ObjStm" || typ == "XRef" {
+				continue
+			}
+		}
+
+		offsets[ref.Num] = cw.n
+		if err := writeIndirect(cw, ref, obj); err != nil {
+			return err
+		}
+	}
+
+	if err := d.writeXref(cw, offsets, 0, true); err != nil {
+		return err
+	}
+
+	return cw.w.Flush()
+}
+
+// WriteUpdate writes the original document data to w, followed by an
+// incremental update section containing the added and modified objects.
+func (d *Document) WriteUpdate(w io.Writer) error {
+	if d.startxref <= 0 {
+		return errors.New("cannot update document with damaged cross-reference information")
+	}
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	if _, err := cw.Write(d.data); err != nil {
+		return err
+	}
+	if n := len(d.data); n > 0 && d.data[n-1] != '\n' && d.data[n-1] != '\r' {
+		if _, err := cw.Write([]byte{'\n'}); err != nil {
+			return err
+		}
+	}
+
+	nums := make([]int, 0, len(d.modified))
+	for num := range d.modified {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+
+	offsets := map[int]int{}
+	for _, num := range nums {
+		entry := d.xref[num]
+		if entry.free {
+			continue
+		}
+
+		offsets[num] = cw.n
+		if err := writeIndirect(cw, Ref{Num: num, Gen: entry.gen}, d.objects[num]); err != nil {
+			return err
+		}
+	}
+
+	if err := d.writeXref(cw, offsets, d.startxref, false); err != nil {
+		return err
+	}
+
+	return cw.w.Flush()
+}
+
+func writeIndirect(w io.Writer, ref Ref, obj Object) error {
+	if _, err := fmt.Fprintf(w, "%d %d obj\n", ref.Num, ref.Gen); err != nil {
+		return err
+	}
+	if err := WriteObject(w, obj); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\nendobj\n")
+	return err
+}
+
+// writeXref writes a cross-reference section containing the specified
+// object offsets, followed by the trailer. If full is true, the section
+// contains entries for all the objects of the document, including the
+// free ones. Otherwise, the section references the previous section.
+func (d *Document) writeXref(cw *countingWriter, offsets map[int]int, prev int, full bool) error {
+	start := cw.n
+
+	entry := func(num int) string {
+		if offset, ok := offsets[num]; ok {
+			return fmt.Sprintf("%010d %05d n\r\n", offset, d.xref[num].gen)
+		}
+		return ""
+	}
+
+	var sections [][2]int
+	if full {
+		sections = append(sections, [2]int{0, d.size})
+	} else {
+		nums := make([]int, 0, len(d.modified))
+		for num := range d.modified {
+			nums = append(nums, num)
+		}
+		sort.Ints(nums)
+
+		for _, num := range nums {
+			if n := len(sections); n > 0 && sections[n-1][0]+sections[n-1][1] == num {
+				sections[n-1][1]++
+				continue
+			}
+			sections = append(sections, [2]int{num, 1})
+		}
+	}
+
+	// Free entries are linked together, starting from object 0.
+	var free []int
+	for num := 0; num < d.size; num++ {
+		if _, ok := offsets[num]; !ok && (full || d.xref[num].free) {
+			free = append(free, num)
+		}
+	}
+	nextFree := map[int]int{}
+	for i, num := range free {
+		if i+1 < len(free) {
+			nextFree[num] = free[i+1]
+		}
+	}
+
+	if _, err := io.WriteString(cw, "xref\n"); err != nil {
+		return err
+	}
+	for _, s := range sections {
+		if _, err := fmt.Fprintf(cw, "%d %d\n", s[0], s[1]); err != nil {
+			return err
+		}
+		for num := s[0]; num < s[0]+s[1]; num++ {
+			line := entry(num)
+			if line == "" {
+				gen := d.xref[num].gen
+				if num == 0 {
+					gen = 65535
+				}
+				line = fmt.Sprintf("%010d %05d f\r\n", nextFree[num], gen)
+			}
+			if _, err := io.WriteString(cw, line); err != nil {
+				return err
+			}
+		}
+	}
+
+	trailer := d.Trailer.Clone()
+	for _, key := range trailerSkipKeys {
+		delete(trailer, key)
+	}
+	trailer["Size"] = int64(d.size)
+	if prev > 0 {
+		trailer["Prev"] = int64(prev)
+	}
+
+	if _, err := io.WriteString(cw, "trailer\n"); err != nil {
+		return err
+	}
+	if err := WriteObject(cw, trailer); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(cw, "\nstartxref\n%d\n%%%%EOF\n", start)
+	return err
+}
@@ -0,0 +1,181 @@
+package pdf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ObjectType defines the types of objects which can be declared in jobs.
+type ObjectType string
+
+// Object type values.
+const (
+	ObjectPage  ObjectType = "page"
+	ObjectCover ObjectType = "cover"
+	ObjectTOC   ObjectType = "toc"
+)
+
+// ObjectSpec contains the declarative specification of an object to be
+// converted as part of a job. The source of page and cover objects is
+// exactly one of HTML, File and URL. The source of table of contents
+// objects is an optional XSL style sheet, specified as a file or a URL.
+// When decoding specifications from JSON or YAML, the options which are
+// not specified retain the defaults returned by NewObjectOpts.
+type ObjectSpec struct {
+	// The type of the object. If not specified, ObjectPage is used.
+	// E.g.: ObjectCover.
+	Type ObjectType `json:"type" yaml:"type"`
+
+	// Inline HTML content of the object.
+	// E.g.: "<h1>Invoice</h1>".
+	HTML string `json:"html" yaml:"html"`
+
+	// Path of the file containing the object content.
+	// E.g.: "templates/cover.html".
+	File string `json:"file" yaml:"file"`
+
+	// URL of the object content.
+	// E.g.: "https://example.com".
+	URL string `json:"url" yaml:"url"`
+
+	// The object options. The location specified by the options is replaced
+	// by the source of the object. If not specified, the defaults returned by
+	// NewObjectOpts are used.
+	Options *ObjectOpts `json:"options" yaml:"options"`
+}
+
+// UnmarshalJSON decodes the object specification from the provided JSON
+// data. The options which are not specified retain their default values.
+func (s *ObjectSpec) UnmarshalJSON(data []byte) error {
+	type objectSpec ObjectSpec
+	spec := objectSpec{Options: NewObjectOpts()}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+
+	*s = ObjectSpec(spec)
+	return nil
+}
+
+// UnmarshalYAML decodes the object specification from the provided YAML
+// node. The options which are not specified retain their default values.
+func (s *ObjectSpec) UnmarshalYAML(value *yaml.Node) error {
+	type objectSpec ObjectSpec
+	spec := objectSpec{Options: NewObjectOpts()}
+	if err := value.Decode(&spec); err != nil {
+		return err
+	}
+
+	*s = ObjectSpec(spec)
+	return nil
+}
+
+// Job contains the declarative specification of a conversion: the converter
+// options and the objects to be converted, in order. Jobs can be encoded as
+// JSON or YAML. When decoding jobs, the converter options which are not
+// specified retain the defaults returned by NewConverterOpts. Unknown
+// fields are ignored.
+type Job struct {
+	// The converter options.
+	Converter ConverterOpts `json:"converter" yaml:"converter"`
+
+	// The objects to be converted, in the order in which they appear in the
+	// output document.
+	Objects []ObjectSpec `json:"objects" yaml:"objects"`
+}
+
+// NewJob returns a new job with the specified objects, which uses the
+// default converter options returned by NewConverterOpts.
+func NewJob(objects ...ObjectSpec) *Job {
+	return &Job{
+		Converter: *NewConverterOpts(),
+		Objects:   objects,
+	}
+}
+
+// UnmarshalJSON decodes the job from the provided JSON data. The converter
+// options which are not specified retain their default values.
+func (j *Job) UnmarshalJSON(data []byte) error {
+	type job Job
+	decoded := job{Converter: *NewConverterOpts()}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	*j = Job(decoded)
+	return nil
+}
+
+// UnmarshalYAML decodes the job from the provided YAML node. The converter
+// options which are not specified retain their default values.
+func (j *Job) UnmarshalYAML(value *yaml.Node) error {
+	type job Job
+	decoded := job{Converter: *NewConverterOpts()}
+	if err := value.Decode(&decoded); err != nil {
+		return err
+	}
+
+	*j = Job(decoded)
+	return nil
+}
+
+// object creates the object described by the specification.
+func (s *ObjectSpec) object() (*Object, error) {
+	opts := NewObjectOpts()
+	if s.Options != nil {
+		copied := *s.Options
+		opts = &copied
+	}
+
+	var sources int
+	for _, source := range []string{s.HTML, s.File, s.URL} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return nil, errors.New("must specify a single object source")
+	}
+
+	switch s.Type {
+	case ObjectPage, ObjectCover, "":
+		if sources == 0 {
+			return nil, errors.New("must specify the HTML content, file or URL of the object")
+		}
+
+		// Cover pages are excluded from outlines and page counts and have no
+		// headers or footers.
+		if s.Type == ObjectCover {
+			opts.IncludeInOutline, opts.CountPages = false, false
+			opts.Header, opts.Footer = Header{}, Header{}
+		}
+
+		if s.HTML != "" {
+			location, err := createTempHTML(strings.NewReader(s.HTML))
+			if err != nil {
+				return nil, err
+			}
+
+			object, err := newObject(location, true, opts)
+			if err != nil {
+				os.Remove(location) // nolint:errcheck
+			}
+			return object, err
+		}
+		return newObject(s.File+s.URL, false, opts)
+	case ObjectTOC:
+		if s.HTML != "" {
+			return nil, errors.New("table of contents style sheet must be a file or URL")
+		}
+
+		opts.Location = s.File + s.URL
+		return newTOCObject(opts)
+	}
+
+	return nil, fmt.Errorf("invalid object type: %s", s.Type)
+}
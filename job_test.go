@@ -0,0 +1,211 @@
+package pdf
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestDecodeJob(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		decode func(data []byte, v interface{}) error
+	}{
+		{
+			name: "json",
+			data: `{
+				"converter": {"title": "Report", "unknownOption": true},
+				"objects": [
+					{"type": "cover", "html": "<h1>Report</h1>"},
+					{"url": "https://example.com", "options": {"zoom": 0.5}, "unknownField": 1},
+					{"type": "toc", "file": "toc.xsl"}
+				],
+				"unknownField": "ignored"
+			}`,
+			decode: json.Unmarshal,
+		},
+		{
+			name: "yaml",
+			data: `
+converter:
+  title: Report
+  unknownOption: true
+objects:
+  - type: cover
+    html: <h1>Report</h1>
+  - url: https://example.com
+    options:
+      zoom: 0.5
+    unknownField: 1
+  - type: toc
+    file: toc.xsl
+unknownField: ignored
+`,
+			decode: yaml.Unmarshal,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var job Job
+			if err := test.decode([]byte(test.data), &job); err != nil {
+				t.Fatal(err)
+			}
+
+			// The converter options which are not specified retain their
+			// default values.
+			expectedConverter := NewConverterOpts()
+			expectedConverter.Title = "Report"
+			if !reflect.DeepEqual(&job.Converter, expectedConverter) {
+				t.Errorf("expected converter options %+v, got %+v", expectedConverter, job.Converter)
+			}
+
+			// The object options which are not specified retain their
+			// default values.
+			defaults, zoomed := NewObjectOpts(), NewObjectOpts()
+			zoomed.Zoom = Float64(0.5)
+
+			expectedObjects := []ObjectSpec{
+				{Type: ObjectCover, HTML: "<h1>Report</h1>", Options: defaults},
+				{URL: "https://example.com", Options: zoomed},
+				{Type: ObjectTOC, File: "toc.xsl", Options: defaults},
+			}
+			if !reflect.DeepEqual(job.Objects, expectedObjects) {
+				t.Errorf("expected objects %+v, got %+v", expectedObjects, job.Objects)
+			}
+		})
+	}
+}
+
+func TestDecodeJobInvalid(t *testing.T) {
+	var job Job
+	if err := json.Unmarshal([]byte(`{"objects": {"url": "https://example.com"}}`), &job); err == nil {
+		t.Error("expected error for invalid JSON objects")
+	}
+	if err := yaml.Unmarshal([]byte("objects:\n  - options: [1, 2]\n"), &job); err == nil {
+		t.Error("expected error for invalid YAML object options")
+	}
+}
+
+func TestObjectSpecObject(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     ObjectSpec
+		location string
+		toc      bool
+		cover    bool
+	}{
+		{
+			name:     "url",
+			spec:     ObjectSpec{URL: "https://example.com"},
+			location: "https://example.com",
+		},
+		{
+			name:     "file",
+			spec:     ObjectSpec{Type: ObjectPage, File: "page.html"},
+			location: "page.html",
+		},
+		{
+			name:     "cover",
+			spec:     ObjectSpec{Type: ObjectCover, File: "cover.html"},
+			location: "cover.html",
+			cover:    true,
+		},
+		{
+			name:     "toc",
+			spec:     ObjectSpec{Type: ObjectTOC, URL: "https://example.com/toc.xsl"},
+			location: "https://example.com/toc.xsl",
+			toc:      true,
+		},
+		{
+			name: "toc without style sheet",
+			spec: ObjectSpec{Type: ObjectTOC},
+			toc:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := NewObjectOpts()
+			opts.Header.ContentCenter = "[title]"
+			test.spec.Options = opts
+
+			object, err := test.spec.object()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer object.Destroy()
+
+			if object.Location != test.location {
+				t.Errorf("expected location %q, got %q", test.location, object.Location)
+			}
+			if object.toc != test.toc || object.temporary {
+				t.Errorf("expected toc %t and non-temporary object, got %t and %t", test.toc, object.toc, object.temporary)
+			}
+
+			// Cover pages are excluded from outlines and page counts and
+			// have no headers.
+			if excluded := !object.IncludeInOutline && !object.CountPages && object.Header == (Header{}); excluded != test.cover {
+				t.Errorf("expected cover options %t, got %t", test.cover, excluded)
+			}
+
+			// The options of the specification are not modified.
+			if opts.Location != "" || !opts.IncludeInOutline || opts.Header.ContentCenter != "[title]" {
+				t.Errorf("specification options were modified: %+v", opts)
+			}
+		})
+	}
+}
+
+func TestObjectSpecObjectHTML(t *testing.T) {
+	spec := ObjectSpec{HTML: "<h1>Invoice</h1>"}
+
+	object, err := spec.object()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !object.temporary {
+		t.Error("expected temporary object")
+	}
+
+	data, err := os.ReadFile(object.Location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != spec.HTML {
+		t.Errorf("expected content %q, got %q", spec.HTML, data)
+	}
+
+	// The temporary file is removed when the object is destroyed.
+	location := object.Location
+	object.Destroy()
+	if _, err := os.Stat(location); !os.IsNotExist(err) {
+		t.Errorf("expected temporary file to be removed, got %v", err)
+	}
+}
+
+func TestObjectSpecObjectErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		spec ObjectSpec
+	}{
+		{name: "missing source", spec: ObjectSpec{}},
+		{name: "missing cover source", spec: ObjectSpec{Type: ObjectCover}},
+		{name: "multiple sources", spec: ObjectSpec{File: "page.html", URL: "https://example.com"}},
+		{name: "toc html", spec: ObjectSpec{Type: ObjectTOC, HTML: "<xsl/>"}},
+		{name: "invalid type", spec: ObjectSpec{Type: "appendix", File: "appendix.html"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if object, err := test.spec.object(); err == nil {
+				object.Destroy()
+				t.Error("expected error for invalid object specification")
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+//go:build cgo
+
+package pdf
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrNotInitialized is returned when using the library before it is
+	// initialized, after it is destroyed or while it is shutting down.
+	ErrNotInitialized = errors.New("library is not initialized")
+
+	// ErrBusy is returned when releasing or shutting down the library while
+	// converters or objects are still in use, or when initializing the
+	// library while it is shutting down.
+	ErrBusy = errors.New("library is in use")
+)
+
+// lifecycle keeps track of the state of the library: the number of
+// references acquired by Init, the number of live converters and objects,
+// and the number of conversions in progress.
+type lifecycle struct {
+	sync.Mutex
+
+	refs          int
+	closing       bool
+	deinitialized bool
+
+	converters int
+	objects    int
+	running    int
+	idle       chan struct{}
+}
+
+var lib = &lifecycle{}
+
+// initialized returns true if the library can be used. The caller must hold
+// the lock.
+func (l *lifecycle) initialized() bool {
+	return l.refs > 0 && !l.closing
+}
+
+// add increments the specified resource counter, if the library can be used.
+func (l *lifecycle) add(counter *int) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if !l.initialized() {
+		return ErrNotInitialized
+	}
+	*counter++
+
+	return nil
+}
+
+// remove decrements the specified resource counter.
+func (l *lifecycle) remove(counter *int) {
+	l.Lock()
+	if *counter > 0 {
+		*counter--
+	}
+	l.Unlock()
+}
+
+// begin marks the start of a conversion, if the library can be used.
+func (l *lifecycle) begin() error {
+	l.Lock()
+	defer l.Unlock()
+
+	if !l.initialized() {
+		return ErrNotInitialized
+	}
+	if l.running == 0 {
+		l.idle = make(chan struct{})
+	}
+	l.running++
+
+	return nil
+}
+
+// end marks the end of a conversion.
+func (l *lifecycle) end() {
+	l.Lock()
+	if l.running--; l.running == 0 {
+		close(l.idle)
+	}
+	l.Unlock()
+}
+
+// busy returns true if there are live converters or objects, or conversions
+// in progress. The caller must hold the lock.
+func (l *lifecycle) busy() bool {
+	return l.running > 0 || l.converters > 0 || l.objects > 0
+}
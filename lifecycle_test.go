@@ -0,0 +1,100 @@
+//go:build cgo
+
+package pdf
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"sync"
+	"testing"
+)
+
+// shutdownTestEnv is set when running the shutdown tests in a separate
+// process, as the library cannot be initialized again after it is destroyed.
+const shutdownTestEnv = "PDF_TEST_SHUTDOWN"
+
+func TestShutdown(t *testing.T) {
+	if os.Getenv(shutdownTestEnv) == "" {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestShutdown$", "-test.v")
+		cmd.Env = append(os.Environ(), shutdownTestEnv+"=1")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("shutdown test failed: %v\n%s", err, out)
+		}
+		return
+	}
+
+	if err := Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	converter, err := NewConverter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := addObjects(converter, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	// Converters in use are not destroyed.
+	converter.mu.Lock()
+	if err := Shutdown(context.Background()); !errors.Is(err, ErrBusy) {
+		t.Fatalf("expected ErrBusy for converter in use, got %v", err)
+	}
+	converter.mu.Unlock()
+
+	// Standalone objects cannot be destroyed by Shutdown.
+	object, err := NewObject("page.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Shutdown(context.Background()); !errors.Is(err, ErrBusy) {
+		t.Fatalf("expected ErrBusy for standalone object, got %v", err)
+	}
+	object.Destroy()
+
+	// Objects added concurrently with Shutdown are either destroyed along
+	// with the converter or rejected.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for addObjects(converter, 1) == nil {
+		}
+	}()
+
+	var shutdownErr error
+	for shutdownErr = ErrBusy; errors.Is(shutdownErr, ErrBusy); {
+		shutdownErr = Shutdown(context.Background())
+	}
+	wg.Wait()
+	if shutdownErr != nil {
+		t.Fatal(shutdownErr)
+	}
+
+	if converter.converter != nil || len(converter.objects) != 0 {
+		t.Error("expected converter to be destroyed")
+	}
+	if err := converter.Add(object); err == nil {
+		t.Error("expected error when using destroyed converter")
+	}
+	if _, err := NewConverter(); !errors.Is(err, ErrNotInitialized) {
+		t.Errorf("expected ErrNotInitialized, got %v", err)
+	}
+}
+
+func addObjects(converter *Converter, count int) error {
+	for i := 0; i < count; i++ {
+		object, err := NewObject("page.html")
+		if err != nil {
+			return err
+		}
+		if err := converter.Add(object); err != nil {
+			object.Destroy()
+			return err
+		}
+	}
+
+	return nil
+}
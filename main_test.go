@@ -0,0 +1,19 @@
+//go:build cgo
+
+package pdf
+
+import (
+	"log"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	if err := Init(); err != nil {
+		log.Fatal(err)
+	}
+	code := m.Run()
+	Destroy()
+
+	os.Exit(code)
+}
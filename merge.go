@@ -0,0 +1,150 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// Merge overlays the specified options on top of the current ones and
+// returns the receiver. Non-zero values of the specified options replace
+// the current values. Zero values (empty strings, false, 0) are ignored,
+// as they cannot be distinguished from unset options, while optional
+// (pointer) fields replace the current values whenever they are not nil.
+// Extra settings are merged key by key. In order to reset options to their
+// default values or to set boolean options to false, use ApplyMergePatch.
+// E.g.: opts := pdf.NewConverterOpts().Merge(tenantOpts).Merge(requestOpts).
+func (opts *ConverterOpts) Merge(other *ConverterOpts) *ConverterOpts {
+	if other != nil {
+		mergeValues(reflect.ValueOf(opts).Elem(), reflect.ValueOf(other).Elem())
+	}
+
+	return opts
+}
+
+// ApplyMergePatch applies the specified JSON Merge Patch (RFC 7396) to the
+// converter options. The patch uses the JSON representation of the options.
+// Options set to null in the patch are reset to their default values, as
+// returned by NewConverterOpts.
+// E.g.: opts.ApplyMergePatch([]byte(`{"title": "Invoice", "marginTop": null}`)).
+func (opts *ConverterOpts) ApplyMergePatch(patch []byte) error {
+	patched := NewConverterOpts()
+	if err := applyOptsMergePatch(opts, patch, patched); err != nil {
+		return err
+	}
+
+	*opts = *patched
+	return nil
+}
+
+// Merge overlays the specified options on top of the current ones and
+// returns the receiver. See ConverterOpts.Merge for the merge semantics.
+func (opts *ObjectOpts) Merge(other *ObjectOpts) *ObjectOpts {
+	if other != nil {
+		mergeValues(reflect.ValueOf(opts).Elem(), reflect.ValueOf(other).Elem())
+	}
+
+	return opts
+}
+
+// ApplyMergePatch applies the specified JSON Merge Patch (RFC 7396) to the
+// object options. The patch uses the JSON representation of the options.
+// Options set to null in the patch are reset to their default values, as
+// returned by NewObjectOpts.
+// E.g.: opts.ApplyMergePatch([]byte(`{"footer": {"contentCenter": "[page]"}}`)).
+func (opts *ObjectOpts) ApplyMergePatch(patch []byte) error {
+	patched := NewObjectOpts()
+	if err := applyOptsMergePatch(opts, patch, patched); err != nil {
+		return err
+	}
+
+	*opts = *patched
+	return nil
+}
+
+// MergePatch applies the specified JSON Merge Patch (RFC 7396) to the
+// provided JSON document and returns the resulting document.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	var docValue interface{}
+	if len(bytes.TrimSpace(doc)) > 0 {
+		if err := json.Unmarshal(doc, &docValue); err != nil {
+			return nil, err
+		}
+	}
+
+	var patchValue interface{}
+	if err := json.Unmarshal(patch, &patchValue); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(mergePatch(docValue, patchValue))
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+			continue
+		}
+		targetObj[key] = mergePatch(targetObj[key], value)
+	}
+
+	return targetObj
+}
+
+func applyOptsMergePatch(opts interface{}, patch []byte, defaults interface{}) error {
+	doc, err := json.Marshal(opts)
+	if err != nil {
+		return err
+	}
+	if doc, err = MergePatch(doc, patch); err != nil {
+		return err
+	}
+
+	// Options removed by the patch retain their default values.
+	return json.Unmarshal(doc, defaults)
+}
+
+func mergeValues(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Struct:
+		for i := 0; i < src.NumField(); i++ {
+			if dst.Type().Field(i).IsExported() {
+				mergeValues(dst.Field(i), src.Field(i))
+			}
+		}
+	case reflect.Ptr:
+		if !src.IsNil() {
+			value := reflect.New(src.Type().Elem())
+			value.Elem().Set(src.Elem())
+			dst.Set(value)
+		}
+	case reflect.Map:
+		if src.Len() == 0 {
+			return
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		}
+		for iter := src.MapRange(); iter.Next(); {
+			dst.SetMapIndex(iter.Key(), iter.Value())
+		}
+	case reflect.Slice:
+		if src.Len() > 0 {
+			dst.Set(reflect.AppendSlice(reflect.MakeSlice(src.Type(), 0, src.Len()), src))
+		}
+	default:
+		if !src.IsZero() {
+			dst.Set(src)
+		}
+	}
+}
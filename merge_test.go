@@ -0,0 +1,171 @@
+package pdf
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	// Test cases from RFC 7396, Appendix A.
+	tests := []struct {
+		doc      string
+		patch    string
+		expected string
+	}{
+		{doc: `{"a":"b"}`, patch: `{"a":"c"}`, expected: `{"a":"c"}`},
+		{doc: `{"a":"b"}`, patch: `{"b":"c"}`, expected: `{"a":"b","b":"c"}`},
+		{doc: `{"a":"b"}`, patch: `{"a":null}`, expected: `{}`},
+		{doc: `{"a":"b","b":"c"}`, patch: `{"a":null}`, expected: `{"b":"c"}`},
+		{doc: `{"a":["b"]}`, patch: `{"a":"c"}`, expected: `{"a":"c"}`},
+		{doc: `{"a":"c"}`, patch: `{"a":["b"]}`, expected: `{"a":["b"]}`},
+		{doc: `{"a":{"b":"c"}}`, patch: `{"a":{"b":"d","c":null}}`, expected: `{"a":{"b":"d"}}`},
+		{doc: `{"a":[{"b":"c"}]}`, patch: `{"a":[1]}`, expected: `{"a":[1]}`},
+		{doc: `["a","b"]`, patch: `["c","d"]`, expected: `["c","d"]`},
+		{doc: `{"a":"b"}`, patch: `["c"]`, expected: `["c"]`},
+		{doc: `{"a":"foo"}`, patch: `null`, expected: `null`},
+		{doc: `{"a":"foo"}`, patch: `"bar"`, expected: `"bar"`},
+		{doc: `{"e":null}`, patch: `{"a":1}`, expected: `{"a":1,"e":null}`},
+		{doc: `[1,2]`, patch: `{"a":"b","c":null}`, expected: `{"a":"b"}`},
+		{doc: `{}`, patch: `{"a":{"bb":{"ccc":null}}}`, expected: `{"a":{"bb":{}}}`},
+		{doc: ``, patch: `{"a":1}`, expected: `{"a":1}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.doc+" "+test.patch, func(t *testing.T) {
+			result, err := MergePatch([]byte(test.doc), []byte(test.patch))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(result) != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, result)
+			}
+		})
+	}
+
+	if _, err := MergePatch([]byte(`{`), []byte(`{}`)); err == nil {
+		t.Error("expected error for invalid document")
+	}
+	if _, err := MergePatch([]byte(`{}`), []byte(`{`)); err == nil {
+		t.Error("expected error for invalid patch")
+	}
+}
+
+func TestConverterOptsMerge(t *testing.T) {
+	opts := NewConverterOpts()
+	opts.Title = "Base"
+	opts.Extra = map[string]string{"a": "1", "b": "2"}
+	opts.Outline = []OutlineItem{{Title: "Base"}}
+
+	overlay := &ConverterOpts{
+		MarginTop:       "2cm",
+		GenerateOutline: false,
+		ImageQuality:    Uint64(0),
+		Extra:           map[string]string{"b": "3", "c": "4"},
+	}
+	merged := opts.Merge(overlay).Merge(nil)
+	if merged != opts {
+		t.Fatal("expected Merge to return the receiver")
+	}
+
+	expected := NewConverterOpts()
+	expected.Title = "Base"
+	expected.MarginTop = "2cm"
+	expected.ImageQuality = Uint64(0)
+	expected.Extra = map[string]string{"a": "1", "b": "3", "c": "4"}
+	expected.Outline = []OutlineItem{{Title: "Base"}}
+	if !reflect.DeepEqual(opts, expected) {
+		t.Errorf("expected %+v, got %+v", expected, opts)
+	}
+
+	// Merged values must not be shared with the overlay.
+	*overlay.ImageQuality = 50
+	if *opts.ImageQuality != 0 {
+		t.Error("optional values are shared with the merged options")
+	}
+}
+
+func TestObjectOptsMerge(t *testing.T) {
+	opts := NewObjectOpts()
+	opts.Merge(&ObjectOpts{
+		Location: "page.html",
+		Zoom:     Float64(0.5),
+		Footer:   Header{ContentCenter: "[page]"},
+		TOC:      TOC{Title: "Contents"},
+	})
+
+	expected := NewObjectOpts()
+	expected.Location = "page.html"
+	expected.Zoom = Float64(0.5)
+	expected.Footer.ContentCenter = "[page]"
+	expected.TOC.Title = "Contents"
+	if !reflect.DeepEqual(opts, expected) {
+		t.Errorf("expected %+v, got %+v", expected, opts)
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	current := func() *ConverterOpts {
+		opts := NewConverterOpts()
+		opts.Title = "Current"
+		opts.MarginLeft = "1in"
+		opts.ImageQuality = Uint64(10)
+		opts.PaperSize = Letter
+		opts.Extra = map[string]string{"a": "1", "b": "2"}
+		return opts
+	}
+
+	tests := []struct {
+		name   string
+		patch  string
+		modify func(opts *ConverterOpts)
+	}{
+		{
+			name:   "set",
+			patch:  `{"title": "Invoice", "copies": 2}`,
+			modify: func(opts *ConverterOpts) { opts.Title, opts.Copies = "Invoice", 2 },
+		},
+		{
+			name:   "false",
+			patch:  `{"collate": false}`,
+			modify: func(opts *ConverterOpts) { opts.Collate = false },
+		},
+		{
+			name:  "reset",
+			patch: `{"marginLeft": null, "imageQuality": null, "paperSize": null}`,
+			modify: func(opts *ConverterOpts) {
+				opts.MarginLeft, opts.ImageQuality, opts.PaperSize = "10mm", Uint64(100), A4
+			},
+		},
+		{
+			name:   "extra",
+			patch:  `{"extra": {"a": null, "c": "3"}}`,
+			modify: func(opts *ConverterOpts) { opts.Extra = map[string]string{"b": "2", "c": "3"} },
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := current()
+			if err := opts.ApplyMergePatch([]byte(test.patch)); err != nil {
+				t.Fatal(err)
+			}
+
+			expected := current()
+			test.modify(expected)
+			if !reflect.DeepEqual(opts, expected) {
+				got, _ := json.Marshal(opts)
+				want, _ := json.Marshal(expected)
+				t.Errorf("expected %s, got %s", want, got)
+			}
+		})
+	}
+
+	opts := NewObjectOpts()
+	if err := opts.ApplyMergePatch([]byte(`{"zoom": "large"}`)); err == nil {
+		t.Error("expected error for invalid option value")
+	}
+	if opts.Zoom == nil || *opts.Zoom != 1 {
+		t.Error("options were modified by an invalid patch")
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !pdfdebug
+
+package pdf
+
+// trackResource reports leaked resources in debug builds. Build with the
+// pdfdebug tag in order to enable leak detection.
+func trackResource[T any](resource *T, kind string, released func(*T) bool) {}
@@ -0,0 +1,93 @@
+package pdf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// NewObject returns a new object instance from the document at the specified
+// location. The location can be a file path or a URL. The object is configured
+// using sensible defaults. See NewObjectOpts for the default options.
+func NewObject(location string) (*Object, error) {
+	return newObject(location, false, nil)
+}
+
+// NewObjectWithOpts returns a new object instance from the document at the
+// specified location. The location can be a file path or a URL. The object is
+// configured using the specified options. If no options are provided, sensible
+// defaults are used. See NewObjectOpts for the default options.
+func NewObjectWithOpts(opts *ObjectOpts) (*Object, error) {
+	return newObject("", false, opts)
+}
+
+// NewObjectFromReader creates a new object from the specified reader.
+// The object is configured using sensible defaults. See NewObjectOpts for
+// the default options.
+func NewObjectFromReader(r io.Reader) (*Object, error) {
+	location, err := createTempHTML(r)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := newObject(location, true, nil)
+	if err != nil {
+		os.Remove(location) // nolint:errcheck
+	}
+	return object, err
+}
+
+func newObject(location string, temp bool, opts *ObjectOpts) (*Object, error) {
+	if opts == nil {
+		opts = NewObjectOpts()
+	}
+	if location != "" {
+		opts.Location = location
+	}
+	if opts.Location == "" {
+		return nil, errors.New("must provide HTML document location")
+	}
+
+	return createObject(opts, temp, false)
+}
+
+// newTOCObject returns a new object which generates a table of contents for
+// the objects of the converter it is added to. The location of the object
+// is the optional location of an XSL style sheet used to render the table.
+func newTOCObject(opts *ObjectOpts) (*Object, error) {
+	if opts == nil {
+		opts = NewObjectOpts()
+	}
+
+	return createObject(opts, false, true)
+}
+
+// createTempHTML writes the HTML document read from the specified reader
+// to a temporary file and returns the location of the file.
+func createTempHTML(r io.Reader) (string, error) {
+	file, err := os.CreateTemp("", "pdf-")
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		os.Remove(file.Name()) // nolint:errcheck
+		return "", err
+	}
+
+	tempLocation := file.Name()
+	if err := file.Close(); err != nil {
+		os.Remove(tempLocation) // nolint:errcheck
+		return "", err
+	}
+
+	location := fmt.Sprintf("%s.html", tempLocation)
+	if err := os.Rename(tempLocation, location); err != nil {
+		os.Remove(tempLocation) // nolint:errcheck
+		return "", err
+	}
+
+	return location, nil
+}
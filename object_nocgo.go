@@ -0,0 +1,32 @@
+//go:build !cgo
+
+package pdf
+
+import "os"
+
+// Object represents an HTML document. The contained options are applied only
+// to the current object. Without cgo, objects only contain the options of the
+// documents, which can be converted by Renderer implementations that do not
+// use the `wkhtmltox` library, such as pdftest.FakeRenderer.
+type Object struct {
+	*ObjectOpts
+	temporary bool
+	toc       bool
+}
+
+func createObject(opts *ObjectOpts, temp, toc bool) (*Object, error) {
+	return &Object{
+		ObjectOpts: opts,
+		temporary:  temp,
+		toc:        toc,
+	}, nil
+}
+
+// Destroy releases all resources used by the object.
+func (o *Object) Destroy() {
+	// Remove temporary file.
+	if o.temporary && o.Location != "" {
+		os.Remove(o.Location) // nolint:errcheck
+		o.Location, o.temporary = "", false
+	}
+}
@@ -0,0 +1,253 @@
+package pdf
+
+// ErrorAction defines actions to take in case of object load failure.
+type ErrorAction string
+
+// Error action values.
+const (
+	ActionAbort  ErrorAction = "abort"
+	ActionIgnore ErrorAction = "ignore"
+	ActionSkip   ErrorAction = "skip"
+)
+
+// TOC contains settings related to the table of contents of an object.
+type TOC struct {
+	// Specifies whether dotted lines should be used for the line of items
+	// of the TOC.
+	UseDottedLines bool `json:"useDottedLines" yaml:"useDottedLines"`
+
+	// The title used for the table of contents.
+	// E.g.: "Table of Contents".
+	Title string `json:"title" yaml:"title"`
+
+	// Specifies whether the TOC items should contain links to the content.
+	GenerateForwardLinks bool `json:"generateForwardLinks" yaml:"generateForwardLinks"`
+
+	// Specifies whether the content should contain links to the TOC.
+	GenerateBackLinks bool `json:"generateBackLinks" yaml:"generateBackLinks"`
+
+	// The indentation used for the TOC nesting levels.
+	// E.g.: "1em".
+	Indentation string `json:"indentation" yaml:"indentation"`
+
+	// Scaling factor for each nesting level of the TOC. If not specified,
+	// the library default is used.
+	// E.g.: pdf.Float64(1).
+	FontScale *float64 `json:"fontScale" yaml:"fontScale"`
+}
+
+// Header contains settings related to the headers and footers of an object.
+type Header struct {
+	// The system font name to use for headers/footers.
+	// E.g.: "Arial".
+	Font string `json:"font" yaml:"font"`
+
+	// The font size to use for headers/footers. A font size of 0 cannot be
+	// rendered, so it is treated as unset, in which case the library default
+	// is used.
+	// E.g.: 12.
+	FontSize uint64 `json:"fontSize" yaml:"fontSize"`
+
+	// Content to print on each of the available regions of the header/footer.
+	// Substitution variables that can be used in the content fields:
+	//  - [page]       The number of the current page.
+	//  - [frompage]   The number of the first page.
+	//  - [topage]     The number of the last page.
+	//  - [webpage]    The URL of the source page.
+	//  - [section]    The name of the current section.
+	//  - [subsection] The name of the current subsection.
+	//  - [date]       The current date in system local format.
+	//  - [isodate]    The current date in ISO 8601 extended format.
+	//  - [time]       The current time in system local format.
+	//  - [title]      The title of the of the current page object.
+	//  - [doctitle]   The title of the output document.
+	//  - [sitepage]   The number of the page in the currently converted site.
+	//  - [sitepages]  The number of pages in the current site being converted.
+	// e.g.: object.Footer.ContentRight = "[page]"
+	ContentLeft   string `json:"contentLeft" yaml:"contentLeft"`
+	ContentCenter string `json:"contentCenter" yaml:"contentCenter"`
+	ContentRight  string `json:"contentRight" yaml:"contentRight"`
+
+	// Specifies whether a line separator should be printed for headers/footers.
+	DisplaySeparator bool `json:"displaySeparator" yaml:"displaySeparator"`
+
+	// The amount of space between the header/footer and the content.
+	// The value is always passed to the library, so 0 is not treated as unset.
+	// E.g.: 0.
+	Spacing float64 `json:"spacing" yaml:"spacing"`
+
+	// Location of a user defined HTML document to be used as the header/footer.
+	CustomLocation string `json:"customLocation" yaml:"customLocation"`
+}
+
+// ObjectOpts defines a set of options to be used in the conversion process.
+type ObjectOpts struct {
+	// Specifies the location of the HTML document. Can be a file path or a URL.
+	Location string `json:"location" yaml:"location"`
+
+	// Specifies whether external links in the HTML document should be converted
+	// to external PDF links.
+	UseExternalLinks bool `json:"useExternalLinks" yaml:"useExternalLinks"`
+
+	// Specifies whether internal links in the HTML document should be converted
+	// into PDF references.
+	UseLocalLinks bool `json:"useLocalLinks" yaml:"useLocalLinks"`
+
+	// Specifies whether HTML forms should be converted into PDF forms.
+	ProduceForms bool `json:"produceForms" yaml:"produceForms"`
+
+	// Specifies whether the sections from the HTML document are included in
+	// outlines and TOCs.
+	IncludeInOutline bool `json:"includeInOutline" yaml:"includeInOutline"`
+
+	// Specifies whether the page count of the HTML document participates in
+	// the counter used for tables of contents, headers and footers.
+	CountPages bool `json:"countPages" yaml:"countPages"`
+
+	// Contains settings for the TOC of the object.
+	TOC TOC `json:"toc" yaml:"toc"`
+
+	// Contains settings for the header of the object.
+	Header Header `json:"header" yaml:"header"`
+
+	// Contains settings for the footer of the object.
+	Footer Header `json:"footer" yaml:"footer"`
+
+	// The username to use when logging in to a website.
+	Username string `json:"username" yaml:"username"`
+
+	// The password to use when logging in to a website.
+	Password string `json:"password" yaml:"password"`
+
+	// The amount of milliseconds to wait after page load, before
+	// executing JS scripts. If not specified, the library default is used.
+	// E.g.: pdf.Uint64(300).
+	JavascriptDelay *uint64 `json:"javascriptDelay" yaml:"javascriptDelay"`
+
+	// Specifies the `window.status` value to wait for, before
+	// rendering the page.
+	// E.g.: "ready".
+	WindowStatus string `json:"windowStatus" yaml:"windowStatus"`
+
+	// Zoom factor to use for the document content. If not specified,
+	// the library default is used.
+	// E.g.: pdf.Float64(1).
+	Zoom *float64 `json:"zoom" yaml:"zoom"`
+
+	// Specifies whether local file access is blocked.
+	BlockLocalFileAccess bool `json:"blockLocalFileAccess" yaml:"blockLocalFileAccess"`
+
+	// Specifies whether slow JS scripts should be stopped.
+	StopSlowScripts bool `json:"stopSlowScripts" yaml:"stopSlowScripts"`
+
+	// Specifies a course of action when an HTML document fails to load.
+	// E.g.: ActionAbort.
+	ErrorAction ErrorAction `json:"errorAction" yaml:"errorAction"`
+
+	// The name of a proxy to use when loading the HTML document.
+	Proxy string `json:"proxy" yaml:"proxy"`
+
+	// Specifies whether the background of the HTML document is preserved.
+	PrintBackground bool `json:"printBackground" yaml:"printBackground"`
+
+	// Specifies whether the images in the HTML document are loaded.
+	LoadImages bool `json:"loadImages" yaml:"loadImages"`
+
+	// Specifies whether Javascript should be executed.
+	EnableJavascript bool `json:"enableJavascript" yaml:"enableJavascript"`
+
+	// Specifies whether to use intelligent shrinkng in order to fit more
+	// content on a page.
+	UseSmartShrinking bool `json:"useSmartShrinking" yaml:"useSmartShrinking"`
+
+	// The minimum font size allowed for rendering content. If not specified,
+	// no minimum font size is used.
+	// E.g.: pdf.Uint64(8).
+	MinFontSize *uint64 `json:"minFontSize" yaml:"minFontSize"`
+
+	// The text encoding to use if the HTML document does not specify one.
+	// E.g.: "utf-8".
+	DefaultEncoding string `json:"defaultEncoding" yaml:"defaultEncoding"`
+
+	// Specifies whether the content should be rendered using the print media
+	// type instead of the screen media type.
+	UsePrintMediaType bool `json:"usePrintMediaType" yaml:"usePrintMediaType"`
+
+	// The location of a user defined stylesheet to use when converting
+	// the HTML document.
+	UserStylesheetLocation string `json:"userStylesheetLocation" yaml:"userStylesheetLocation"`
+
+	// Specifies whether NS plugins should be enabled.
+	EnablePlugins bool `json:"enablePlugins" yaml:"enablePlugins"`
+
+	// Raw object settings passed to the library as is, after the options
+	// above. Useful for settings which are not modeled by the other fields.
+	// E.g.: {"load.debugJavascript": "true"}.
+	Extra map[string]string `json:"extra" yaml:"extra" flag:"objectExtra"`
+}
+
+// NewObjectOpts returns a new instance of object options, configured
+// using sensible defaults.
+//
+//	Defaults options:
+//
+//	UseExternalLinks:  true
+//	UseLocalLinks:     true
+//	IncludeInOutline:  true
+//	CountPages:        true
+//	JavascriptDelay:   300
+//	Zoom:              1
+//	StopSlowScripts:   true
+//	ErrorAction:       ActionAbort
+//	PrintBackground:   true
+//	LoadImages:        true
+//	EnableJavascript:  true
+//	UseSmartShrinking: true
+//	DefaultEncoding:   "utf-8"
+//	TOC:
+//		UseDottedLines:       true
+//		Title:                "Table of Contents"
+//		GenerateForwardLinks: true
+//		GenerateBackLinks:    true
+//		Indentation:          "1em"
+//		FontScale:            1
+//	Header:
+//		Font:     "Arial"
+//		FontSize: 12
+//	Footer:
+//		Font:     "Arial"
+//		FontSize: 12
+func NewObjectOpts() *ObjectOpts {
+	return &ObjectOpts{
+		UseExternalLinks:  true,
+		UseLocalLinks:     true,
+		ProduceForms:      true,
+		IncludeInOutline:  true,
+		CountPages:        true,
+		JavascriptDelay:   Uint64(300),
+		Zoom:              Float64(1),
+		StopSlowScripts:   true,
+		ErrorAction:       ActionAbort,
+		PrintBackground:   true,
+		LoadImages:        true,
+		EnableJavascript:  true,
+		UseSmartShrinking: true,
+		DefaultEncoding:   "utf-8",
+		TOC: TOC{
+			UseDottedLines:       true,
+			Title:                "Table of Contents",
+			GenerateForwardLinks: true,
+			GenerateBackLinks:    true,
+			Indentation:          "1em",
+			FontScale:            Float64(1),
+		},
+		Header: Header{
+			Font:     "Arial",
+			FontSize: 12,
+		},
+		Footer: Header{
+			Font:     "Arial",
+			FontSize: 12,
+		},
+	}
+}
@@ -1,3 +1,5 @@
+//go:build cgo
+
 package pdf
 
 import (
@@ -50,3 +52,14 @@ func (or *objectRegistry) remove(id objectID) {
 	delete(or.objects, id)
 	or.Unlock()
 }
+
+func (or *objectRegistry) all() []interface{} {
+	or.RLock()
+	objects := make([]interface{}, 0, len(or.objects))
+	for _, object := range or.objects {
+		objects = append(objects, object)
+	}
+	or.RUnlock()
+
+	return objects
+}
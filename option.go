@@ -2,9 +2,24 @@ package pdf
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 )
 
+// Uint64 returns a pointer to the specified value. It can be used to specify
+// optional unsigned integer options.
+// E.g.: object.JavascriptDelay = pdf.Uint64(0).
+func Uint64(v uint64) *uint64 {
+	return &v
+}
+
+// Float64 returns a pointer to the specified value. It can be used to specify
+// optional floating point options.
+// E.g.: object.Zoom = pdf.Float64(0.5).
+func Float64(v float64) *float64 {
+	return &v
+}
+
 type optType int
 
 const (
@@ -36,25 +51,46 @@ func newSetOp(name string, value interface{}, typ optType, setter setterFunc, se
 }
 
 func (op *setOp) execute() error {
+	// Optional values are only set if they are specified, in which case
+	// they are set regardless of their value.
+	value, setEmpty := op.value, op.setEmpty
+	switch v := value.(type) {
+	case *int64:
+		if v == nil {
+			return nil
+		}
+		value, setEmpty = *v, true
+	case *uint64:
+		if v == nil {
+			return nil
+		}
+		value, setEmpty = *v, true
+	case *float64:
+		if v == nil {
+			return nil
+		}
+		value, setEmpty = *v, true
+	}
+
 	switch op.typ {
 	case optTypeString:
-		if val := op.value.(string); op.setEmpty || val != "" {
+		if val := value.(string); setEmpty || val != "" {
 			return op.setter(op.name, val)
 		}
 	case optTypeBool:
-		if val := op.value.(bool); op.setEmpty || val {
+		if val := value.(bool); setEmpty || val {
 			return op.setter(op.name, strconv.FormatBool(val))
 		}
 	case optTypeInt:
-		if val := op.value.(int64); op.setEmpty || val > 0 {
+		if val := value.(int64); setEmpty || val > 0 {
 			return op.setter(op.name, strconv.FormatInt(val, 10))
 		}
 	case optTypeUint:
-		if val := op.value.(uint64); op.setEmpty || val > 0 {
+		if val := value.(uint64); setEmpty || val > 0 {
 			return op.setter(op.name, strconv.FormatUint(val, 10))
 		}
 	case optTypeFloat:
-		if val := op.value.(float64); op.setEmpty || uint64(val) > 0 {
+		if val := value.(float64); setEmpty || val > 0 {
 			return op.setter(op.name, strconv.FormatFloat(val, 'E', -1, 64))
 		}
 	default:
@@ -63,3 +99,80 @@ func (op *setOp) execute() error {
 
 	return nil
 }
+
+// newExtraSetOps returns the set operations for the specified raw options,
+// sorted by option name.
+func newExtraSetOps(extra map[string]string, setter setterFunc) []*setOp {
+	names := make([]string, 0, len(extra))
+	for name := range extra {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ops := make([]*setOp, 0, len(names))
+	for _, name := range names {
+		ops = append(ops, newSetOp(name, extra[name], optTypeString, setter, true))
+	}
+
+	return ops
+}
+
+// globalSettingNames contains the names of the scalar global settings
+// supported by the `wkhtmltox` library.
+var globalSettingNames = []string{
+	"size.pageSize", "size.width", "size.height", "orientation", "colorMode",
+	"resolution", "dpi", "pageOffset", "copies", "collate", "outline",
+	"outlineDepth", "dumpOutline", "out", "documentTitle", "useCompression",
+	"margin.top", "margin.bottom", "margin.left", "margin.right", "imageDPI",
+	"imageQuality", "load.cookieJar", "viewportSize", "outputFormat",
+	"resolveRelativeLinks", "useGraphics", "quiet",
+}
+
+// objectSettingNames contains the names of the scalar object settings
+// supported by the `wkhtmltox` library. List settings, such as the custom
+// HTTP headers or the cookies, cannot be retrieved as a single value.
+var objectSettingNames = []string{
+	"page", "isTableOfContent", "tocXsl", "useExternalLinks", "useLocalLinks",
+	"produceForms", "includeInOutline", "pagesCount",
+	"toc.useDottedLines", "toc.captionText", "toc.forwardLinks",
+	"toc.backLinks", "toc.indentation", "toc.fontScale",
+	"header.fontName", "header.fontSize", "header.left", "header.center",
+	"header.right", "header.line", "header.spacing", "header.htmlUrl",
+	"footer.fontName", "footer.fontSize", "footer.left", "footer.center",
+	"footer.right", "footer.line", "footer.spacing", "footer.htmlUrl",
+	"load.username", "load.password", "load.jsdelay", "load.windowStatus",
+	"load.zoomFactor", "load.repeatCustomHeaders", "load.blockLocalFileAccess",
+	"load.stopSlowScripts", "load.debugJavascript", "load.loadErrorHandling",
+	"load.mediaLoadErrorHandling", "load.proxy", "load.proxyHostNameLookup",
+	"web.background", "web.loadImages", "web.enableJavascript",
+	"web.enableIntelligentShrinking", "web.minimumFontSize",
+	"web.defaultEncoding", "web.printMediaType", "web.userStyleSheet",
+	"web.enablePlugins",
+}
+
+// effectiveSettings returns the values reported by the getter for each of
+// the specified setting names and for each of the options targeted by the
+// specified set operations. The settings which cannot be retrieved are
+// omitted.
+func effectiveSettings(names []string, ops []*setOp, getter func(name string) (string, error)) map[string]string {
+	names = append([]string(nil), names...)
+	for _, op := range ops {
+		names = append(names, op.name)
+	}
+
+	settings := make(map[string]string, len(names))
+	for _, name := range names {
+		if _, ok := settings[name]; ok {
+			continue
+		}
+		if value, err := getter(name); err == nil {
+			settings[name] = value
+		}
+	}
+
+	return settings
+}
+
+// maxOptionValueSize is the maximum size of the option values retrieved
+// from the `wkhtmltox` library.
+const maxOptionValueSize = 1 << 20
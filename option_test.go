@@ -0,0 +1,174 @@
+//go:build cgo
+
+package pdf
+
+import "testing"
+
+func TestSetOps(t *testing.T) {
+	type setting struct {
+		value string
+		set   bool
+	}
+
+	tests := []struct {
+		name      string
+		setting   string
+		converter func(opts *ConverterOpts)
+		object    func(opts *ObjectOpts)
+		expected  setting
+	}{
+		// Javascript delay.
+		{
+			name:     "javascript delay nil",
+			setting:  "load.jsdelay",
+			object:   func(opts *ObjectOpts) { opts.JavascriptDelay = nil },
+			expected: setting{},
+		},
+		{
+			name:     "javascript delay zero",
+			setting:  "load.jsdelay",
+			object:   func(opts *ObjectOpts) { opts.JavascriptDelay = Uint64(0) },
+			expected: setting{value: "0", set: true},
+		},
+		{
+			name:     "javascript delay",
+			setting:  "load.jsdelay",
+			object:   func(opts *ObjectOpts) { opts.JavascriptDelay = Uint64(500) },
+			expected: setting{value: "500", set: true},
+		},
+
+		// Zoom.
+		{
+			name:     "zoom nil",
+			setting:  "load.zoomFactor",
+			object:   func(opts *ObjectOpts) { opts.Zoom = nil },
+			expected: setting{},
+		},
+		{
+			name:     "zoom zero",
+			setting:  "load.zoomFactor",
+			object:   func(opts *ObjectOpts) { opts.Zoom = Float64(0) },
+			expected: setting{value: "0E+00", set: true},
+		},
+		{
+			name:     "zoom",
+			setting:  "load.zoomFactor",
+			object:   func(opts *ObjectOpts) { opts.Zoom = Float64(1.5) },
+			expected: setting{value: "1.5E+00", set: true},
+		},
+
+		// TOC font scale.
+		{
+			name:     "toc font scale nil",
+			setting:  "toc.fontScale",
+			object:   func(opts *ObjectOpts) { opts.TOC.FontScale = nil },
+			expected: setting{},
+		},
+		{
+			name:     "toc font scale zero",
+			setting:  "toc.fontScale",
+			object:   func(opts *ObjectOpts) { opts.TOC.FontScale = Float64(0) },
+			expected: setting{value: "0E+00", set: true},
+		},
+		{
+			name:     "toc font scale",
+			setting:  "toc.fontScale",
+			object:   func(opts *ObjectOpts) { opts.TOC.FontScale = Float64(0.8) },
+			expected: setting{value: "8E-01", set: true},
+		},
+
+		// Minimum font size.
+		{
+			name:     "min font size nil",
+			setting:  "web.minimumFontSize",
+			object:   func(opts *ObjectOpts) { opts.MinFontSize = nil },
+			expected: setting{},
+		},
+		{
+			name:     "min font size zero",
+			setting:  "web.minimumFontSize",
+			object:   func(opts *ObjectOpts) { opts.MinFontSize = Uint64(0) },
+			expected: setting{value: "0", set: true},
+		},
+		{
+			name:     "min font size",
+			setting:  "web.minimumFontSize",
+			object:   func(opts *ObjectOpts) { opts.MinFontSize = Uint64(12) },
+			expected: setting{value: "12", set: true},
+		},
+
+		// Outline depth.
+		{
+			name:      "outline depth nil",
+			setting:   "outlineDepth",
+			converter: func(opts *ConverterOpts) { opts.OutlineDepth = nil },
+			expected:  setting{},
+		},
+		{
+			name:      "outline depth zero",
+			setting:   "outlineDepth",
+			converter: func(opts *ConverterOpts) { opts.OutlineDepth = Uint64(0) },
+			expected:  setting{value: "0", set: true},
+		},
+		{
+			name:      "outline depth",
+			setting:   "outlineDepth",
+			converter: func(opts *ConverterOpts) { opts.OutlineDepth = Uint64(3) },
+			expected:  setting{value: "3", set: true},
+		},
+
+		// Image quality.
+		{
+			name:      "image quality nil",
+			setting:   "imageQuality",
+			converter: func(opts *ConverterOpts) { opts.ImageQuality = nil },
+			expected:  setting{},
+		},
+		{
+			name:      "image quality zero",
+			setting:   "imageQuality",
+			converter: func(opts *ConverterOpts) { opts.ImageQuality = Uint64(0) },
+			expected:  setting{value: "0", set: true},
+		},
+		{
+			name:      "image quality",
+			setting:   "imageQuality",
+			converter: func(opts *ConverterOpts) { opts.ImageQuality = Uint64(94) },
+			expected:  setting{value: "94", set: true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			settings := map[string]string{}
+			setter := func(name, value string) error {
+				if _, ok := settings[name]; ok {
+					t.Errorf("setting %q set multiple times", name)
+				}
+				settings[name] = value
+				return nil
+			}
+
+			var ops []*setOp
+			if test.converter != nil {
+				opts := NewConverterOpts()
+				test.converter(opts)
+				ops = (&Converter{ConverterOpts: opts}).setOps(setter)
+			} else {
+				opts := NewObjectOpts()
+				test.object(opts)
+				ops = (&Object{ObjectOpts: opts}).setOps(setter)
+			}
+			for _, op := range ops {
+				if err := op.execute(); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			value, set := settings[test.setting]
+			if set != test.expected.set || value != test.expected.value {
+				t.Errorf("expected setting %q to be %+v, got %+v", test.setting, test.expected, setting{value: value, set: set})
+			}
+		})
+	}
+}
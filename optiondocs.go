@@ -0,0 +1,98 @@
+// Code generated by gen_optiondocs.go; DO NOT EDIT.
+
+package pdf
+
+// optionDocs contains the summaries of the option field docs.
+var optionDocs = map[string]string{
+	"ConverterOpts.Collate":             "Specifies whether copies should be collated.",
+	"ConverterOpts.Colorspace":          "The color mode of the output document.",
+	"ConverterOpts.Conformance":         "The PDF/A conformance level of the output document.",
+	"ConverterOpts.CookieJarPath":       "Path of the file used to load and store cookies for web objects.",
+	"ConverterOpts.Copies":              "Copies of the converted documents to be included in the output document.",
+	"ConverterOpts.DPI":                 "DPI of the output document.",
+	"ConverterOpts.Extra":               "Raw global settings passed to the library as is, after the options above.",
+	"ConverterOpts.GenerateOutline":     "Specifies whether outlines should be generated for the output document.",
+	"ConverterOpts.Height":              "The height of the output document.",
+	"ConverterOpts.ImageDPI":            "The maximum number of DPI for the images in the output document.",
+	"ConverterOpts.ImageQuality":        "The compression factor to use for the JPEG images in the output document.",
+	"ConverterOpts.KeepRelativeLinks":   "Specifies whether relative external links are kept as is in the output document, instead of being resolved to absolute links.",
+	"ConverterOpts.MarginBottom":        "Size of the bottom margin.",
+	"ConverterOpts.MarginLeft":          "Size of the left margin.",
+	"ConverterOpts.MarginRight":         "Size of the right margin.",
+	"ConverterOpts.MarginTop":           "Size of the top margin.",
+	"ConverterOpts.Orientation":         "The orientation of the output document.",
+	"ConverterOpts.Outline":             "Custom outline items to be added to the output document.",
+	"ConverterOpts.OutlineDepth":        "The maximum number of nesting levels in outlines.",
+	"ConverterOpts.OutlineDumpPath":     "A location to write an XML representation of the generated outlines.",
+	"ConverterOpts.OutlineMode":         "Specifies how custom outline items are combined with the generated outlines.",
+	"ConverterOpts.OutputFormat":        "The file format of the output document.",
+	"ConverterOpts.PageOffset":          "A number added to all page numbers when rendering headers, footers and tables of contents.",
+	"ConverterOpts.PaperSize":           "The paper size of the output document.",
+	"ConverterOpts.Quiet":               "Specifies whether the library should suppress its own output.",
+	"ConverterOpts.Reproducible":        "Produce reproducible output documents.",
+	"ConverterOpts.Resolution":          "The resolution mode used for the output document.",
+	"ConverterOpts.Title":               "The title of the output document.",
+	"ConverterOpts.UseCompression":      "Specifies whether the conversion process should use lossless compression.",
+	"ConverterOpts.UseGraphics":         "Specifies whether the graphics system of the X server is used for rendering.",
+	"ConverterOpts.ViewportSize":        "The size of the viewport used to render the converted objects, useful for content which depends on the size of the window.",
+	"ConverterOpts.Width":               "The width of the output document.",
+	"Header.ContentCenter":              "Content to print on each of the available regions of the header/footer.",
+	"Header.ContentLeft":                "Content to print on each of the available regions of the header/footer.",
+	"Header.ContentRight":               "Content to print on each of the available regions of the header/footer.",
+	"Header.CustomLocation":             "Location of a user defined HTML document to be used as the header/footer.",
+	"Header.DisplaySeparator":           "Specifies whether a line separator should be printed for headers/footers.",
+	"Header.Font":                       "The system font name to use for headers/footers.",
+	"Header.FontSize":                   "The font size to use for headers/footers.",
+	"Header.Spacing":                    "The amount of space between the header/footer and the content.",
+	"ObjectOpts.BlockLocalFileAccess":   "Specifies whether local file access is blocked.",
+	"ObjectOpts.CountPages":             "Specifies whether the page count of the HTML document participates in the counter used for tables of contents, headers and footers.",
+	"ObjectOpts.DefaultEncoding":        "The text encoding to use if the HTML document does not specify one.",
+	"ObjectOpts.EnableJavascript":       "Specifies whether Javascript should be executed.",
+	"ObjectOpts.EnablePlugins":          "Specifies whether NS plugins should be enabled.",
+	"ObjectOpts.ErrorAction":            "Specifies a course of action when an HTML document fails to load.",
+	"ObjectOpts.Extra":                  "Raw object settings passed to the library as is, after the options above.",
+	"ObjectOpts.Footer":                 "Contains settings for the footer of the object.",
+	"ObjectOpts.Header":                 "Contains settings for the header of the object.",
+	"ObjectOpts.IncludeInOutline":       "Specifies whether the sections from the HTML document are included in outlines and TOCs.",
+	"ObjectOpts.JavascriptDelay":        "The amount of milliseconds to wait after page load, before executing JS scripts.",
+	"ObjectOpts.LoadImages":             "Specifies whether the images in the HTML document are loaded.",
+	"ObjectOpts.Location":               "Specifies the location of the HTML document.",
+	"ObjectOpts.MinFontSize":            "The minimum font size allowed for rendering content.",
+	"ObjectOpts.Password":               "The password to use when logging in to a website.",
+	"ObjectOpts.PrintBackground":        "Specifies whether the background of the HTML document is preserved.",
+	"ObjectOpts.ProduceForms":           "Specifies whether HTML forms should be converted into PDF forms.",
+	"ObjectOpts.Proxy":                  "The name of a proxy to use when loading the HTML document.",
+	"ObjectOpts.StopSlowScripts":        "Specifies whether slow JS scripts should be stopped.",
+	"ObjectOpts.TOC":                    "Contains settings for the TOC of the object.",
+	"ObjectOpts.UseExternalLinks":       "Specifies whether external links in the HTML document should be converted to external PDF links.",
+	"ObjectOpts.UseLocalLinks":          "Specifies whether internal links in the HTML document should be converted into PDF references.",
+	"ObjectOpts.UsePrintMediaType":      "Specifies whether the content should be rendered using the print media type instead of the screen media type.",
+	"ObjectOpts.UseSmartShrinking":      "Specifies whether to use intelligent shrinkng in order to fit more content on a page.",
+	"ObjectOpts.UserStylesheetLocation": "The location of a user defined stylesheet to use when converting the HTML document.",
+	"ObjectOpts.Username":               "The username to use when logging in to a website.",
+	"ObjectOpts.WindowStatus":           "Specifies the `window.status` value to wait for, before rendering the page.",
+	"ObjectOpts.Zoom":                   "Zoom factor to use for the document content.",
+	"OutlineItem.Children":              "The nested outline items.",
+	"OutlineItem.Link":                  "The name of the anchor the item points to.",
+	"OutlineItem.Open":                  "Specifies whether the nested items are displayed by default.",
+	"OutlineItem.Page":                  "The output document page the item points to.",
+	"OutlineItem.Title":                 "The title of the outline item.",
+	"TOC.FontScale":                     "Scaling factor for each nesting level of the TOC.",
+	"TOC.GenerateBackLinks":             "Specifies whether the content should contain links to the TOC.",
+	"TOC.GenerateForwardLinks":          "Specifies whether the TOC items should contain links to the content.",
+	"TOC.Indentation":                   "The indentation used for the TOC nesting levels.",
+	"TOC.Title":                         "The title used for the table of contents.",
+	"TOC.UseDottedLines":                "Specifies whether dotted lines should be used for the line of items of the TOC.",
+}
+
+// optionEnums contains the defined values of the option types.
+var optionEnums = map[string][]string{
+	"Colorspace":   {"Color", "Grayscale"},
+	"Conformance":  {"PDF/A-1b", "PDF/A-2b", "PDF/A-3b"},
+	"ErrorAction":  {"abort", "ignore", "skip"},
+	"Orientation":  {"Portrait", "Landscape"},
+	"OutlineMode":  {"replace", "append", "prepend"},
+	"OutputFormat": {"pdf", "ps"},
+	"PaperSize":    {"A0", "A1", "A2", "A3", "A4", "A5", "A6", "A7", "A8", "A9", "B0", "B1", "B2", "B3", "B4", "B5", "B6", "B7", "B8", "B9", "B10", "C5E", "Comm10E", "DLE", "Executive", "Folio", "Ledger", "Legal", "Letter", "Tabloid"},
+	"Resolution":   {"screen", "printer", "high"},
+}
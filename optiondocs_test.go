@@ -0,0 +1,34 @@
+package pdf
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestOptionDocsSync(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not available")
+	}
+
+	output := filepath.Join(t.TempDir(), "optiondocs.go")
+	cmd := exec.Command(goBin, "run", "gen_optiondocs.go", "-o", output)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("could not run generator: %v\n%s", err, out)
+	}
+
+	expected, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := os.ReadFile("optiondocs.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(actual, expected) {
+		t.Error("optiondocs.go is out of date; run go generate")
+	}
+}
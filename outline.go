@@ -0,0 +1,312 @@
+package pdf
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+
+	"github.com/adrg/go-wkhtmltopdf/internal/pdfdoc"
+)
+
+// OutlineMode specifies how custom outlines are combined with the outlines
+// generated by the `wkhtmltox` library.
+type OutlineMode string
+
+// Outline mode values.
+const (
+	OutlineReplace OutlineMode = "replace"
+	OutlineAppend  OutlineMode = "append"
+	OutlinePrepend OutlineMode = "prepend"
+)
+
+// OutlineItem represents an item of the outline (bookmarks) of a document.
+type OutlineItem struct {
+	// The title of the outline item.
+	Title string `json:"title" yaml:"title"`
+
+	// The output document page the item points to. Page numbers start at 1
+	// and do not include the page offset specified in the converter options.
+	Page int `json:"page" yaml:"page"`
+
+	// The name of the anchor the item points to. When adding custom outline
+	// items, the link takes precedence over the page, if the document has a
+	// named destination with the specified name.
+	// E.g.: "__WKANCHOR_2".
+	Link string `json:"link,omitempty" yaml:"link,omitempty"`
+
+	// Specifies whether the nested items are displayed by default. The
+	// field is only used when adding custom outline items.
+	Open bool `json:"open,omitempty" yaml:"open,omitempty"`
+
+	// The nested outline items.
+	Children []OutlineItem `json:"children,omitempty" yaml:"children,omitempty"`
+}
+
+// outlineDumpItem represents an item of the XML outline representation
+// generated by the `wkhtmltox` library.
+type outlineDumpItem struct {
+	Title    string            `xml:"title,attr"`
+	Page     int               `xml:"page,attr"`
+	Link     string            `xml:"link,attr"`
+	BackLink string            `xml:"backLink,attr"`
+	Items    []outlineDumpItem `xml:"item"`
+}
+
+// readOutlineDump parses the XML outline representation written by the
+// `wkhtmltox` library at the specified path. The top-level items of the
+// outline correspond to the converted objects, in conversion order.
+func readOutlineDump(path string) ([]outlineDumpItem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var outline struct {
+		Items []outlineDumpItem `xml:"item"`
+	}
+	if err := xml.Unmarshal(data, &outline); err != nil {
+		return nil, err
+	}
+
+	return outline.Items, nil
+}
+
+// outlineItems converts the specified outline dump items to outline items,
+// adjusting the page numbers of the items by the specified page offset.
+func outlineItems(items []outlineDumpItem, pageOffset int64) []OutlineItem {
+	if len(items) == 0 {
+		return nil
+	}
+
+	outline := make([]OutlineItem, 0, len(items))
+	for _, item := range items {
+		outline = append(outline, OutlineItem{
+			Title:    item.Title,
+			Page:     item.Page - int(pageOffset),
+			Link:     item.Link,
+			Children: outlineItems(item.Items, pageOffset),
+		})
+	}
+
+	return outline
+}
+
+// injectOutline adds the specified outline items to the document, combining
+// them with the existing outline items based on the specified mode.
+func injectOutline(doc *pdfdoc.Document, items []OutlineItem, mode OutlineMode) error {
+	catalog, catalogRef, err := doc.Catalog()
+	if err != nil {
+		return err
+	}
+	pages, err := doc.Pages()
+	if err != nil {
+		return err
+	}
+
+	// Collect existing top-level outline items.
+	var existing []pdfdoc.Ref
+	visited := map[int]bool{}
+	oldRoot, _ := catalog["Outlines"].(pdfdoc.Ref)
+	if root := doc.Dict(oldRoot); root != nil {
+		visited[oldRoot.Num] = true
+		for ref, ok := root["First"].(pdfdoc.Ref); ok && !visited[ref.Num]; ref, ok = doc.Dict(ref)["Next"].(pdfdoc.Ref) {
+			visited[ref.Num] = true
+			existing = append(existing, ref)
+		}
+	}
+
+	switch mode {
+	case "", OutlineReplace:
+		for _, ref := range existing {
+			deleteOutlineItem(doc, ref, visited)
+		}
+		existing = nil
+	case OutlineAppend, OutlinePrepend:
+	default:
+		return fmt.Errorf("invalid outline mode: %q", string(mode))
+	}
+
+	// Create new outline items.
+	rootRef := oldRoot
+	if rootRef.Num == 0 {
+		rootRef = doc.Add(nil)
+	}
+
+	added, err := addOutlineItems(doc, catalog, pages, rootRef, items)
+	if err != nil {
+		return err
+	}
+
+	refs := make([]pdfdoc.Ref, 0, len(existing)+len(added))
+	if mode == OutlinePrepend {
+		refs = append(append(refs, added...), existing...)
+	} else {
+		refs = append(append(refs, existing...), added...)
+	}
+
+	// Link top-level outline items.
+	root := pdfdoc.Dict{"Type": pdfdoc.Name("Outlines")}
+	var count int64
+	for i, ref := range refs {
+		item := doc.Dict(ref).Clone()
+		item["Parent"] = rootRef
+		delete(item, "Prev")
+		delete(item, "Next")
+		if i > 0 {
+			item["Prev"] = refs[i-1]
+		}
+		if i < len(refs)-1 {
+			item["Next"] = refs[i+1]
+		}
+		doc.Set(ref, item)
+
+		count++
+		if n, _ := pdfdoc.Int(item["Count"]); n > 0 {
+			count += n
+		}
+	}
+	if len(refs) > 0 {
+		root["First"] = refs[0]
+		root["Last"] = refs[len(refs)-1]
+		root["Count"] = count
+	}
+	doc.Set(rootRef, root)
+
+	catalog = catalog.Clone()
+	catalog["Outlines"] = rootRef
+	if _, ok := catalog["PageMode"]; !ok && len(refs) > 0 {
+		catalog["PageMode"] = pdfdoc.Name("UseOutlines")
+	}
+	doc.Set(catalogRef, catalog)
+
+	return nil
+}
+
+// addOutlineItems adds the specified outline items to the document, as
+// children of the item with the specified reference. The function returns
+// the references of the added items. The items are linked to each other,
+// but the references of the parent item are not updated.
+func addOutlineItems(doc *pdfdoc.Document, catalog pdfdoc.Dict, pages []pdfdoc.Ref, parent pdfdoc.Ref, items []OutlineItem) ([]pdfdoc.Ref, error) {
+	refs := make([]pdfdoc.Ref, len(items))
+	for i := range items {
+		refs[i] = doc.Add(nil)
+	}
+
+	for i, item := range items {
+		dest, err := outlineDestination(doc, catalog, pages, item)
+		if err != nil {
+			return nil, err
+		}
+
+		d := pdfdoc.Dict{
+			"Title":  pdfdoc.EncodeText(item.Title),
+			"Parent": parent,
+			"Dest":   dest,
+		}
+		if i > 0 {
+			d["Prev"] = refs[i-1]
+		}
+		if i < len(items)-1 {
+			d["Next"] = refs[i+1]
+		}
+
+		if len(item.Children) > 0 {
+			children, err := addOutlineItems(doc, catalog, pages, refs[i], item.Children)
+			if err != nil {
+				return nil, err
+			}
+
+			d["First"] = children[0]
+			d["Last"] = children[len(children)-1]
+			if count := int64(visibleOutlineItems(item.Children)); item.Open {
+				d["Count"] = count
+			} else {
+				d["Count"] = -count
+			}
+		}
+
+		doc.Set(refs[i], d)
+	}
+
+	return refs, nil
+}
+
+// visibleOutlineItems returns the number of items which are visible when
+// the parent of the specified items is open.
+func visibleOutlineItems(items []OutlineItem) int {
+	count := len(items)
+	for _, item := range items {
+		if item.Open {
+			count += visibleOutlineItems(item.Children)
+		}
+	}
+
+	return count
+}
+
+// outlineDestination returns the destination of the specified outline item.
+func outlineDestination(doc *pdfdoc.Document, catalog pdfdoc.Dict, pages []pdfdoc.Ref, item OutlineItem) (pdfdoc.Object, error) {
+	if item.Link != "" {
+		// Named destinations stored in the Dests dictionary of the catalog
+		// are referenced by name, while the ones stored in the Dests name
+		// tree are referenced by string.
+		if dests := doc.Dict(catalog["Dests"]); dests != nil && dests[pdfdoc.Name(item.Link)] != nil {
+			return pdfdoc.Name(item.Link), nil
+		}
+		if names := doc.Dict(catalog["Names"]); names != nil && nameTreeContains(doc, names["Dests"], item.Link, 0) {
+			return pdfdoc.String(item.Link), nil
+		}
+		if item.Page == 0 {
+			return nil, fmt.Errorf("outline item %q: named destination %q not found", item.Title, item.Link)
+		}
+	}
+
+	if item.Page < 1 || item.Page > len(pages) {
+		return nil, fmt.Errorf("outline item %q: invalid page %d", item.Title, item.Page)
+	}
+
+	return pdfdoc.Array{pages[item.Page-1], pdfdoc.Name("Fit")}, nil
+}
+
+// nameTreeContains returns true if the name tree with the specified root
+// node contains the specified key.
+func nameTreeContains(doc *pdfdoc.Document, node pdfdoc.Object, key string, depth int) bool {
+	d := doc.Dict(node)
+	if d == nil || depth > 32 {
+		return false
+	}
+
+	names := doc.Array(d["Names"])
+	for i := 0; i+1 < len(names); i += 2 {
+		if name, ok := pdfdoc.Text(names[i]); ok && name == key {
+			return true
+		}
+	}
+	for _, kid := range doc.Array(d["Kids"]) {
+		if nameTreeContains(doc, kid, key, depth+1) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// deleteOutlineItem removes the outline item with the specified reference
+// and its descendants from the document. The visited set contains the items
+// which are already collected for deletion, so that cyclic outlines are not
+// walked indefinitely. It is shared by all the calls of a removal, and must
+// contain the specified item.
+func deleteOutlineItem(doc *pdfdoc.Document, ref pdfdoc.Ref, visited map[int]bool) {
+	var children []pdfdoc.Ref
+
+	item := doc.Dict(ref)
+	for child, ok := item["First"].(pdfdoc.Ref); ok && !visited[child.Num]; child, ok = doc.Dict(child)["Next"].(pdfdoc.Ref) {
+		visited[child.Num] = true
+		children = append(children, child)
+	}
+
+	for _, child := range children {
+		deleteOutlineItem(doc, child, visited)
+	}
+	doc.Delete(ref)
+}
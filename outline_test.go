@@ -0,0 +1,244 @@
+package pdf
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/adrg/go-wkhtmltopdf/internal/pdfdoc"
+)
+
+func TestInjectOutline(t *testing.T) {
+	items := []OutlineItem{
+		{Title: "Custom", Page: 2, Open: true, Children: []OutlineItem{{Title: "Anchor", Link: "anchor"}}},
+		{Title: "Closed", Page: 3, Children: []OutlineItem{{Title: "Hidden", Page: 1, Link: "missing"}}},
+	}
+	custom := []string{
+		"Custom (page 2) count 1",
+		"  Anchor (anchor)",
+		"Closed (page 3) count -1",
+		"  Hidden (page 1)",
+	}
+	generated := []string{
+		"Generated 1 (page 1) count 1",
+		"  Generated 1.1 (page 1)",
+		"Generated 2 (page 2)",
+	}
+
+	tests := []struct {
+		name     string
+		mode     OutlineMode
+		expected []string
+		count    int64
+		deleted  bool
+	}{
+		{name: "default", expected: custom, count: 3, deleted: true},
+		{name: "replace", mode: OutlineReplace, expected: custom, count: 3, deleted: true},
+		{name: "append", mode: OutlineAppend, expected: append(append([]string{}, generated...), custom...), count: 6},
+		{name: "prepend", mode: OutlinePrepend, expected: append(append([]string{}, custom...), generated...), count: 6},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc := outlineDocument(t)
+			if err := injectOutline(doc, items, test.mode); err != nil {
+				t.Fatal(err)
+			}
+
+			outline, count := readOutline(t, doc)
+			if !reflect.DeepEqual(outline, test.expected) {
+				t.Errorf("expected outline:\n%s\ngot:\n%s", strings.Join(test.expected, "\n"), strings.Join(outline, "\n"))
+			}
+			if count != test.count {
+				t.Errorf("expected outline count %d, got %d", test.count, count)
+			}
+
+			for _, num := range []int{7, 8, 9} {
+				if deleted := doc.Dict(pdfdoc.Ref{Num: num}) == nil; deleted != test.deleted {
+					t.Errorf("expected generated item %d deleted=%t, got %t", num, test.deleted, deleted)
+				}
+			}
+
+			catalog, _, err := doc.Catalog()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if mode := catalog.Name("PageMode"); mode != "UseOutlines" {
+				t.Errorf("expected page mode UseOutlines, got %q", mode)
+			}
+		})
+	}
+}
+
+func TestInjectOutlineWithoutOutline(t *testing.T) {
+	doc := outlineDocument(t)
+	catalog, catalogRef, err := doc.Catalog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	catalog = catalog.Clone()
+	delete(catalog, "Outlines")
+	doc.Set(catalogRef, catalog)
+
+	if err := injectOutline(doc, []OutlineItem{{Title: "Only", Page: 1}}, OutlineAppend); err != nil {
+		t.Fatal(err)
+	}
+	if outline, count := readOutline(t, doc); !reflect.DeepEqual(outline, []string{"Only (page 1)"}) || count != 1 {
+		t.Errorf("unexpected outline %q with count %d", outline, count)
+	}
+}
+
+func TestInjectOutlineCyclic(t *testing.T) {
+	doc := outlineDocument(t)
+
+	// The nested item points back to its parent and to itself.
+	item := doc.Dict(pdfdoc.Ref{Num: 9}).Clone()
+	item["First"] = pdfdoc.Ref{Num: 7}
+	item["Next"] = pdfdoc.Ref{Num: 9}
+	doc.Set(pdfdoc.Ref{Num: 9}, item)
+
+	if err := injectOutline(doc, []OutlineItem{{Title: "Custom", Page: 1}}, OutlineReplace); err != nil {
+		t.Fatal(err)
+	}
+	for _, num := range []int{7, 8, 9} {
+		if doc.Dict(pdfdoc.Ref{Num: num}) != nil {
+			t.Errorf("expected generated item %d to be deleted", num)
+		}
+	}
+	if outline, _ := readOutline(t, doc); !reflect.DeepEqual(outline, []string{"Custom (page 1)"}) {
+		t.Errorf("unexpected outline %q", outline)
+	}
+}
+
+func TestInjectOutlineErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		items    []OutlineItem
+		mode     OutlineMode
+		expected string
+	}{
+		{
+			name:     "invalid mode",
+			items:    []OutlineItem{{Title: "Item", Page: 1}},
+			mode:     "merge",
+			expected: `invalid outline mode: "merge"`,
+		},
+		{
+			name:     "invalid page",
+			items:    []OutlineItem{{Title: "Item", Page: 4}},
+			expected: `outline item "Item": invalid page 4`,
+		},
+		{
+			name:     "missing page",
+			items:    []OutlineItem{{Title: "Parent", Page: 1, Children: []OutlineItem{{Title: "Child"}}}},
+			expected: `outline item "Child": invalid page 0`,
+		},
+		{
+			name:     "missing named destination",
+			items:    []OutlineItem{{Title: "Item", Link: "missing"}},
+			expected: `outline item "Item": named destination "missing" not found`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := injectOutline(outlineDocument(t), test.items, test.mode)
+			if err == nil || err.Error() != test.expected {
+				t.Errorf("expected error %q, got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+// outlineDocument returns a document with three pages, a generated outline
+// and a named destination.
+func outlineDocument(t *testing.T) *pdfdoc.Document {
+	t.Helper()
+
+	doc, err := pdfdoc.Parse(testDocument([]string{
+		// 1-2: catalog and page tree.
+		"<< /Type /Catalog /Pages 2 0 R /Outlines 6 0 R /Names << /Dests 10 0 R >> >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R 5 0 R] /Count 3 >>",
+		// 3-5: pages.
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] >>",
+		// 6-9: generated outline.
+		"<< /Type /Outlines /First 7 0 R /Last 8 0 R /Count 3 >>",
+		"<< /Title (Generated 1) /Parent 6 0 R /Next 8 0 R /First 9 0 R /Last 9 0 R /Count 1 /Dest [3 0 R /Fit] >>",
+		"<< /Title (Generated 2) /Parent 6 0 R /Prev 7 0 R /Dest [4 0 R /Fit] >>",
+		"<< /Title (Generated 1.1) /Parent 7 0 R /Dest [3 0 R /Fit] >>",
+		// 10: named destinations.
+		"<< /Names [(anchor) [5 0 R /Fit]] >>",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return doc
+}
+
+// readOutline returns a textual representation of the outline items of the
+// document, along with the count of the outline root. The links between the
+// items are checked while walking the outline.
+func readOutline(t *testing.T, doc *pdfdoc.Document) ([]string, int64) {
+	t.Helper()
+
+	catalog, _, err := doc.Catalog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages, err := doc.Pages()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lines []string
+	var walk func(parent pdfdoc.Ref, level int)
+	walk = func(parent pdfdoc.Ref, level int) {
+		var prev pdfdoc.Ref
+		last, _ := doc.Dict(parent)["Last"].(pdfdoc.Ref)
+		for ref, ok := doc.Dict(parent)["First"].(pdfdoc.Ref); ok; ref, ok = doc.Dict(ref)["Next"].(pdfdoc.Ref) {
+			item := doc.Dict(ref)
+			if item == nil || len(lines) > 100 {
+				t.Fatalf("invalid outline item %s", ref)
+			}
+			if p, _ := item["Parent"].(pdfdoc.Ref); p != parent {
+				t.Errorf("item %s: expected parent %s, got %s", ref, parent, p)
+			}
+			if p, _ := item["Prev"].(pdfdoc.Ref); p != prev {
+				t.Errorf("item %s: expected previous item %s, got %s", ref, prev, p)
+			}
+			if _, ok := item["Next"]; !ok && ref != last {
+				t.Errorf("item %s: expected last item %s", ref, last)
+			}
+
+			title, _ := item.Text("Title")
+			line := strings.Repeat("  ", level) + pdfdoc.DecodeText(title)
+			switch dest := item["Dest"].(type) {
+			case pdfdoc.Array:
+				for i, page := range pages {
+					if page == dest[0] {
+						line += fmt.Sprintf(" (page %d)", i+1)
+					}
+				}
+			case pdfdoc.String:
+				line += fmt.Sprintf(" (%s)", string(dest))
+			}
+			if count, ok := pdfdoc.Int(item["Count"]); ok {
+				line += fmt.Sprintf(" count %d", count)
+			}
+			lines = append(lines, line)
+
+			walk(ref, level+1)
+			prev = ref
+		}
+	}
+
+	root, _ := catalog["Outlines"].(pdfdoc.Ref)
+	walk(root, 0)
+
+	count, _ := pdfdoc.Int(doc.Dict(root)["Count"])
+	return lines, count
+}
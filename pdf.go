@@ -46,7 +46,7 @@ Example
 		if err != nil {
 			log.Fatal(err)
 		}
-		object3.Zoom = 1.5
+		object3.Zoom = pdf.Float64(1.5)
 		object3.TOC.Title = "Table of Contents"
 
 		// Create converter.
@@ -57,9 +57,11 @@ Example
 		defer converter.Destroy()
 
 		// Add created objects to the converter.
-		converter.Add(object)
-		converter.Add(object2)
-		converter.Add(object3)
+		for _, o := range []*pdf.Object{object, object2, object3} {
+			if err := converter.Add(o); err != nil {
+				log.Fatal(err)
+			}
+		}
 
 		// Set converter options.
 		converter.Title = "Sample document"
@@ -94,17 +96,38 @@ package pdf
 #include <wkhtmltox/pdf.h>
 */
 import "C"
-import "errors"
+import (
+	"context"
+	"errors"
+)
 
-var registry *objectRegistry
+var registry = newObjectRegistry()
 
 // Init initializes the library, allocating all necessary resources.
+// The library is reference counted, so Init can be called multiple times,
+// each call having to be matched by a call to Destroy or Release. The
+// library cannot be initialized again after it is destroyed. Due to a
+// limitation of the `wkhtmltox` library, this function must be called on
+// the main thread.
 func Init() error {
+	lib.Lock()
+	defer lib.Unlock()
+
+	switch {
+	case lib.closing:
+		return ErrBusy
+	case lib.deinitialized:
+		return errors.New("library cannot be initialized after being destroyed")
+	case lib.refs > 0:
+		lib.refs++
+		return nil
+	}
+
 	if C.wkhtmltopdf_init(0) != 1 {
 		return errors.New("could not initialize library")
 	}
+	lib.refs = 1
 
-	registry = newObjectRegistry()
 	return nil
 }
 
@@ -119,8 +142,132 @@ func HasPatchedQT() bool {
 	return C.wkhtmltopdf_extended_qt() != 0
 }
 
-// Destroy releases all the resources used by the library.
+// Destroy releases a reference to the library, acquired by Init. When the
+// last reference is released, all the resources used by the library are
+// released. Errors are ignored, so the reference is not released if there
+// are converters or objects which have not been destroyed, or conversions in
+// progress. Use Release in order to be notified of such cases. Due to a
+// limitation of the `wkhtmltox` library, this function must be called on
+// the main thread.
 func Destroy() {
-	registry = nil
+	Release() // nolint:errcheck
+}
+
+// Release releases a reference to the library, acquired by Init, and
+// reports the errors ignored by Destroy. When the last reference is
+// released, all the resources used by the library are released. In that
+// case, ErrBusy is returned if there are converters or objects which have
+// not been destroyed, or conversions in progress, and the reference is not
+// released. Due to a limitation of the `wkhtmltox` library, this function
+// must be called on the main thread.
+func Release() error {
+	lib.Lock()
+	defer lib.Unlock()
+
+	if !lib.initialized() {
+		return ErrNotInitialized
+	}
+	if lib.refs > 1 {
+		lib.refs--
+		return nil
+	}
+	if lib.busy() {
+		return ErrBusy
+	}
+
+	deinit()
+	return nil
+}
+
+// Shutdown releases all the resources used by the library, regardless of
+// the number of references acquired by Init. New converters, objects and
+// conversions are rejected with ErrNotInitialized, and Shutdown waits for
+// the conversions in progress to finish. The remaining converters are then
+// destroyed, along with the objects added to them. ErrBusy is returned if
+// any of the converters is still in use by another goroutine (e.g. adding
+// objects or running a conversion), or if there are objects which are not
+// added to converters and have not been destroyed. If the context is done
+// before the conversions in progress finish, the context error is returned.
+// In both cases, the library remains initialized. Due to a limitation of
+// the `wkhtmltox` library, the library has to be destroyed on the main
+// thread, so this function should be called on the main thread, once the
+// goroutines dispatching conversions to it are notified to stop.
+func Shutdown(ctx context.Context) error {
+	lib.Lock()
+	if !lib.initialized() {
+		lib.Unlock()
+		return ErrNotInitialized
+	}
+	lib.closing = true
+
+	// Wait for the conversions in progress to finish.
+	for lib.running > 0 {
+		idle := lib.idle
+		lib.Unlock()
+
+		select {
+		case <-idle:
+		case <-ctx.Done():
+			lib.Lock()
+			lib.closing = false
+			lib.Unlock()
+			return ctx.Err()
+		}
+
+		lib.Lock()
+	}
+
+	// Acquire the remaining converters, so that they cannot be used while
+	// they are destroyed. Converters which are in use are not destroyed.
+	var converters []*converterHandle
+	release := func() {
+		for _, converter := range converters {
+			converter.mu.Unlock()
+		}
+	}
+
+	objects := 0
+	for _, object := range registry.all() {
+		converter, ok := object.(*converterHandle)
+		if !ok {
+			continue
+		}
+		if !converter.mu.TryLock() {
+			release()
+			lib.closing = false
+			lib.Unlock()
+			return ErrBusy
+		}
+		converters = append(converters, converter)
+		objects += len(converter.objects)
+	}
+
+	// Objects which are not added to converters are not tracked, so they
+	// cannot be destroyed by Shutdown.
+	if lib.objects > objects {
+		release()
+		lib.closing = false
+		lib.Unlock()
+		return ErrBusy
+	}
+	lib.Unlock()
+
+	// Destroy remaining converters.
+	for _, converter := range converters {
+		converter.destroy()
+		converter.events.close()
+	}
+	release()
+
+	lib.Lock()
+	deinit()
+	lib.Unlock()
+
+	return nil
+}
+
+// deinit deinitializes the library. The caller must hold the lifecycle lock.
+func deinit() {
 	C.wkhtmltopdf_deinit()
+	lib.refs, lib.closing, lib.deinitialized = 0, false, true
 }
@@ -0,0 +1,358 @@
+/*
+Package pdftest provides utilities for testing generated PDF documents.
+
+The package can be used to extract the text, the links, the annotations and
+the outline of PDF documents, and to compare documents against golden files.
+The golden files are stored in the testdata directory of the tested package
+and can be updated by running the tests using the -update-golden flag.
+
+The tests which require the `wkhtmltox` library can be skipped using
+SkipIfNoLib. As the test binaries are linked against the library when cgo
+is enabled, the tests are skipped only if the PDFTEST_SKIP_LIB environment
+variable is set, or if cgo is disabled.
+
+Example
+
+	func TestInvoice(t *testing.T) {
+		pdftest.SkipIfNoLib(t)
+
+		data := renderInvoice(t)
+
+		doc, err := pdftest.Parse(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count := doc.PageCount(); count != 2 {
+			t.Errorf("expected 2 pages, got %d", count)
+		}
+
+		// Compare the content of the document with testdata/invoice.golden.
+		pdftest.AssertGolden(t, "invoice.golden", data)
+	}
+*/
+package pdftest
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/adrg/go-wkhtmltopdf/internal/pdfdoc"
+)
+
+// SkipLibEnv is the name of the environment variable which can be set in
+// order to skip the tests which require the `wkhtmltox` library.
+const SkipLibEnv = "PDFTEST_SKIP_LIB"
+
+// Link represents a link annotation of a document.
+type Link struct {
+	// The page containing the link. Page numbers start at 1.
+	Page int `json:"page" yaml:"page"`
+
+	// The area of the page covered by the link, specified as the lower-left
+	// and the upper-right corners of a rectangle, in default user space units.
+	Rect [4]float64 `json:"rect" yaml:"rect"`
+
+	// The target URI of the link, for links to external resources.
+	// E.g.: "https://example.com".
+	URI string `json:"uri,omitempty" yaml:"uri,omitempty"`
+
+	// The target page of the link, for links to locations inside the
+	// document. The value is 0 for external links and for links whose
+	// target cannot be determined.
+	DestPage int `json:"destPage,omitempty" yaml:"destPage,omitempty"`
+}
+
+// Annotation represents an annotation of a document.
+type Annotation struct {
+	// The page containing the annotation. Page numbers start at 1.
+	Page int `json:"page" yaml:"page"`
+
+	// The type of the annotation.
+	// E.g.: "Link".
+	Subtype string `json:"subtype" yaml:"subtype"`
+
+	// The area of the page covered by the annotation, specified as the
+	// lower-left and the upper-right corners of a rectangle, in default
+	// user space units.
+	Rect [4]float64 `json:"rect" yaml:"rect"`
+
+	// The text of the annotation, if any.
+	Contents string `json:"contents,omitempty" yaml:"contents,omitempty"`
+}
+
+// OutlineEntry represents an entry of the outline of a document.
+type OutlineEntry struct {
+	// The title of the entry.
+	Title string `json:"title" yaml:"title"`
+
+	// The depth of the entry in the outline hierarchy. Top-level entries
+	// have level 0.
+	Level int `json:"level" yaml:"level"`
+
+	// The target page of the entry. The value is 0 if the target of the
+	// entry cannot be determined.
+	Page int `json:"page" yaml:"page"`
+}
+
+// Document represents a parsed PDF document.
+type Document struct {
+	doc     *pdfdoc.Document
+	catalog pdfdoc.Dict
+	pages   []pdfdoc.Ref
+}
+
+// Parse parses the specified PDF document data.
+func Parse(data []byte) (*Document, error) {
+	doc, err := pdfdoc.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog, _, err := doc.Catalog()
+	if err != nil {
+		return nil, err
+	}
+	pages, err := doc.Pages()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Document{
+		doc:     doc,
+		catalog: catalog,
+		pages:   pages,
+	}, nil
+}
+
+// ReadFile parses the PDF document at the specified path.
+func ReadFile(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(data)
+}
+
+// PageCount returns the number of pages of the document.
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+// Title returns the title of the document, from the document information
+// dictionary.
+func (d *Document) Title() string {
+	info := d.doc.Info()
+	if info == nil {
+		return ""
+	}
+
+	title, _ := info.Text("Title")
+	return pdfdoc.DecodeText(title)
+}
+
+// Text returns the text of the specified page. Page numbers start at 1.
+// Text lines are separated by newlines and words are separated by spaces.
+func (d *Document) Text(page int) (string, error) {
+	if page < 1 || page > len(d.pages) {
+		return "", fmt.Errorf("invalid page number %d: the document has %d pages", page, len(d.pages))
+	}
+
+	return extractText(d.doc, d.doc.Dict(d.pages[page-1]))
+}
+
+// Texts returns the text of all the pages of the document, in page order.
+func (d *Document) Texts() ([]string, error) {
+	texts := make([]string, 0, len(d.pages))
+	for page := 1; page <= len(d.pages); page++ {
+		text, err := d.Text(page)
+		if err != nil {
+			return nil, err
+		}
+		texts = append(texts, text)
+	}
+
+	return texts, nil
+}
+
+// Annotations returns the annotations of the document, in page order.
+func (d *Document) Annotations() []Annotation {
+	var annotations []Annotation
+	d.visitAnnotations(func(page int, annot pdfdoc.Dict) {
+		contents, _ := annot.Text("Contents")
+		annotations = append(annotations, Annotation{
+			Page:     page,
+			Subtype:  string(annot.Name("Subtype")),
+			Rect:     d.rect(annot["Rect"]),
+			Contents: pdfdoc.DecodeText(contents),
+		})
+	})
+
+	return annotations
+}
+
+// Links returns the link annotations of the document, in page order.
+func (d *Document) Links() []Link {
+	var links []Link
+	d.visitAnnotations(func(page int, annot pdfdoc.Dict) {
+		if annot.Name("Subtype") != "Link" {
+			return
+		}
+
+		link := Link{Page: page, Rect: d.rect(annot["Rect"])}
+		if action := d.doc.Dict(annot["A"]); action != nil {
+			switch action.Name("S") {
+			case "URI":
+				uri, _ := action.Text("URI")
+				link.URI = uri
+			case "GoTo":
+				link.DestPage = d.destPage(action["D"])
+			}
+		} else {
+			link.DestPage = d.destPage(annot["Dest"])
+		}
+
+		links = append(links, link)
+	})
+
+	return links
+}
+
+// Outline returns the entries of the document outline, in display order.
+func (d *Document) Outline() []OutlineEntry {
+	var (
+		entries []OutlineEntry
+		visited = map[pdfdoc.Ref]bool{}
+	)
+
+	var walk func(first pdfdoc.Object, level int)
+	walk = func(first pdfdoc.Object, level int) {
+		for next := first; next != nil; {
+			ref, ok := next.(pdfdoc.Ref)
+			if !ok || visited[ref] {
+				return
+			}
+			visited[ref] = true
+
+			item := d.doc.Dict(ref)
+			if item == nil {
+				return
+			}
+
+			title, _ := item.Text("Title")
+			entry := OutlineEntry{
+				Title: pdfdoc.DecodeText(title),
+				Level: level,
+				Page:  d.destPage(item["Dest"]),
+			}
+			if action := d.doc.Dict(item["A"]); action != nil && action.Name("S") == "GoTo" {
+				entry.Page = d.destPage(action["D"])
+			}
+			entries = append(entries, entry)
+
+			walk(item["First"], level+1)
+			next = item["Next"]
+		}
+	}
+
+	if outlines := d.doc.Dict(d.catalog["Outlines"]); outlines != nil {
+		walk(outlines["First"], 0)
+	}
+
+	return entries
+}
+
+func (d *Document) visitAnnotations(fn func(page int, annot pdfdoc.Dict)) {
+	for i, ref := range d.pages {
+		page := d.doc.Dict(ref)
+		if page == nil {
+			continue
+		}
+
+		for _, o := range d.doc.Array(page["Annots"]) {
+			if annot := d.doc.Dict(o); annot != nil {
+				fn(i+1, annot)
+			}
+		}
+	}
+}
+
+// destPage returns the page number of the specified destination, which can
+// be an explicit destination array or a named destination.
+func (d *Document) destPage(dest pdfdoc.Object) int {
+	dest = d.doc.Resolve(dest)
+
+	// Resolve named destinations.
+	if name, ok := dest.(pdfdoc.Name); ok {
+		dest = d.namedDest(string(name))
+	} else if name, ok := pdfdoc.Text(dest); ok {
+		dest = d.namedDest(name)
+	}
+
+	// Destinations can be specified as dictionaries with a D entry.
+	if dict := d.doc.Dict(dest); dict != nil {
+		dest = dict["D"]
+	}
+
+	arr := d.doc.Array(dest)
+	if len(arr) == 0 {
+		return 0
+	}
+	if ref, ok := arr[0].(pdfdoc.Ref); ok {
+		for i, page := range d.pages {
+			if page == ref {
+				return i + 1
+			}
+		}
+	}
+
+	return 0
+}
+
+// namedDest returns the destination with the specified name, from either
+// the Dests dictionary of the catalog or the Dests name tree.
+func (d *Document) namedDest(name string) pdfdoc.Object {
+	if dests := d.doc.Dict(d.catalog["Dests"]); dests != nil {
+		if dest, ok := dests[pdfdoc.Name(name)]; ok {
+			return d.doc.Resolve(dest)
+		}
+	}
+	if names := d.doc.Dict(d.catalog["Names"]); names != nil {
+		return d.nameTreeLookup(names["Dests"], name, 0)
+	}
+
+	return nil
+}
+
+func (d *Document) nameTreeLookup(node pdfdoc.Object, key string, depth int) pdfdoc.Object {
+	dict := d.doc.Dict(node)
+	if dict == nil || depth > 32 {
+		return nil
+	}
+
+	names := d.doc.Array(dict["Names"])
+	for i := 0; i+1 < len(names); i += 2 {
+		if name, ok := pdfdoc.Text(names[i]); ok && name == key {
+			return d.doc.Resolve(names[i+1])
+		}
+	}
+	for _, kid := range d.doc.Array(dict["Kids"]) {
+		if dest := d.nameTreeLookup(kid, key, depth+1); dest != nil {
+			return dest
+		}
+	}
+
+	return nil
+}
+
+func (d *Document) rect(o pdfdoc.Object) [4]float64 {
+	var rect [4]float64
+	for i, v := range d.doc.Array(o) {
+		if i >= len(rect) {
+			break
+		}
+		rect[i], _ = pdfdoc.Float(d.doc.Resolve(v))
+	}
+
+	return rect
+}
@@ -0,0 +1,232 @@
+package pdftest
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/adrg/go-wkhtmltopdf/internal/pdfdoc"
+)
+
+func TestDocument(t *testing.T) {
+	doc, err := Parse(testPDF())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count := doc.PageCount(); count != 2 {
+		t.Errorf("expected 2 pages, got %d", count)
+	}
+	if title := doc.Title(); title != "Test document" {
+		t.Errorf("expected title %q, got %q", "Test document", title)
+	}
+
+	texts, err := doc.Texts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"Hello world\nSecond line", "Hi\nBye"}; !reflect.DeepEqual(texts, expected) {
+		t.Errorf("expected texts %q, got %q", expected, texts)
+	}
+	for _, page := range []int{0, 3} {
+		if _, err := doc.Text(page); err == nil {
+			t.Errorf("expected error for page %d", page)
+		}
+	}
+
+	expectedLinks := []Link{
+		{Page: 1, Rect: [4]float64{72, 760, 150, 780}, URI: "https://example.com"},
+		{Page: 1, Rect: [4]float64{72, 740, 150, 755}, DestPage: 2},
+	}
+	if links := doc.Links(); !reflect.DeepEqual(links, expectedLinks) {
+		t.Errorf("expected links %+v, got %+v", expectedLinks, links)
+	}
+
+	expectedAnnotations := []Annotation{
+		{Page: 1, Subtype: "Link", Rect: [4]float64{72, 760, 150, 780}},
+		{Page: 1, Subtype: "Link", Rect: [4]float64{72, 740, 150, 755}},
+		{Page: 1, Subtype: "Text", Rect: [4]float64{0, 0, 20, 20}, Contents: "Note"},
+	}
+	if annotations := doc.Annotations(); !reflect.DeepEqual(annotations, expectedAnnotations) {
+		t.Errorf("expected annotations %+v, got %+v", expectedAnnotations, annotations)
+	}
+
+	expectedOutline := []OutlineEntry{
+		{Title: "Intro", Level: 0, Page: 1},
+		{Title: "Overview", Level: 1, Page: 2},
+		{Title: "Details", Level: 0, Page: 2},
+	}
+	if outline := doc.Outline(); !reflect.DeepEqual(outline, expectedOutline) {
+		t.Errorf("expected outline %+v, got %+v", expectedOutline, outline)
+	}
+}
+
+func TestTextExtraction(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "words",
+			content:  "BT /F1 12 Tf 72 770 Td (Hello) Tj 30 0 Td (world) Tj ET",
+			expected: "Hello world",
+		},
+		{
+			name:     "kerning",
+			content:  "BT /F1 12 Tf 72 770 Td [(Ker) -20 (ning) -2000 (word)] TJ ET",
+			expected: "Kerning word",
+		},
+		{
+			name:     "lines",
+			content:  "BT /F1 10 Tf 12 TL 72 770 Td (One) Tj T* (Two) Tj (Three) ' ET",
+			expected: "One\nTwo\nThree",
+		},
+		{
+			name:     "text matrix",
+			content:  "BT /F1 1 Tf 12 0 0 12 72 770 Tm (Top) Tj 1 0 0 1 72 700 Tm (Bottom) Tj ET",
+			expected: "Top\nBottom",
+		},
+		{
+			name:     "escaped",
+			content:  `BT /F1 12 Tf 72 770 Td (\(a\) \\ b) Tj ET`,
+			expected: `(a) \ b`,
+		},
+		{
+			name:    "empty",
+			content: "0 0 m 10 10 l S",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := Parse(buildPDF([]string{
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+				"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R >>",
+				stream(test.content),
+				"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+				"<< >>",
+			}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			text, err := doc.Text(1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if text != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, text)
+			}
+		})
+	}
+}
+
+func TestParseFake(t *testing.T) {
+	doc, err := Parse(minimalPDF("Fake (output)", true, []string{"First", "Second"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if title := doc.Title(); title != "Fake (output)" {
+		t.Errorf("expected title %q, got %q", "Fake (output)", title)
+	}
+	texts, err := doc.Texts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"First", "Second"}; !reflect.DeepEqual(texts, expected) {
+		t.Errorf("expected texts %q, got %q", expected, texts)
+	}
+
+	// The lengths of the content streams must match their data.
+	for _, ref := range doc.pages {
+		stream, ok := doc.doc.Resolve(doc.doc.Dict(ref)["Contents"]).(*pdfdoc.Stream)
+		if !ok {
+			t.Fatalf("missing content stream for page %s", ref)
+		}
+		if !bytes.HasSuffix(stream.Data, []byte("ET")) {
+			t.Errorf("invalid content stream length for page %s: %q", ref, stream.Data)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	if _, err := Parse([]byte("not a document")); err == nil {
+		t.Error("expected error for invalid document")
+	}
+	if _, err := ReadFile("testdata/missing.pdf"); err == nil {
+		t.Error("expected error for missing document")
+	}
+}
+
+// testPDF returns a document containing two pages, with text shown using
+// simple and composite fonts, annotations, an outline and named
+// destinations.
+func testPDF() []byte {
+	return buildPDF([]string{
+		// 1: catalog.
+		"<< /Type /Catalog /Pages 2 0 R /Outlines 9 0 R /Names << /Dests 12 0 R >> >>",
+		// 2: page tree, with inherited resources.
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /MediaBox [0 0 595 842] " +
+			"/Resources << /Font << /F1 5 0 R /F2 6 0 R >> >> >>",
+		// 3-4: pages.
+		"<< /Type /Page /Parent 2 0 R /Contents 7 0 R /Annots [13 0 R 14 0 R 15 0 R] >>",
+		"<< /Type /Page /Parent 2 0 R /Contents [8 0 R 16 0 R] >>",
+		// 5: simple font.
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		// 6: composite font.
+		"<< /Type /Font /Subtype /Type0 /BaseFont /Test /Encoding /Identity-H " +
+			"/DescendantFonts [<< /Type /Font /Subtype /CIDFontType2 /DW 500 >>] /ToUnicode 17 0 R >>",
+		// 7-8: content streams.
+		stream("BT /F1 12 Tf 72 770 Td (Hello) Tj 50 0 Td (world) Tj -50 -14 Td [(Sec) -10 (ond)] TJ 30 0 Td (line) Tj ET"),
+		stream("BT /F2 10 Tf 72 770 Td <00010002> Tj ET"),
+		// 9-11: outline.
+		"<< /Type /Outlines /First 10 0 R /Last 11 0 R /Count 2 >>",
+		"<< /Title (Intro) /Parent 9 0 R /Next 11 0 R /First 18 0 R /Last 18 0 R /Dest [3 0 R /Fit] >>",
+		"<< /Title <FEFF00440065007400610069006C0073> /Parent 9 0 R /Prev 10 0 R /A << /S /GoTo /D (details) >> >>",
+		// 12: destinations name tree.
+		"<< /Names [(details) [4 0 R /Fit]] >>",
+		// 13-15: annotations.
+		"<< /Type /Annot /Subtype /Link /Rect [72 760 150 780] /A << /S /URI /URI (https://example.com) >> >>",
+		"<< /Type /Annot /Subtype /Link /Rect [72 740 150 755] /Dest /details >>",
+		"<< /Type /Annot /Subtype /Text /Rect [0 0 20 20] /Contents (Note) >>",
+		// 16: second content stream of the second page.
+		stream("BT /F1 10 Tf 1 0 0 1 72 700 Tm (Bye) Tj ET"),
+		// 17: ToUnicode CMap of the composite font.
+		stream("begincmap\n2 beginbfchar\n<0001> <0048>\n<0002> <0069>\nendbfchar\nendcmap"),
+		// 18: nested outline item.
+		"<< /Title (Overview) /Parent 10 0 R /Dest [4 0 R /XYZ 0 0 0] >>",
+		// 19: document information.
+		"<< /Title (Test document) >>",
+	})
+}
+
+// buildPDF returns a document containing the specified objects. The first
+// object is the catalog and the last one is the document information
+// dictionary.
+func buildPDF(objects []string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f\r\n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n\r\n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, len(objects), xref)
+
+	return buf.Bytes()
+}
+
+func stream(content string) string {
+	return fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content)
+}
@@ -0,0 +1,288 @@
+package pdftest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	pdf "github.com/adrg/go-wkhtmltopdf"
+)
+
+// FakePhases contains the conversion phases reported by FakeRenderer,
+// unless other phases are specified.
+var FakePhases = []string{
+	"Loading pages",
+	"Counting pages",
+	"Resolving links",
+	"Loading headers and footers",
+	"Printing pages",
+	"Done",
+}
+
+// FakeRenderer is an in-memory pdf.Renderer implementation, which can be
+// used for testing code which depends on pdf.Renderer without performing
+// actual conversions. The renderer records the options of the conversion,
+// calls the conversion callbacks according to its script and produces a
+// minimal PDF document, containing a page for each added object.
+//
+// The renderer does not use the `wkhtmltox` library, but the objects added
+// to it are created using the object constructors of the pdf package. When
+// cgo is enabled, the constructors require the library to be initialized,
+// so tests must call pdf.Init before creating objects. When cgo is disabled,
+// the library is not used and pdf.Init is not available.
+type FakeRenderer struct {
+	*pdf.ConverterOpts
+
+	// The descriptions of the conversion phases. If not specified,
+	// FakePhases is used.
+	Phases []string
+
+	// The progress values reported for each conversion phase, in percents.
+	// If not specified, the progress is reported as 0, 50 and 100.
+	// E.g.: []int{0, 100}.
+	ProgressSteps []int
+
+	// The warnings issued during the first conversion phase.
+	Warnings []string
+
+	// The errors issued during the first conversion phase. If specified,
+	// the conversion fails.
+	Errors []string
+
+	// The error returned by Run. If specified, the conversion fails.
+	Err error
+
+	// The output document written by Run. If not specified, a minimal PDF
+	// document is generated, containing a page for each added object, with
+	// the text "Object N", where N is the position of the object.
+	Output []byte
+
+	callbacks pdf.Callbacks
+	objects   []*pdf.Object
+	ran       bool
+	destroyed bool
+}
+
+// NewFakeRenderer returns a new fake renderer, configured using the specified
+// options. If no options are provided, the defaults are used. See
+// pdf.NewConverterOpts for the default options.
+func NewFakeRenderer(opts *pdf.ConverterOpts) *FakeRenderer {
+	if opts == nil {
+		opts = pdf.NewConverterOpts()
+	}
+
+	return &FakeRenderer{ConverterOpts: opts}
+}
+
+// Add appends the specified object to the list of objects to be converted.
+func (r *FakeRenderer) Add(object *pdf.Object) error {
+	if r.destroyed {
+		return errors.New("cannot use destroyed renderer")
+	}
+	if object == nil {
+		return errors.New("the provided object cannot be nil")
+	}
+	if r.ran {
+		return pdf.ErrAlreadyRun
+	}
+	for _, o := range r.objects {
+		if o == object {
+			return errors.New("object already added to the renderer")
+		}
+	}
+
+	r.objects = append(r.objects, object)
+	return nil
+}
+
+// Run simulates the conversion and copies the output document to the
+// provided writer. The callbacks of the renderer are called synchronously.
+func (r *FakeRenderer) Run(w io.Writer) error {
+	if r.destroyed {
+		return errors.New("cannot use destroyed renderer")
+	}
+	if w == nil {
+		return errors.New("the provided writer cannot be nil")
+	}
+	if r.ran {
+		return pdf.ErrAlreadyRun
+	}
+	if len(r.objects) == 0 {
+		return errors.New("must add at least one object to convert")
+	}
+	r.ran = true
+
+	phases := r.Phases
+	if len(phases) == 0 {
+		phases = FakePhases
+	}
+	steps := r.ProgressSteps
+	if len(steps) == 0 {
+		steps = []int{0, 50, 100}
+	}
+
+	cb := r.callbacks
+	for i, phase := range phases {
+		if cb.PhaseChanged != nil {
+			cb.PhaseChanged(i)
+		}
+		if i == 0 {
+			for _, msg := range r.Warnings {
+				if cb.Warning != nil {
+					cb.Warning(msg)
+				}
+			}
+			for _, msg := range r.Errors {
+				if cb.Error != nil {
+					cb.Error(msg)
+				}
+			}
+		}
+
+		for _, step := range steps {
+			if cb.ProgressChanged != nil {
+				cb.ProgressChanged(step)
+			}
+			if cb.OverallProgressChanged != nil {
+				cb.OverallProgressChanged(pdf.Progress{
+					Percent:          (float64(i) + float64(step)/100) / float64(len(phases)) * 100,
+					PhaseIndex:       i,
+					PhaseDescription: phase,
+					PhasePercent:     step,
+					ObjectCount:      len(r.objects),
+				})
+			}
+		}
+	}
+
+	err := r.Err
+	if err == nil && len(r.Errors) > 0 {
+		err = errors.New("could not convert the added objects")
+	}
+	if cb.Finished != nil {
+		cb.Finished(err == nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	output := r.Output
+	if output == nil {
+		pages := make([]string, len(r.objects))
+		for i := range pages {
+			pages[i] = fmt.Sprintf("Object %d", i+1)
+		}
+		output = minimalPDF(r.Title, r.Orientation == pdf.Landscape, pages)
+	}
+
+	_, err = io.Copy(w, bytes.NewReader(output))
+	return err
+}
+
+// SetCallbacks sets the functions called during the conversion process.
+func (r *FakeRenderer) SetCallbacks(callbacks pdf.Callbacks) {
+	r.callbacks = callbacks
+}
+
+// Destroy destroys the objects added to the renderer.
+func (r *FakeRenderer) Destroy() {
+	for _, o := range r.objects {
+		o.Destroy()
+	}
+	r.destroyed = true
+}
+
+// Objects returns the options of the objects added to the renderer, in the
+// order in which they were added.
+func (r *FakeRenderer) Objects() []*pdf.ObjectOpts {
+	opts := make([]*pdf.ObjectOpts, 0, len(r.objects))
+	for _, o := range r.objects {
+		opts = append(opts, o.ObjectOpts)
+	}
+
+	return opts
+}
+
+// Ran returns true if the renderer performed a conversion.
+func (r *FakeRenderer) Ran() bool {
+	return r.ran
+}
+
+// Destroyed returns true if the renderer was destroyed.
+func (r *FakeRenderer) Destroyed() bool {
+	return r.destroyed
+}
+
+var _ pdf.Renderer = (*FakeRenderer)(nil)
+
+// minimalPDF returns a minimal A4 PDF document with the specified title,
+// containing a page with the provided text for each of the specified pages.
+func minimalPDF(title string, landscape bool, pages []string) []byte {
+	width, height := 595, 842
+	if landscape {
+		width, height = height, width
+	}
+
+	// Objects 1-4 are the catalog, the page tree, the font and the document
+	// information dictionary. Each page is followed by its content stream.
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"", // Page tree, added after the pages.
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Title %s /Producer (pdftest) >>", pdfString(title)),
+	}
+
+	kids := make([]string, 0, len(pages))
+	for _, text := range pages {
+		num := len(objects) + 1
+		kids = append(kids, fmt.Sprintf("%d 0 R", num))
+
+		content := fmt.Sprintf("BT /F1 12 Tf 72 %d Td %s Tj ET", height-72, pdfString(text))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", width, height, num+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f\r\n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n\r\n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 4 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return buf.Bytes()
+}
+
+// pdfString returns the PDF literal string representation of the specified
+// text. Characters outside the printable ASCII range are replaced.
+func pdfString(text string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < ' ' || r > '~':
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte(')')
+
+	return b.String()
+}
@@ -0,0 +1,275 @@
+package pdftest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	pdf "github.com/adrg/go-wkhtmltopdf"
+)
+
+func TestFakeRendererAdd(t *testing.T) {
+	renderer := NewFakeRenderer(nil)
+	defer renderer.Destroy()
+
+	first, second := newFakeObject(t, "first.html"), newFakeObject(t, "second.html")
+	if err := renderer.Add(nil); err == nil {
+		t.Error("expected error for nil object")
+	}
+	if err := renderer.Add(first); err != nil {
+		t.Fatal(err)
+	}
+	if err := renderer.Add(first); err == nil {
+		t.Error("expected error for duplicate object")
+	}
+	if err := renderer.Add(second); err != nil {
+		t.Fatal(err)
+	}
+
+	objects := renderer.Objects()
+	if len(objects) != 2 || objects[0] != first.ObjectOpts || objects[1] != second.ObjectOpts {
+		t.Fatalf("expected the options of the added objects, got %v", objects)
+	}
+	if location := objects[1].Location; location != "second.html" {
+		t.Errorf("expected location %q, got %q", "second.html", location)
+	}
+
+	if err := renderer.Run(&bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := renderer.Add(newFakeObject(t, "third.html")); !errors.Is(err, pdf.ErrAlreadyRun) {
+		t.Errorf("expected ErrAlreadyRun, got %v", err)
+	}
+}
+
+func TestFakeRendererRun(t *testing.T) {
+	opts := pdf.NewConverterOpts()
+	opts.Title = "Fake report"
+	opts.Orientation = pdf.Landscape
+
+	renderer := NewFakeRenderer(opts)
+	defer renderer.Destroy()
+
+	var buf bytes.Buffer
+	if err := renderer.Run(&buf); err == nil {
+		t.Error("expected error for renderer without objects")
+	}
+	for _, location := range []string{"first.html", "second.html"} {
+		if err := renderer.Add(newFakeObject(t, location)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := renderer.Run(nil); err == nil {
+		t.Error("expected error for nil writer")
+	}
+	if renderer.Ran() {
+		t.Fatal("expected renderer not to have run")
+	}
+
+	if err := renderer.Run(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !renderer.Ran() {
+		t.Error("expected renderer to have run")
+	}
+	if err := renderer.Run(&buf); !errors.Is(err, pdf.ErrAlreadyRun) {
+		t.Errorf("expected ErrAlreadyRun on second run, got %v", err)
+	}
+
+	doc, err := Parse(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count := doc.PageCount(); count != 2 {
+		t.Errorf("expected 2 pages, got %d", count)
+	}
+	if title := doc.Title(); title != "Fake report" {
+		t.Errorf("expected title %q, got %q", "Fake report", title)
+	}
+	texts, err := doc.Texts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"Object 1", "Object 2"}; !reflect.DeepEqual(texts, expected) {
+		t.Errorf("expected texts %q, got %q", expected, texts)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("/MediaBox [0 0 842 595]")) {
+		t.Error("expected landscape pages")
+	}
+}
+
+func TestFakeRendererCallbacks(t *testing.T) {
+	renderer := NewFakeRenderer(nil)
+	defer renderer.Destroy()
+
+	renderer.Phases = []string{"Loading", "Printing"}
+	renderer.ProgressSteps = []int{0, 100}
+	renderer.Warnings = []string{"first warning", "second warning"}
+	if err := renderer.Add(newFakeObject(t, "index.html")); err != nil {
+		t.Fatal(err)
+	}
+
+	var events []string
+	var progress []pdf.Progress
+	renderer.SetCallbacks(pdf.Callbacks{
+		PhaseChanged: func(phase int) {
+			events = append(events, "phase "+renderer.Phases[phase])
+		},
+		ProgressChanged: func(percent int) {
+			events = append(events, fmt.Sprint("progress ", percent))
+		},
+		OverallProgressChanged: func(p pdf.Progress) {
+			progress = append(progress, p)
+		},
+		Warning: func(msg string) {
+			events = append(events, "warning "+msg)
+		},
+		Error: func(msg string) {
+			events = append(events, "error "+msg)
+		},
+		Finished: func(success bool) {
+			events = append(events, fmt.Sprint("finished ", success))
+		},
+	})
+
+	if err := renderer.Run(&bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedEvents := []string{
+		"phase Loading",
+		"warning first warning",
+		"warning second warning",
+		"progress 0",
+		"progress 100",
+		"phase Printing",
+		"progress 0",
+		"progress 100",
+		"finished true",
+	}
+	if !reflect.DeepEqual(events, expectedEvents) {
+		t.Errorf("expected events %q, got %q", expectedEvents, events)
+	}
+
+	expectedProgress := []pdf.Progress{
+		{Percent: 0, PhaseIndex: 0, PhaseDescription: "Loading", PhasePercent: 0, ObjectCount: 1},
+		{Percent: 50, PhaseIndex: 0, PhaseDescription: "Loading", PhasePercent: 100, ObjectCount: 1},
+		{Percent: 50, PhaseIndex: 1, PhaseDescription: "Printing", PhasePercent: 0, ObjectCount: 1},
+		{Percent: 100, PhaseIndex: 1, PhaseDescription: "Printing", PhasePercent: 100, ObjectCount: 1},
+	}
+	if !reflect.DeepEqual(progress, expectedProgress) {
+		t.Errorf("expected progress %+v, got %+v", expectedProgress, progress)
+	}
+}
+
+func TestFakeRendererFailure(t *testing.T) {
+	errConversion := errors.New("conversion failed")
+
+	tests := []struct {
+		name     string
+		errors   []string
+		err      error
+		expected []string
+	}{
+		{
+			name:     "errors",
+			errors:   []string{"could not load page"},
+			expected: []string{"error could not load page", "finished false"},
+		},
+		{
+			name:     "err",
+			err:      errConversion,
+			expected: []string{"finished false"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			renderer := NewFakeRenderer(nil)
+			defer renderer.Destroy()
+
+			renderer.Errors = test.errors
+			renderer.Err = test.err
+			if err := renderer.Add(newFakeObject(t, "index.html")); err != nil {
+				t.Fatal(err)
+			}
+
+			var events []string
+			renderer.SetCallbacks(pdf.Callbacks{
+				Error: func(msg string) {
+					events = append(events, "error "+msg)
+				},
+				Finished: func(success bool) {
+					events = append(events, fmt.Sprint("finished ", success))
+				},
+			})
+
+			var buf bytes.Buffer
+			err := renderer.Run(&buf)
+			if err == nil {
+				t.Fatal("expected conversion error")
+			}
+			if test.err != nil && !errors.Is(err, test.err) {
+				t.Errorf("expected error %v, got %v", test.err, err)
+			}
+			if buf.Len() != 0 {
+				t.Errorf("expected no output, got %d bytes", buf.Len())
+			}
+			if !reflect.DeepEqual(events, test.expected) {
+				t.Errorf("expected events %q, got %q", test.expected, events)
+			}
+		})
+	}
+}
+
+func TestFakeRendererOutput(t *testing.T) {
+	renderer := NewFakeRenderer(nil)
+	defer renderer.Destroy()
+
+	renderer.Output = []byte("custom output")
+	if err := renderer.Add(newFakeObject(t, "index.html")); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := renderer.Run(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if output := buf.String(); output != "custom output" {
+		t.Errorf("expected output %q, got %q", "custom output", output)
+	}
+}
+
+func TestFakeRendererDestroy(t *testing.T) {
+	renderer := NewFakeRenderer(nil)
+	if err := renderer.Add(newFakeObject(t, "index.html")); err != nil {
+		t.Fatal(err)
+	}
+
+	renderer.Destroy()
+	if !renderer.Destroyed() {
+		t.Error("expected renderer to be destroyed")
+	}
+	if err := renderer.Add(newFakeObject(t, "other.html")); err == nil {
+		t.Error("expected error when adding objects to destroyed renderer")
+	}
+	if err := renderer.Run(&bytes.Buffer{}); err == nil {
+		t.Error("expected error when running destroyed renderer")
+	}
+}
+
+// newFakeObject returns a new object from the specified location. The object
+// is destroyed when the test finishes, unless it is destroyed by a renderer.
+func newFakeObject(t *testing.T, location string) *pdf.Object {
+	t.Helper()
+
+	object, err := pdf.NewObject(location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(object.Destroy)
+
+	return object
+}
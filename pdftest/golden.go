@@ -0,0 +1,153 @@
+package pdftest
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// update specifies whether the golden files are updated instead of being
+// compared against.
+var update = flag.Bool("update-golden", false, "update the golden files used by pdftest")
+
+// GoldenPath returns the path of the golden file with the specified name.
+// Golden files are stored in the testdata directory of the tested package.
+func GoldenPath(name string) string {
+	return filepath.Join("testdata", name)
+}
+
+// Summary returns a textual representation of the specified document,
+// containing its page count, its title, the text, the links and the
+// annotations of each page, and its outline. The summary is used for
+// comparing documents against golden files, as it does not depend on
+// the volatile fields or the internal structure of the documents.
+func Summary(doc *Document) (string, error) {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "pages: %d\n", doc.PageCount())
+	if title := doc.Title(); title != "" {
+		fmt.Fprintf(&buf, "title: %s\n", title)
+	}
+
+	links, annotations := doc.Links(), doc.Annotations()
+	for page := 1; page <= doc.PageCount(); page++ {
+		text, err := doc.Text(page)
+		if err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(&buf, "\npage %d:\n", page)
+		for _, line := range strings.Split(text, "\n") {
+			fmt.Fprintf(&buf, "  | %s\n", line)
+		}
+		for _, link := range links {
+			if link.Page != page {
+				continue
+			}
+
+			target := link.URI
+			if target == "" {
+				target = fmt.Sprintf("page %d", link.DestPage)
+			}
+			fmt.Fprintf(&buf, "  link %s -> %s\n", formatRect(link.Rect), target)
+		}
+		for _, annot := range annotations {
+			if annot.Page != page || annot.Subtype == "Link" {
+				continue
+			}
+			fmt.Fprintf(&buf, "  annotation %s %s %q\n", annot.Subtype, formatRect(annot.Rect), annot.Contents)
+		}
+	}
+
+	if outline := doc.Outline(); len(outline) > 0 {
+		buf.WriteString("\noutline:\n")
+		for _, entry := range outline {
+			fmt.Fprintf(&buf, "  %s%s (page %d)\n", strings.Repeat("  ", entry.Level), entry.Title, entry.Page)
+		}
+	}
+
+	return buf.String(), nil
+}
+
+// AssertGolden compares the summary of the specified PDF document against
+// the golden file with the provided name. If the -update-golden flag is
+// specified, the golden file is updated instead. See Summary for the
+// information included in the comparison.
+func AssertGolden(tb testing.TB, name string, data []byte) {
+	tb.Helper()
+
+	doc, err := Parse(data)
+	if err != nil {
+		tb.Fatalf("pdftest: could not parse document: %v", err)
+	}
+	summary, err := Summary(doc)
+	if err != nil {
+		tb.Fatalf("pdftest: could not summarize document: %v", err)
+	}
+
+	AssertGoldenBytes(tb, name, []byte(summary))
+}
+
+// AssertGoldenBytes compares the specified data against the golden file
+// with the provided name. If the -update-golden flag is specified, the
+// golden file is updated instead. The function can be used for comparing
+// entire output documents, generated using reproducible output.
+func AssertGoldenBytes(tb testing.TB, name string, data []byte) {
+	tb.Helper()
+
+	path := GoldenPath(name)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			tb.Fatalf("pdftest: could not create golden file directory: %v", err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			tb.Fatalf("pdftest: could not update golden file: %v", err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		tb.Fatalf("pdftest: could not read golden file (run the tests using -update-golden to create it): %v", err)
+	}
+	if !bytes.Equal(data, expected) {
+		tb.Errorf("pdftest: output does not match golden file %s: %s", path, firstDifference(expected, data))
+	}
+}
+
+// firstDifference describes the first line which differs between the
+// expected and the actual data.
+func firstDifference(expected, actual []byte) string {
+	expectedLines := bytes.Split(expected, []byte("\n"))
+	actualLines := bytes.Split(actual, []byte("\n"))
+
+	for i := 0; i < len(expectedLines) || i < len(actualLines); i++ {
+		var e, a []byte
+		if i < len(expectedLines) {
+			e = expectedLines[i]
+		}
+		if i < len(actualLines) {
+			a = actualLines[i]
+		}
+		if !bytes.Equal(e, a) {
+			return fmt.Sprintf("line %d: expected %q, got %q", i+1, truncate(e), truncate(a))
+		}
+	}
+
+	return "sizes differ"
+}
+
+func truncate(line []byte) []byte {
+	if len(line) > 80 {
+		return append(line[:80:80], "..."...)
+	}
+
+	return line
+}
+
+func formatRect(rect [4]float64) string {
+	return fmt.Sprintf("[%g %g %g %g]", rect[0], rect[1], rect[2], rect[3])
+}
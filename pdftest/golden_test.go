@@ -0,0 +1,208 @@
+package pdftest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestSummary(t *testing.T) {
+	doc, err := Parse(testPDF())
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary, err := Summary(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `pages: 2
+title: Test document
+
+page 1:
+  | Hello world
+  | Second line
+  link [72 760 150 780] -> https://example.com
+  link [72 740 150 755] -> page 2
+  annotation Text [0 0 20 20] "Note"
+
+page 2:
+  | Hi
+  | Bye
+
+outline:
+  Intro (page 1)
+    Overview (page 2)
+  Details (page 2)
+`
+	if summary != expected {
+		t.Errorf("expected summary:\n%s\ngot:\n%s", expected, summary)
+	}
+}
+
+func TestAssertGolden(t *testing.T) {
+	tests := []struct {
+		name     string
+		golden   string
+		data     []byte
+		failed   bool
+		fatal    bool
+		expected string
+	}{
+		{
+			name:   "match",
+			golden: "document.golden",
+			data:   testPDF(),
+		},
+		{
+			name:     "mismatch",
+			golden:   "document.golden",
+			data:     minimalPDF("Test document", false, []string{"Other"}),
+			failed:   true,
+			expected: `line 1: expected "pages: 2", got "pages: 1"`,
+		},
+		{
+			name:     "missing golden file",
+			golden:   "missing.golden",
+			data:     testPDF(),
+			failed:   true,
+			fatal:    true,
+			expected: "-update-golden",
+		},
+		{
+			name:     "invalid document",
+			golden:   "document.golden",
+			data:     []byte("not a document"),
+			failed:   true,
+			fatal:    true,
+			expected: "could not parse document",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tb := runFakeTB(func(tb testing.TB) {
+				AssertGolden(tb, test.golden, test.data)
+			})
+			if tb.failed != test.failed || tb.fatal != test.fatal {
+				t.Fatalf("expected failed=%t fatal=%t, got failed=%t fatal=%t: %q",
+					test.failed, test.fatal, tb.failed, tb.fatal, tb.messages)
+			}
+			if test.expected != "" && !strings.Contains(strings.Join(tb.messages, "\n"), test.expected) {
+				t.Errorf("expected message containing %q, got %q", test.expected, tb.messages)
+			}
+		})
+	}
+}
+
+func TestAssertGoldenUpdate(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd) // nolint:errcheck
+
+	defer func(value bool) { *update = value }(*update)
+	*update = true
+
+	name := filepath.Join("nested", "output.golden")
+	if tb := runFakeTB(func(tb testing.TB) { AssertGoldenBytes(tb, name, []byte("first")) }); tb.failed {
+		t.Fatalf("could not update golden file: %q", tb.messages)
+	}
+	data, err := os.ReadFile(GoldenPath(name))
+	if err != nil || string(data) != "first" {
+		t.Fatalf("expected updated golden file, got %q (%v)", data, err)
+	}
+
+	*update = false
+	if tb := runFakeTB(func(tb testing.TB) { AssertGoldenBytes(tb, name, []byte("first")) }); tb.failed {
+		t.Errorf("expected data to match updated golden file: %q", tb.messages)
+	}
+	if tb := runFakeTB(func(tb testing.TB) { AssertGoldenBytes(tb, name, []byte("first\nsecond")) }); !tb.failed || tb.fatal {
+		t.Errorf("expected non-fatal failure, got failed=%t fatal=%t", tb.failed, tb.fatal)
+	}
+}
+
+func TestFirstDifference(t *testing.T) {
+	long := strings.Repeat("a", 100)
+
+	tests := []struct {
+		expected string
+		actual   string
+		result   string
+	}{
+		{expected: "a\nb\nc", actual: "a\nx\nc", result: `line 2: expected "b", got "x"`},
+		{expected: "a", actual: "a\nb", result: `line 2: expected "", got "b"`},
+		{expected: "a\n", actual: "a\n", result: "sizes differ"},
+		{expected: long, actual: "b", result: fmt.Sprintf("line 1: expected %q, got \"b\"", long[:80]+"...")},
+	}
+
+	for _, test := range tests {
+		if result := firstDifference([]byte(test.expected), []byte(test.actual)); result != test.result {
+			t.Errorf("expected %q, got %q", test.result, result)
+		}
+	}
+}
+
+func TestSkipIfNoLib(t *testing.T) {
+	t.Setenv(SkipLibEnv, "1")
+
+	for name, skip := range map[string]func(testing.TB){
+		"SkipIfNoLib":        SkipIfNoLib,
+		"SkipIfNoPatchedQT":  SkipIfNoPatchedQT,
+		"SkipIfVersionBelow": func(tb testing.TB) { SkipIfVersionBelow(tb, "0.12.6") },
+	} {
+		if tb := runFakeTB(skip); !tb.skipped {
+			t.Errorf("expected %s to skip the test", name)
+		}
+	}
+}
+
+// fakeTB records the failures and the skips of the helpers under test.
+// Fatal failures and skips stop the goroutine which runs the helper.
+type fakeTB struct {
+	testing.TB
+	failed, fatal, skipped bool
+	messages               []string
+}
+
+func runFakeTB(fn func(tb testing.TB)) *fakeTB {
+	tb := &fakeTB{}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(tb)
+	}()
+	<-done
+
+	return tb
+}
+
+func (tb *fakeTB) Helper() {}
+
+func (tb *fakeTB) Errorf(format string, args ...interface{}) {
+	tb.failed = true
+	tb.messages = append(tb.messages, fmt.Sprintf(format, args...))
+}
+
+func (tb *fakeTB) Fatalf(format string, args ...interface{}) {
+	tb.Errorf(format, args...)
+	tb.fatal = true
+	runtime.Goexit()
+}
+
+func (tb *fakeTB) Skip(args ...interface{}) {
+	tb.skipped = true
+	runtime.Goexit()
+}
+
+func (tb *fakeTB) Skipf(format string, args ...interface{}) {
+	tb.Skip()
+}
@@ -0,0 +1,21 @@
+//go:build cgo
+
+package pdftest
+
+import (
+	"log"
+	"os"
+	"testing"
+
+	pdf "github.com/adrg/go-wkhtmltopdf"
+)
+
+func TestMain(m *testing.M) {
+	if err := pdf.Init(); err != nil {
+		log.Fatal(err)
+	}
+	code := m.Run()
+	pdf.Destroy()
+
+	os.Exit(code)
+}
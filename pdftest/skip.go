@@ -0,0 +1,46 @@
+//go:build cgo
+
+package pdftest
+
+import (
+	"os"
+	"testing"
+
+	pdf "github.com/adrg/go-wkhtmltopdf"
+)
+
+// SkipIfNoLib skips the test if the PDFTEST_SKIP_LIB environment variable
+// is set. When cgo is enabled, the test binary is linked against the
+// `wkhtmltox` library, so the availability of the library cannot be
+// detected at run time.
+func SkipIfNoLib(tb testing.TB) {
+	tb.Helper()
+
+	if os.Getenv(SkipLibEnv) != "" {
+		tb.Skipf("pdftest: skipping test which requires the wkhtmltox library (%s is set)", SkipLibEnv)
+	}
+}
+
+// SkipIfNoPatchedQT skips the test if the `wkhtmltox` library is not
+// available or if it is not built against the wkhtmltopdf version of QT,
+// which is required by some of the conversion options.
+func SkipIfNoPatchedQT(tb testing.TB) {
+	tb.Helper()
+
+	SkipIfNoLib(tb)
+	if !pdf.HasPatchedQT() {
+		tb.Skip("pdftest: the wkhtmltox library is not built against patched QT")
+	}
+}
+
+// SkipIfVersionBelow skips the test if the `wkhtmltox` library is not
+// available or if its version is lower than the specified version.
+// E.g.: SkipIfVersionBelow(t, "0.12.6").
+func SkipIfVersionBelow(tb testing.TB, version string) {
+	tb.Helper()
+
+	SkipIfNoLib(tb)
+	if current := pdf.Version(); compareVersions(current, version) < 0 {
+		tb.Skipf("pdftest: the wkhtmltox library version %s is lower than %s", current, version)
+	}
+}
@@ -0,0 +1,26 @@
+//go:build !cgo
+
+package pdftest
+
+import "testing"
+
+// SkipIfNoLib skips the test, as the `wkhtmltox` library cannot be used
+// without cgo.
+func SkipIfNoLib(tb testing.TB) {
+	tb.Helper()
+	tb.Skip("pdftest: the wkhtmltox library cannot be used without cgo")
+}
+
+// SkipIfNoPatchedQT skips the test, as the `wkhtmltox` library cannot be
+// used without cgo.
+func SkipIfNoPatchedQT(tb testing.TB) {
+	tb.Helper()
+	SkipIfNoLib(tb)
+}
+
+// SkipIfVersionBelow skips the test, as the `wkhtmltox` library cannot be
+// used without cgo.
+func SkipIfVersionBelow(tb testing.TB, version string) {
+	tb.Helper()
+	SkipIfNoLib(tb)
+}
@@ -0,0 +1,447 @@
+package pdftest
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/adrg/go-wkhtmltopdf/internal/pdfdoc"
+)
+
+// maxFormDepth is the maximum nesting depth of the form XObjects whose text
+// is extracted.
+const maxFormDepth = 8
+
+// textFont contains the information required for decoding the strings
+// shown using a font and for computing their widths.
+type textFont struct {
+	codeLen   int
+	toUnicode map[uint32]string
+	widths    map[uint32]float64
+	defWidth  float64
+}
+
+func loadFont(doc *pdfdoc.Document, font pdfdoc.Dict) *textFont {
+	f := &textFont{codeLen: 1, widths: map[uint32]float64{}}
+	if font == nil {
+		return f
+	}
+
+	if font.Name("Subtype") == "Type0" {
+		f.codeLen, f.defWidth = 2, 1000
+
+		// Composite font widths are specified by the descendant font.
+		if descendants := doc.Array(font["DescendantFonts"]); len(descendants) > 0 {
+			cidFont := doc.Dict(descendants[0])
+			if w, ok := pdfdoc.Float(doc.Resolve(cidFont["DW"])); ok {
+				f.defWidth = w
+			}
+			f.loadCIDWidths(doc, doc.Array(cidFont["W"]))
+		}
+	} else {
+		first, _ := pdfdoc.Int(doc.Resolve(font["FirstChar"]))
+		for i, w := range doc.Array(font["Widths"]) {
+			f.widths[uint32(first)+uint32(i)], _ = pdfdoc.Float(doc.Resolve(w))
+		}
+		if descriptor := doc.Dict(font["FontDescriptor"]); descriptor != nil {
+			f.defWidth, _ = pdfdoc.Float(doc.Resolve(descriptor["MissingWidth"]))
+		}
+	}
+
+	if stream, ok := doc.Resolve(font["ToUnicode"]).(*pdfdoc.Stream); ok {
+		if data, err := stream.Decode(); err == nil {
+			f.toUnicode = parseToUnicode(data)
+		}
+	}
+
+	return f
+}
+
+// loadCIDWidths loads the glyph widths of a CID font, specified either as
+// `c [w1 w2 ...]` or as `cFirst cLast w` sequences.
+func (f *textFont) loadCIDWidths(doc *pdfdoc.Document, w pdfdoc.Array) {
+	for i := 0; i+1 < len(w); {
+		first, _ := pdfdoc.Int(doc.Resolve(w[i]))
+		if widths := doc.Array(w[i+1]); widths != nil {
+			for j, width := range widths {
+				f.widths[uint32(first)+uint32(j)], _ = pdfdoc.Float(doc.Resolve(width))
+			}
+			i += 2
+			continue
+		}
+		if i+2 >= len(w) {
+			break
+		}
+
+		last, _ := pdfdoc.Int(doc.Resolve(w[i+1]))
+		width, _ := pdfdoc.Float(doc.Resolve(w[i+2]))
+		for code := first; code <= last && code-first < 65536; code++ {
+			f.widths[uint32(code)] = width
+		}
+		i += 3
+	}
+}
+
+// codes splits the specified string into character codes.
+func (f *textFont) codes(s string) []uint32 {
+	codes := make([]uint32, 0, len(s)/f.codeLen)
+	for i := 0; i+f.codeLen <= len(s); i += f.codeLen {
+		var code uint32
+		for j := 0; j < f.codeLen; j++ {
+			code = code<<8 | uint32(s[i+j])
+		}
+		codes = append(codes, code)
+	}
+
+	return codes
+}
+
+// text returns the Unicode text of the specified character code. Codes of
+// composite fonts without a ToUnicode mapping cannot be decoded.
+func (f *textFont) text(code uint32) string {
+	if text, ok := f.toUnicode[code]; ok {
+		return text
+	}
+	if f.codeLen == 1 {
+		return string(rune(code))
+	}
+
+	return ""
+}
+
+// width returns the width of the specified character code, in thousandths
+// of text space units.
+func (f *textFont) width(code uint32) float64 {
+	if w, ok := f.widths[code]; ok {
+		return w
+	}
+
+	return f.defWidth
+}
+
+// parseToUnicode parses the mappings of the specified ToUnicode CMap.
+func parseToUnicode(data []byte) map[uint32]string {
+	ops, err := pdfdoc.ParseContent(data)
+	if err != nil {
+		return nil
+	}
+
+	code := func(o pdfdoc.Object) (uint32, bool) {
+		s, ok := pdfdoc.Text(o)
+		if !ok || len(s) == 0 || len(s) > 4 {
+			return 0, false
+		}
+
+		var c uint32
+		for i := 0; i < len(s); i++ {
+			c = c<<8 | uint32(s[i])
+		}
+		return c, true
+	}
+
+	mappings := map[uint32]string{}
+	for _, op := range ops {
+		switch op.Operator {
+		case "endbfchar":
+			for i := 0; i+1 < len(op.Operands); i += 2 {
+				src, ok := code(op.Operands[i])
+				if !ok {
+					continue
+				}
+				if dst, ok := pdfdoc.Text(op.Operands[i+1]); ok {
+					mappings[src] = decodeUTF16(dst, 0)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(op.Operands); i += 3 {
+				lo, ok1 := code(op.Operands[i])
+				hi, ok2 := code(op.Operands[i+1])
+				if !ok1 || !ok2 || hi < lo || hi-lo > 65535 {
+					continue
+				}
+
+				switch dst := op.Operands[i+2].(type) {
+				case pdfdoc.Array:
+					for j, o := range dst {
+						if s, ok := pdfdoc.Text(o); ok && lo+uint32(j) <= hi {
+							mappings[lo+uint32(j)] = decodeUTF16(s, 0)
+						}
+					}
+				default:
+					if s, ok := pdfdoc.Text(dst); ok {
+						for c := lo; c <= hi; c++ {
+							mappings[c] = decodeUTF16(s, uint16(c-lo))
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return mappings
+}
+
+// decodeUTF16 decodes the specified UTF-16BE string, after adding the
+// provided offset to its last code unit.
+func decodeUTF16(s string, offset uint16) string {
+	units := make([]uint16, 0, len(s)/2)
+	for i := 0; i+1 < len(s); i += 2 {
+		units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+	}
+	if n := len(units); n > 0 {
+		units[n-1] += offset
+	}
+
+	return string(utf16.Decode(units))
+}
+
+// textExtractor extracts the text of a page, by interpreting the text
+// operators of its content streams. The positions of the shown strings are
+// used for separating the extracted text into words and lines.
+type textExtractor struct {
+	doc   *pdfdoc.Document
+	fonts map[string]*textFont
+	buf   strings.Builder
+
+	// Text state.
+	font        *textFont
+	fontSize    float64
+	charSpacing float64
+	wordSpacing float64
+	scale       float64
+	leading     float64
+	tm, tlm     [6]float64
+
+	// The end position of the last shown string, in text space units.
+	lastX, lastY float64
+	hasText      bool
+}
+
+func extractText(doc *pdfdoc.Document, page pdfdoc.Dict) (string, error) {
+	var data [][]byte
+	contents := doc.Resolve(page["Contents"])
+	if arr, ok := contents.(pdfdoc.Array); ok {
+		for _, o := range arr {
+			if stream, ok := doc.Resolve(o).(*pdfdoc.Stream); ok {
+				decoded, err := stream.Decode()
+				if err != nil {
+					return "", err
+				}
+				data = append(data, decoded)
+			}
+		}
+	} else if stream, ok := contents.(*pdfdoc.Stream); ok {
+		decoded, err := stream.Decode()
+		if err != nil {
+			return "", err
+		}
+		data = append(data, decoded)
+	}
+
+	e := &textExtractor{doc: doc, scale: 1}
+	resources := doc.Dict(doc.Inherited(page, "Resources"))
+	if err := e.run(bytes.Join(data, []byte("\n")), resources, 0); err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(e.buf.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n")), nil
+}
+
+func (e *textExtractor) run(data []byte, resources pdfdoc.Dict, depth int) error {
+	ops, err := pdfdoc.ParseContent(data)
+	if err != nil {
+		return err
+	}
+
+	for _, op := range ops {
+		args := op.Operands
+		num := func(i int) float64 {
+			if i >= len(args) {
+				return 0
+			}
+			v, _ := pdfdoc.Float(args[i])
+			return v
+		}
+
+		switch op.Operator {
+		case "BT":
+			e.tm = [6]float64{1, 0, 0, 1, 0, 0}
+			e.tlm = e.tm
+		case "Tf":
+			if len(args) > 0 {
+				name, _ := args[0].(pdfdoc.Name)
+				e.font = e.loadFont(resources, name)
+			}
+			e.fontSize = num(1)
+		case "Tc":
+			e.charSpacing = num(0)
+		case "Tw":
+			e.wordSpacing = num(0)
+		case "Tz":
+			e.scale = num(0) / 100
+		case "TL":
+			e.leading = num(0)
+		case "Td":
+			e.moveLine(num(0), num(1))
+		case "TD":
+			e.leading = -num(1)
+			e.moveLine(num(0), num(1))
+		case "Tm":
+			for i := range e.tm {
+				e.tm[i] = num(i)
+			}
+			e.tlm = e.tm
+		case "T*":
+			e.moveLine(0, -e.leading)
+		case "Tj":
+			if len(args) > 0 {
+				e.show(args[0])
+			}
+		case "'":
+			e.moveLine(0, -e.leading)
+			if len(args) > 0 {
+				e.show(args[0])
+			}
+		case "\"":
+			e.wordSpacing, e.charSpacing = num(0), num(1)
+			e.moveLine(0, -e.leading)
+			if len(args) > 2 {
+				e.show(args[2])
+			}
+		case "TJ":
+			if len(args) == 0 {
+				continue
+			}
+			arr, _ := args[0].(pdfdoc.Array)
+			for _, o := range arr {
+				if adjustment, ok := pdfdoc.Float(o); ok {
+					e.advance(-adjustment / 1000 * e.fontSize * e.scale)
+					continue
+				}
+				e.show(o)
+			}
+		case "Do":
+			if depth >= maxFormDepth || len(args) == 0 {
+				continue
+			}
+			name, _ := args[0].(pdfdoc.Name)
+			if err := e.runForm(resources, name, depth); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// runForm extracts the text of the form XObject with the specified name.
+func (e *textExtractor) runForm(resources pdfdoc.Dict, name pdfdoc.Name, depth int) error {
+	xobjects := e.doc.Dict(resources["XObject"])
+	if xobjects == nil {
+		return nil
+	}
+
+	stream, ok := e.doc.Resolve(xobjects[name]).(*pdfdoc.Stream)
+	if !ok || stream.Dict.Name("Subtype") != "Form" {
+		return nil
+	}
+	data, err := stream.Decode()
+	if err != nil {
+		return err
+	}
+
+	formResources := e.doc.Dict(stream.Dict["Resources"])
+	if formResources == nil {
+		formResources = resources
+	}
+
+	return e.run(data, formResources, depth+1)
+}
+
+func (e *textExtractor) loadFont(resources pdfdoc.Dict, name pdfdoc.Name) *textFont {
+	fonts := e.doc.Dict(resources["Font"])
+	if fonts == nil {
+		return loadFont(e.doc, nil)
+	}
+
+	// Cache the fonts by reference, as the same resource names can refer
+	// to different fonts in different form XObjects.
+	key := string(name)
+	if ref, ok := fonts[name].(pdfdoc.Ref); ok {
+		key = ref.String()
+	}
+	if font, ok := e.fonts[key]; ok {
+		return font
+	}
+
+	font := loadFont(e.doc, e.doc.Dict(fonts[name]))
+	if e.fonts == nil {
+		e.fonts = map[string]*textFont{}
+	}
+	e.fonts[key] = font
+
+	return font
+}
+
+// moveLine moves to the start of the next line, offset from the start of
+// the current line by the specified amounts.
+func (e *textExtractor) moveLine(tx, ty float64) {
+	m := e.tlm
+	m[4] = tx*e.tlm[0] + ty*e.tlm[2] + e.tlm[4]
+	m[5] = tx*e.tlm[1] + ty*e.tlm[3] + e.tlm[5]
+	e.tm, e.tlm = m, m
+}
+
+// advance moves the text position horizontally by the specified amount,
+// in unscaled text space units.
+func (e *textExtractor) advance(tx float64) {
+	e.tm[4] += tx * e.tm[0]
+	e.tm[5] += tx * e.tm[1]
+}
+
+// show appends the specified string to the extracted text, preceded by
+// a space or a newline, based on its position relative to the previously
+// shown string.
+func (e *textExtractor) show(o pdfdoc.Object) {
+	s, ok := pdfdoc.Text(o)
+	if !ok {
+		return
+	}
+	if e.font == nil {
+		e.font = loadFont(e.doc, nil)
+	}
+
+	// Approximate the size of the font, in text space units.
+	size := math.Abs(e.fontSize * math.Hypot(e.tm[2], e.tm[3]))
+	if size == 0 {
+		size = 1
+	}
+
+	x, y := e.tm[4], e.tm[5]
+	if e.hasText {
+		switch {
+		case math.Abs(y-e.lastY) > size/2:
+			e.buf.WriteByte('\n')
+		case x-e.lastX > size*0.15:
+			e.buf.WriteByte(' ')
+		}
+	}
+
+	for _, code := range e.font.codes(s) {
+		e.buf.WriteString(e.font.text(code))
+
+		tx := e.font.width(code)/1000*e.fontSize + e.charSpacing
+		if e.font.codeLen == 1 && code == ' ' {
+			tx += e.wordSpacing
+		}
+		e.advance(tx * e.scale)
+	}
+
+	e.lastX, e.lastY, e.hasText = e.tm[4], e.tm[5], true
+}
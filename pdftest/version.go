@@ -0,0 +1,49 @@
+package pdftest
+
+import (
+	"strconv"
+	"strings"
+)
+
+// compareVersions compares the specified dot-separated version numbers,
+// ignoring any non-numeric suffixes. It returns -1 if a is lower than b,
+// 1 if a is greater than b and 0 if the versions are equal.
+func compareVersions(a, b string) int {
+	as, bs := versionParts(a), versionParts(b)
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func versionParts(version string) []int {
+	var parts []int
+	for _, part := range strings.Split(strings.TrimSpace(version), ".") {
+		end := 0
+		for end < len(part) && part[end] >= '0' && part[end] <= '9' {
+			end++
+		}
+
+		n, _ := strconv.Atoi(part[:end])
+		parts = append(parts, n)
+		if end < len(part) {
+			break
+		}
+	}
+
+	return parts
+}
@@ -0,0 +1,26 @@
+package pdftest
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{a: "0.12.6", b: "0.12.6", expected: 0},
+		{a: "0.12.6", b: "0.12.5", expected: 1},
+		{a: "0.12.5", b: "0.12.6", expected: -1},
+		{a: "0.12", b: "0.12.0", expected: 0},
+		{a: "0.12.6", b: "0.12", expected: 1},
+		{a: "0.12.10", b: "0.12.9", expected: 1},
+		{a: "0.12.6-dev", b: "0.12.6", expected: 0},
+		{a: " 0.13.0 ", b: "0.12.6", expected: 1},
+		{a: "", b: "0.1", expected: -1},
+	}
+
+	for _, test := range tests {
+		if result := compareVersions(test.a, test.b); result != test.expected {
+			t.Errorf("compareVersions(%q, %q): expected %d, got %d", test.a, test.b, test.expected, result)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/adrg/go-wkhtmltopdf/internal/pdfdoc"
+)
+
+// postProcess applies the adjustments requested through the converter
+// options to the output document generated by the `wkhtmltox` library.
+// If no adjustments are required, the data is returned unchanged.
+func (opts *ConverterOpts) postProcess(data []byte) ([]byte, error) {
+	if len(opts.Outline) == 0 && opts.Conformance == "" && !opts.Reproducible {
+		return data, nil
+	}
+	if opts.OutputFormat == PostScript {
+		return nil, errors.New("cannot post-process PostScript output documents")
+	}
+
+	doc, err := pdfdoc.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Normalize the document dates, before they are used for generating
+	// the document metadata.
+	if opts.Reproducible {
+		if err := normalizeDates(doc); err != nil {
+			return nil, err
+		}
+	}
+
+	// Add custom outline items.
+	if len(opts.Outline) > 0 {
+		if err := injectOutline(doc, opts.Outline, opts.OutlineMode); err != nil {
+			return nil, err
+		}
+	}
+
+	// Adjust document to conform to the requested PDF/A level.
+	if opts.Conformance != "" {
+		if err := makeConformant(doc, opts.Conformance); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Reproducible {
+		return writeReproducible(doc)
+	}
+
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
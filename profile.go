@@ -0,0 +1,184 @@
+package pdf
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// profileDef contains the definition of an option profile, as read from a
+// configuration file. The options are stored in their JSON encoded form,
+// in order to be applied on top of the options of the parent profile.
+type profileDef struct {
+	extends   string
+	converter json.RawMessage
+	object    json.RawMessage
+}
+
+var (
+	profilesMu sync.RWMutex
+	profiles   = map[string]*profileDef{}
+)
+
+// LoadProfiles reads the option profiles defined in the YAML or JSON file
+// at the specified path and adds them to the profile registry. Profiles
+// which are already registered are replaced. The file maps profile names to
+// profile definitions, which contain converter and object options, using
+// the same keys as the JSON and YAML representations of the options.
+// A profile can extend another profile, in which case its options are
+// applied on top of the options of the extended profile, as a JSON Merge
+// Patch (see MergePatch). Options set to null are reset to their defaults.
+// The options of profiles which do not extend other profiles are applied on
+// top of the defaults returned by NewConverterOpts and NewObjectOpts.
+//
+//	base:
+//	  converter:
+//	    paperSize: A4
+//	    marginTop: 15mm
+//	    marginBottom: 15mm
+//	invoice:
+//	  extends: base
+//	  object:
+//	    footer:
+//	      contentRight: "[page]"
+func LoadProfiles(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	// YAML is a superset of JSON, so both formats are decoded as YAML.
+	var file map[string]struct {
+		Extends   string      `yaml:"extends"`
+		Converter interface{} `yaml:"converter"`
+		Object    interface{} `yaml:"object"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("invalid profiles file %s: %w", path, err)
+	}
+
+	defs := make(map[string]*profileDef, len(file))
+	for name, profile := range file {
+		def := &profileDef{extends: profile.Extends}
+		if def.converter, err = encodeProfileOpts(profile.Converter); err != nil {
+			return fmt.Errorf("invalid converter options for profile %q: %w", name, err)
+		}
+		if def.object, err = encodeProfileOpts(profile.Object); err != nil {
+			return fmt.Errorf("invalid object options for profile %q: %w", name, err)
+		}
+		defs[name] = def
+	}
+
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+
+	// Register profiles and check that all of them can be resolved.
+	previous := make(map[string]*profileDef, len(defs))
+	for name, def := range defs {
+		previous[name] = profiles[name]
+		profiles[name] = def
+	}
+	for name := range defs {
+		if _, _, err := resolveProfile(name); err != nil {
+			for name, def := range previous {
+				if def == nil {
+					delete(profiles, name)
+				} else {
+					profiles[name] = def
+				}
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NewConverterOptsFromProfile returns a new instance of converter options,
+// configured using the profile with the specified name.
+// See LoadProfiles for loading profiles.
+func NewConverterOptsFromProfile(name string) (*ConverterOpts, error) {
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+
+	opts, _, err := resolveProfile(name)
+	return opts, err
+}
+
+// NewObjectOptsFromProfile returns a new instance of object options,
+// configured using the profile with the specified name.
+// See LoadProfiles for loading profiles.
+func NewObjectOptsFromProfile(name string) (*ObjectOpts, error) {
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+
+	_, opts, err := resolveProfile(name)
+	return opts, err
+}
+
+// NewObjectFromProfile returns a new object instance from the document at
+// the specified location, configured using the profile with the specified
+// name. If the location is empty, the location defined by the profile is
+// used. See LoadProfiles for loading profiles.
+func NewObjectFromProfile(name, location string) (*Object, error) {
+	opts, err := NewObjectOptsFromProfile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return newObject(location, false, opts)
+}
+
+// resolveProfile returns the converter and object options defined by the
+// profile with the specified name. The caller must hold the registry lock.
+func resolveProfile(name string) (*ConverterOpts, *ObjectOpts, error) {
+	// Collect the inheritance chain of the profile.
+	var (
+		chain   []*profileDef
+		visited = map[string]bool{}
+		names   []string
+	)
+	for current := name; current != ""; {
+		names = append(names, current)
+		if visited[current] {
+			return nil, nil, fmt.Errorf("profile inheritance cycle: %s", strings.Join(names, " -> "))
+		}
+		visited[current] = true
+
+		def, ok := profiles[current]
+		if !ok {
+			return nil, nil, fmt.Errorf("profile %q not found", current)
+		}
+		chain = append(chain, def)
+		current = def.extends
+	}
+
+	// Apply the options of the profiles, starting with the base profile.
+	converterOpts, objectOpts := NewConverterOpts(), NewObjectOpts()
+	for i := len(chain) - 1; i >= 0; i-- {
+		if def := chain[i]; def.converter != nil {
+			if err := converterOpts.ApplyMergePatch(def.converter); err != nil {
+				return nil, nil, fmt.Errorf("invalid converter options for profile %q: %w", names[i], err)
+			}
+		}
+		if def := chain[i]; def.object != nil {
+			if err := objectOpts.ApplyMergePatch(def.object); err != nil {
+				return nil, nil, fmt.Errorf("invalid object options for profile %q: %w", names[i], err)
+			}
+		}
+	}
+
+	return converterOpts, objectOpts, nil
+}
+
+func encodeProfileOpts(opts interface{}) (json.RawMessage, error) {
+	if opts == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(opts)
+}
@@ -0,0 +1,165 @@
+package pdf
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadProfiles(t *testing.T) {
+	path := writeProfiles(t, "profiles.yaml", `
+base:
+  converter:
+    paperSize: Letter
+    marginTop: 15mm
+    title: Base
+  object:
+    zoom: 0.5
+    footer:
+      contentCenter: "[page]"
+invoice:
+  extends: base
+  converter:
+    title: Invoice
+    marginTop: null
+  object:
+    footer:
+      contentRight: "[title]"
+    zoom: null
+`)
+	if err := LoadProfiles(path); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		profile       string
+		title         string
+		marginTop     string
+		zoom          float64
+		contentCenter string
+		contentRight  string
+	}{
+		{profile: "base", title: "Base", marginTop: "15mm", zoom: 0.5, contentCenter: "[page]"},
+		{profile: "invoice", title: "Invoice", marginTop: "", zoom: 1, contentCenter: "[page]", contentRight: "[title]"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.profile, func(t *testing.T) {
+			converterOpts, err := NewConverterOptsFromProfile(test.profile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if converterOpts.PaperSize != Letter {
+				t.Errorf("expected paper size %s, got %s", Letter, converterOpts.PaperSize)
+			}
+			if converterOpts.Title != test.title {
+				t.Errorf("expected title %q, got %q", test.title, converterOpts.Title)
+			}
+			if converterOpts.MarginTop != test.marginTop {
+				t.Errorf("expected top margin %q, got %q", test.marginTop, converterOpts.MarginTop)
+			}
+			if converterOpts.MarginLeft != "10mm" {
+				t.Errorf("expected default left margin, got %q", converterOpts.MarginLeft)
+			}
+
+			objectOpts, err := NewObjectOptsFromProfile(test.profile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if objectOpts.Zoom == nil || *objectOpts.Zoom != test.zoom {
+				t.Errorf("expected zoom %v, got %v", test.zoom, objectOpts.Zoom)
+			}
+			if objectOpts.Footer.ContentCenter != test.contentCenter {
+				t.Errorf("expected footer center %q, got %q", test.contentCenter, objectOpts.Footer.ContentCenter)
+			}
+			if objectOpts.Footer.ContentRight != test.contentRight {
+				t.Errorf("expected footer right %q, got %q", test.contentRight, objectOpts.Footer.ContentRight)
+			}
+			if !objectOpts.PrintBackground {
+				t.Error("expected default options to be preserved")
+			}
+		})
+	}
+}
+
+func TestLoadProfilesJSON(t *testing.T) {
+	path := writeProfiles(t, "profiles.json", `{"json-legal": {"converter": {"paperSize": "Legal"}}}`)
+	if err := LoadProfiles(path); err != nil {
+		t.Fatal(err)
+	}
+
+	opts, err := NewConverterOptsFromProfile("json-legal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.PaperSize != Legal {
+		t.Errorf("expected paper size %s, got %s", Legal, opts.PaperSize)
+	}
+}
+
+func TestLoadProfilesErrors(t *testing.T) {
+	path := writeProfiles(t, "valid.yaml", "stable:\n  converter:\n    title: Stable\n")
+	if err := LoadProfiles(path); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{
+			name:     "cycle",
+			data:     "stable:\n  extends: cycle-b\ncycle-b:\n  extends: stable\n",
+			expected: "profile inheritance cycle",
+		},
+		{
+			name:     "missing parent",
+			data:     "stable:\n  extends: missing\n",
+			expected: `profile "missing" not found`,
+		},
+		{
+			name:     "invalid options",
+			data:     "stable:\n  converter:\n    copies: many\n",
+			expected: `invalid converter options for profile "stable"`,
+		},
+		{
+			name:     "invalid file",
+			data:     "- not a map",
+			expected: "invalid profiles file",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := LoadProfiles(writeProfiles(t, "invalid.yaml", test.data))
+			if err == nil || !strings.Contains(err.Error(), test.expected) {
+				t.Fatalf("expected error containing %q, got %v", test.expected, err)
+			}
+
+			// Invalid files must not replace the registered profiles.
+			opts, err := NewConverterOptsFromProfile("stable")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if opts.Title != "Stable" {
+				t.Errorf("expected registered profile to be preserved, got title %q", opts.Title)
+			}
+			if _, err := NewConverterOptsFromProfile("cycle-b"); err == nil {
+				t.Error("expected profiles of invalid files not to be registered")
+			}
+		})
+	}
+}
+
+func writeProfiles(t *testing.T, name, data string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
@@ -0,0 +1,327 @@
+package pdf
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultPhaseWeights contains the default relative weights of the
+// conversion phases of the `wkhtmltox` library, keyed by description.
+var defaultPhaseWeights = map[string]float64{
+	"Loading pages":               60,
+	"Counting pages":              5,
+	"Loading TOC":                 5,
+	"Resolving links":             5,
+	"Loading headers and footers": 10,
+	"Printing pages":              15,
+	"Done":                        0,
+}
+
+// defaultPhaseWeight is the default weight of unknown conversion phases.
+const defaultPhaseWeight = 5
+
+// learningRate is the smoothing factor used for learning phase durations.
+// The samples of previous conversions are discounted by 1-learningRate for
+// each new sample.
+const learningRate = 0.3
+
+// DefaultProgressEstimator is the progress estimator used by converters
+// which do not specify one. It learns the phase weights from the durations
+// of the completed conversions.
+var DefaultProgressEstimator = NewProgressEstimator()
+
+// Progress contains the overall progress of a conversion, aggregated across
+// all conversion phases.
+type Progress struct {
+	// The overall progress of the conversion, as a percentage.
+	Percent float64 `json:"percent" yaml:"percent"`
+
+	// The index of the current conversion phase.
+	PhaseIndex int `json:"phaseIndex" yaml:"phaseIndex"`
+
+	// The description of the current conversion phase.
+	PhaseDescription string `json:"phaseDescription" yaml:"phaseDescription"`
+
+	// The progress of the current conversion phase, as a percentage.
+	PhasePercent int `json:"phasePercent" yaml:"phasePercent"`
+
+	// The number of converted objects.
+	ObjectCount int `json:"objectCount" yaml:"objectCount"`
+
+	// The time elapsed since the start of the conversion.
+	Elapsed time.Duration `json:"elapsed" yaml:"elapsed"`
+
+	// The estimated time until the conversion is finished. The value is zero
+	// if the remaining time cannot be estimated yet.
+	ETA time.Duration `json:"eta" yaml:"eta"`
+}
+
+// ProgressEstimator determines the weights of the conversion phases, used
+// to aggregate the progress of the phases into the overall progress of
+// conversions. The phase weights are either configured, learned from the
+// durations of completed conversions, or the defaults. The learned duration
+// of each phase consists of a fixed part and a part proportional to the
+// number of converted objects, so learned weights take the number of
+// converted objects into account. A progress estimator can be shared by
+// multiple converters.
+type ProgressEstimator struct {
+	// Relative weights of the conversion phases, keyed by phase description.
+	// If specified, the weights take precedence over the learned weights.
+	// Phases without a weight do not contribute to the overall progress.
+	// E.g.: {"Loading pages": 80, "Printing pages": 20}.
+	Weights map[string]float64
+
+	// Specifies whether phase weights are learned from the durations of
+	// completed conversions.
+	Learn bool
+
+	mu      sync.Mutex
+	learned map[string]*phaseModel
+}
+
+// NewProgressEstimator returns a new progress estimator, which learns the
+// phase weights from the durations of completed conversions.
+func NewProgressEstimator() *ProgressEstimator {
+	return &ProgressEstimator{Learn: true}
+}
+
+// weights returns the weights of the specified phases, for a conversion
+// with the specified number of objects.
+func (pe *ProgressEstimator) weights(phases []string, objectCount int) []float64 {
+	weights := make([]float64, len(phases))
+
+	// Use configured weights.
+	if len(pe.Weights) > 0 {
+		for i, phase := range phases {
+			weights[i] = pe.Weights[phase]
+		}
+		return weights
+	}
+
+	// Use learned weights, if all phases have been learned.
+	pe.mu.Lock()
+	learned, total := len(pe.learned) > 0, 0.0
+	for i, phase := range phases {
+		model, ok := pe.learned[phase]
+		if !ok {
+			learned = false
+			break
+		}
+		weights[i] = model.estimate(float64(objectCount))
+		total += weights[i]
+	}
+	pe.mu.Unlock()
+	if learned && total > 0 {
+		return weights
+	}
+
+	// Use default weights.
+	for i, phase := range phases {
+		weight, ok := defaultPhaseWeights[phase]
+		if !ok {
+			weight = defaultPhaseWeight
+		}
+		weights[i] = weight
+	}
+
+	return weights
+}
+
+// learn updates the learned phase weights using the phase durations of a
+// completed conversion.
+func (pe *ProgressEstimator) learn(phases []string, durations []time.Duration, objectCount int) {
+	if !pe.Learn || objectCount <= 0 {
+		return
+	}
+
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	if pe.learned == nil {
+		pe.learned = map[string]*phaseModel{}
+	}
+	for i, phase := range phases {
+		model, ok := pe.learned[phase]
+		if !ok {
+			model = &phaseModel{}
+			pe.learned[phase] = model
+		}
+		model.add(float64(objectCount), durations[i].Seconds())
+	}
+}
+
+// phaseModel models the duration of a conversion phase as a fixed intercept
+// plus a slope per converted object, fitted using least squares regression
+// on the exponentially discounted samples of completed conversions.
+type phaseModel struct {
+	// Discounted sums of the sample weights, object counts, durations,
+	// squared object counts and object count-duration products.
+	w, n, d, nn, nd float64
+}
+
+// add adds a sample containing the number of objects and the duration of
+// the phase, in seconds, to the model.
+func (m *phaseModel) add(n, d float64) {
+	decay := 1 - learningRate
+	m.w = m.w*decay + 1
+	m.n = m.n*decay + n
+	m.d = m.d*decay + d
+	m.nn = m.nn*decay + n*n
+	m.nd = m.nd*decay + n*d
+}
+
+// estimate returns the estimated duration of the phase, in seconds, for the
+// specified number of objects. Until samples with different object counts
+// are available, the slope cannot be determined and the mean duration is
+// used. The intercept and the slope are not allowed to be negative.
+func (m *phaseModel) estimate(n float64) float64 {
+	if m.w == 0 {
+		return 0
+	}
+	meanN, meanD := m.n/m.w, m.d/m.w
+
+	var slope float64
+	if variance := m.nn/m.w - meanN*meanN; variance > 1e-9 {
+		if slope = (m.nd/m.w - meanN*meanD) / variance; slope < 0 {
+			slope = 0
+		}
+	}
+
+	// Fit a line through the origin if the intercept would be negative.
+	intercept := meanD - slope*meanN
+	if intercept < 0 {
+		intercept, slope = 0, m.nd/m.nn
+	}
+
+	return intercept + slope*n
+}
+
+// progressTracker aggregates the progress of the phases of a conversion.
+type progressTracker struct {
+	estimator   *ProgressEstimator
+	phases      []string
+	weights     []float64
+	total       float64
+	objectCount int
+
+	start      time.Time
+	phaseStart time.Time
+	durations  []time.Duration
+	phase      int
+	percent    float64
+}
+
+func newProgressTracker(estimator *ProgressEstimator, phases []string, objectCount int) *progressTracker {
+	if estimator == nil {
+		estimator = DefaultProgressEstimator
+	}
+
+	weights := estimator.weights(phases, objectCount)
+
+	var total float64
+	for _, weight := range weights {
+		total += weight
+	}
+
+	now := time.Now()
+	return &progressTracker{
+		estimator:   estimator,
+		phases:      phases,
+		weights:     weights,
+		total:       total,
+		objectCount: objectCount,
+		start:       now,
+		phaseStart:  now,
+		durations:   make([]time.Duration, len(phases)),
+		phase:       -1,
+	}
+}
+
+// update records the progress of the specified phase and returns the
+// overall progress of the conversion. The returned boolean reports whether
+// the overall progress or the current phase changed.
+func (t *progressTracker) update(phaseIndex, phasePercent int) (Progress, bool) {
+	if t == nil || phaseIndex < 0 || phaseIndex >= len(t.phases) {
+		return Progress{}, false
+	}
+
+	// Record the duration of the previous phase.
+	now := time.Now()
+	phaseChanged := phaseIndex != t.phase
+	if phaseChanged {
+		if t.phase >= 0 {
+			t.durations[t.phase] += now.Sub(t.phaseStart)
+		}
+		t.phase, t.phaseStart = phaseIndex, now
+	}
+
+	// Aggregate the progress of the phases. The overall progress never
+	// decreases, even if the reported phase progress does.
+	percent := t.percent
+	if t.total > 0 {
+		var done float64
+		for _, weight := range t.weights[:phaseIndex] {
+			done += weight
+		}
+		done += t.weights[phaseIndex] * float64(clampPercent(phasePercent)) / 100
+
+		if current := 100 * done / t.total; current > percent {
+			percent = current
+		}
+	}
+
+	changed := percent != t.percent
+	t.percent = percent
+
+	return t.progress(phasePercent, now), changed || phaseChanged
+}
+
+// finish marks the end of the conversion and returns the final overall
+// progress. The phase durations of successful conversions are used for
+// learning the phase weights.
+func (t *progressTracker) finish(success bool) (Progress, bool) {
+	if t == nil || t.phase < 0 {
+		return Progress{}, false
+	}
+
+	now := time.Now()
+	t.durations[t.phase] += now.Sub(t.phaseStart)
+	t.phaseStart = now
+
+	if !success {
+		return t.progress(0, now), false
+	}
+
+	t.estimator.learn(t.phases, t.durations, t.objectCount)
+	changed := t.percent != 100
+	t.percent = 100
+
+	return t.progress(100, now), changed
+}
+
+func (t *progressTracker) progress(phasePercent int, now time.Time) Progress {
+	progress := Progress{
+		Percent:          t.percent,
+		PhaseIndex:       t.phase,
+		PhaseDescription: t.phases[t.phase],
+		PhasePercent:     clampPercent(phasePercent),
+		ObjectCount:      t.objectCount,
+		Elapsed:          now.Sub(t.start),
+	}
+	if t.percent > 0 && t.percent < 100 {
+		progress.ETA = time.Duration(float64(progress.Elapsed) * (100 - t.percent) / t.percent)
+	}
+
+	return progress
+}
+
+func clampPercent(percent int) int {
+	switch {
+	case percent < 0:
+		return 0
+	case percent > 100:
+		return 100
+	}
+
+	return percent
+}
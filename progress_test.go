@@ -0,0 +1,136 @@
+package pdf
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestProgressEstimatorWeights(t *testing.T) {
+	phases := []string{"Loading pages", "Printing pages", "Custom phase"}
+
+	tests := []struct {
+		name        string
+		estimator   *ProgressEstimator
+		samples     [][2]int
+		objectCount int
+		expected    []float64
+	}{
+		{
+			name:        "defaults",
+			estimator:   NewProgressEstimator(),
+			objectCount: 3,
+			expected:    []float64{60, 15, defaultPhaseWeight},
+		},
+		{
+			name:        "configured",
+			estimator:   &ProgressEstimator{Weights: map[string]float64{"Loading pages": 80, "Printing pages": 20}, Learn: true},
+			samples:     [][2]int{{1, 1}},
+			objectCount: 3,
+			expected:    []float64{80, 20, 0},
+		},
+		{
+			name:        "learning disabled",
+			estimator:   &ProgressEstimator{},
+			samples:     [][2]int{{1, 1}},
+			objectCount: 3,
+			expected:    []float64{60, 15, defaultPhaseWeight},
+		},
+		{
+			// A single object count cannot separate the fixed part from
+			// the per object part, so the mean durations are used.
+			name:        "single object count",
+			estimator:   NewProgressEstimator(),
+			samples:     [][2]int{{2, 2}, {2, 2}},
+			objectCount: 10,
+			expected:    []float64{1 + 2*2, 2, 1},
+		},
+		{
+			name:        "fixed and per object durations",
+			estimator:   NewProgressEstimator(),
+			samples:     [][2]int{{1, 1}, {4, 4}, {2, 2}},
+			objectCount: 10,
+			expected:    []float64{1 + 2*10, 2, 1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// The duration of the first phase consists of a fixed second
+			// and two seconds per object, the second phase takes two
+			// seconds and the third phase one second.
+			for _, sample := range test.samples {
+				n := sample[0]
+				test.estimator.learn(phases, []time.Duration{
+					time.Second + time.Duration(2*n)*time.Second,
+					2 * time.Second,
+					time.Second,
+				}, n)
+			}
+
+			weights := test.estimator.weights(phases, test.objectCount)
+			for i, weight := range weights {
+				if math.Abs(weight-test.expected[i]) > 1e-6 {
+					t.Errorf("expected weights %v, got %v", test.expected, weights)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestPhaseModelIntercept(t *testing.T) {
+	// Durations proportional to the object count, with a negative fitted
+	// intercept, are modeled as a line through the origin.
+	var m phaseModel
+	m.add(1, 0.5)
+	m.add(2, 3)
+	m.add(3, 5.5)
+
+	if estimate := m.estimate(0); estimate != 0 {
+		t.Errorf("expected no fixed duration, got %v", estimate)
+	}
+	if estimate := m.estimate(4); estimate <= 5.5 {
+		t.Errorf("expected estimate to grow with the object count, got %v", estimate)
+	}
+}
+
+func TestProgressTracker(t *testing.T) {
+	estimator := &ProgressEstimator{Weights: map[string]float64{"A": 1, "B": 3}}
+	tracker := newProgressTracker(estimator, []string{"A", "B"}, 2)
+
+	tests := []struct {
+		phase    int
+		percent  int
+		expected float64
+		changed  bool
+	}{
+		{phase: 0, percent: 0, expected: 0, changed: true},
+		{phase: 0, percent: 50, expected: 12.5, changed: true},
+		{phase: 0, percent: 50, expected: 12.5, changed: false},
+		{phase: 0, percent: 20, expected: 12.5, changed: false},
+		{phase: 1, percent: 0, expected: 25, changed: true},
+		{phase: 1, percent: 200, expected: 100, changed: true},
+		{phase: 2, percent: 0, expected: 0, changed: false},
+	}
+
+	for _, test := range tests {
+		progress, changed := tracker.update(test.phase, test.percent)
+		if changed != test.changed {
+			t.Errorf("phase %d at %d%%: expected changed %t, got %t", test.phase, test.percent, test.changed, changed)
+		}
+		if progress.Percent != test.expected {
+			t.Errorf("phase %d at %d%%: expected %v%%, got %v%%", test.phase, test.percent, test.expected, progress.Percent)
+		}
+	}
+
+	progress, _ := tracker.finish(true)
+	if progress.Percent != 100 || progress.PhaseDescription != "B" || progress.ObjectCount != 2 || progress.ETA != 0 {
+		t.Errorf("unexpected final progress: %+v", progress)
+	}
+
+	var nilTracker *progressTracker
+	if _, changed := nilTracker.update(0, 0); changed {
+		t.Error("expected nil tracker not to report changes")
+	}
+}
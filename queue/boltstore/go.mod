@@ -0,0 +1,16 @@
+module github.com/adrg/go-wkhtmltopdf/queue/boltstore
+
+go 1.19
+
+require (
+	github.com/adrg/go-wkhtmltopdf v0.0.0-20261019103220-3b7e8cc98ca2
+	go.etcd.io/bbolt v1.3.8
+)
+
+require (
+	golang.org/x/sys v0.10.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// The parent module is replaced with the local copy during development.
+replace github.com/adrg/go-wkhtmltopdf => ../..
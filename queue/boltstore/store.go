@@ -0,0 +1,112 @@
+/*
+Package boltstore implements a queue.Store which persists jobs in a BoltDB
+database file. It is a separate module, so that users of the core package
+do not depend on BoltDB.
+*/
+package boltstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/adrg/go-wkhtmltopdf/queue"
+	bolt "go.etcd.io/bbolt"
+)
+
+// bucket is the name of the bucket which contains the jobs.
+var bucket = []byte("jobs")
+
+// Store is a queue.Store implementation which persists jobs in a BoltDB
+// database file.
+type Store struct {
+	db *bolt.DB
+}
+
+// New returns a new store which persists jobs in the BoltDB database at
+// the specified path. The database is created if it does not exist. The
+// store must be closed when it is no longer used.
+func New(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Put creates or updates the specified job.
+func (s *Store) Put(ctx context.Context, job *queue.Job) error {
+	if job.ID == "" {
+		return errors.New("invalid job ID")
+	}
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Put([]byte(job.ID), data)
+	})
+}
+
+// Get returns the job with the specified ID.
+func (s *Store) Get(ctx context.Context, id string) (*queue.Job, error) {
+	var job *queue.Job
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucket).Get([]byte(id))
+		if data == nil {
+			return queue.ErrNotFound
+		}
+
+		job = &queue.Job{}
+		return json.Unmarshal(data, job)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// Delete removes the job with the specified ID.
+func (s *Store) Delete(ctx context.Context, id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Delete([]byte(id))
+	})
+}
+
+// List returns all the jobs in the store.
+func (s *Store) List(ctx context.Context) ([]*queue.Job, error) {
+	var jobs []*queue.Job
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).ForEach(func(_, data []byte) error {
+			job := &queue.Job{}
+			if err := json.Unmarshal(data, job); err != nil {
+				return err
+			}
+
+			jobs = append(jobs, job)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
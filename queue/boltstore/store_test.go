@@ -0,0 +1,93 @@
+package boltstore
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	pdf "github.com/adrg/go-wkhtmltopdf"
+	"github.com/adrg/go-wkhtmltopdf/queue"
+)
+
+// Check that Store implements the queue.Store interface.
+var _ queue.Store = (*Store)(nil)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "jobs.db")
+
+	store, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jobs := []*queue.Job{
+		testJob("a", queue.StatusPending),
+		testJob("b", queue.StatusFailed),
+		testJob("c", queue.StatusSucceeded),
+	}
+	for _, job := range jobs {
+		if err := store.Put(ctx, job); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Update and delete jobs.
+	jobs[0].Status, jobs[0].Attempts = queue.StatusRunning, 1
+	if err := store.Put(ctx, jobs[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(ctx, "c"); err != nil {
+		t.Fatal(err)
+	}
+	jobs = jobs[:2]
+
+	// Reload the jobs from the database.
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if store, err = New(path); err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	listed, err := store.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Slice(listed, func(i, j int) bool { return listed[i].ID < listed[j].ID })
+	if !reflect.DeepEqual(listed, jobs) {
+		t.Errorf("expected jobs %+v, got %+v", jobs, listed)
+	}
+
+	job, err := store.Get(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(job, jobs[0]) {
+		t.Errorf("expected job %+v, got %+v", jobs[0], job)
+	}
+	if _, err := store.Get(ctx, "c"); !errors.Is(err, queue.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if err := store.Put(ctx, testJob("", queue.StatusPending)); err == nil {
+		t.Error("expected error for empty job ID")
+	}
+}
+
+func testJob(id string, status queue.Status) *queue.Job {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	return &queue.Job{
+		ID:          id,
+		Spec:        *pdf.NewJob(pdf.ObjectSpec{URL: "https://example.com/" + id}),
+		Status:      status,
+		NextAttempt: created,
+		CreatedAt:   created,
+		UpdatedAt:   created,
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Package queue implements a durable background queue for conversion jobs.
+
+Jobs are declarative conversion specifications (see pdf.Job), persisted in
+a pluggable store, so pending jobs survive process restarts. Failed jobs are
+retried with exponential backoff, and the output documents of successful
+jobs are written to a pluggable blob sink. The jobs are executed by the
+Run method, which must be called on the main thread, due to a limitation
+of the `wkhtmltox` library. Jobs can be persisted in a directory, using
+FileStore, or in a BoltDB database, using the store provided by the
+separate github.com/adrg/go-wkhtmltopdf/queue/boltstore module.
+
+Example
+
+	func init() {
+		runtime.LockOSThread()
+	}
+
+	func main() {
+		if err := pdf.Init(); err != nil {
+			log.Fatal(err)
+		}
+		defer pdf.Destroy()
+
+		store, err := boltstore.New("jobs.db")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer store.Close()
+
+		q := queue.New(store, queue.NewFileSink("output"), nil)
+
+		// Submit jobs from any goroutine.
+		go func() {
+			job := pdf.NewJob(pdf.ObjectSpec{URL: "https://example.com"})
+			if _, err := q.Submit(context.Background(), job); err != nil {
+				log.Println(err)
+			}
+		}()
+
+		// Execute jobs on the main thread, until interrupted.
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		if err := q.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			log.Fatal(err)
+		}
+	}
+*/
+package queue
+
+import (
+	"errors"
+	"time"
+
+	pdf "github.com/adrg/go-wkhtmltopdf"
+)
+
+// ErrNotFound is returned by stores when a job does not exist.
+var ErrNotFound = errors.New("job not found")
+
+// Status defines the states of queued jobs.
+type Status string
+
+// Status values.
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// Job represents a queued conversion job.
+type Job struct {
+	// The unique identifier of the job. The output document of the job is
+	// written to the blob sink using the ID as key.
+	ID string `json:"id" yaml:"id"`
+
+	// The conversion specification.
+	Spec pdf.Job `json:"spec" yaml:"spec"`
+
+	// The current status of the job.
+	Status Status `json:"status" yaml:"status"`
+
+	// The number of times the job was attempted.
+	Attempts int `json:"attempts" yaml:"attempts"`
+
+	// The error of the last failed attempt.
+	LastError string `json:"lastError,omitempty" yaml:"lastError,omitempty"`
+
+	// The time after which the job is attempted again.
+	NextAttempt time.Time `json:"nextAttempt" yaml:"nextAttempt"`
+
+	// The creation time of the job.
+	CreatedAt time.Time `json:"createdAt" yaml:"createdAt"`
+
+	// The time of the last job update.
+	UpdatedAt time.Time `json:"updatedAt" yaml:"updatedAt"`
+}
@@ -0,0 +1,354 @@
+//go:build cgo
+
+package queue
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	pdf "github.com/adrg/go-wkhtmltopdf"
+)
+
+// Options contains the configuration of a queue.
+type Options struct {
+	// The maximum number of attempts for each job. If not specified,
+	// jobs are attempted 3 times.
+	// E.g.: 5.
+	MaxAttempts int
+
+	// Returns the delay before retrying a job, based on the number of
+	// failed attempts. If not specified, an exponential backoff is used,
+	// starting at 1 second and capped at 5 minutes.
+	Backoff func(attempts int) time.Duration
+
+	// The interval at which the store is checked for jobs which are not
+	// submitted through the queue, and for finished jobs to be pruned.
+	// Jobs submitted through the queue are picked up immediately.
+	// If not specified, 5 seconds is used.
+	// E.g.: time.Second.
+	PollInterval time.Duration
+
+	// The amount of time finished (succeeded or failed) jobs are retained
+	// in the store, after which they are deleted. The output documents
+	// written to the sink are not deleted. If not specified, finished jobs
+	// are retained for 7 days. A negative value disables pruning.
+	// E.g.: 24 * time.Hour.
+	Retention time.Duration
+}
+
+// Queue executes conversion jobs persisted in a store, writing their
+// output documents to a blob sink.
+type Queue struct {
+	store Store
+	sink  Sink
+	opts  Options
+	wake  chan struct{}
+
+	// The index of the pending jobs, keyed by ID, used for scheduling jobs
+	// without listing the store.
+	mu      sync.Mutex
+	pending map[string]*Job
+}
+
+// New returns a new queue, which uses the specified store and sink.
+// If no options are provided, the defaults are used. See Options for
+// the default values.
+func New(store Store, sink Sink, opts *Options) *Queue {
+	q := &Queue{
+		store:   store,
+		sink:    sink,
+		wake:    make(chan struct{}, 1),
+		pending: map[string]*Job{},
+	}
+	if opts != nil {
+		q.opts = *opts
+	}
+	if q.opts.MaxAttempts <= 0 {
+		q.opts.MaxAttempts = 3
+	}
+	if q.opts.Backoff == nil {
+		q.opts.Backoff = ExponentialBackoff(time.Second, 5*time.Minute)
+	}
+	if q.opts.PollInterval <= 0 {
+		q.opts.PollInterval = 5 * time.Second
+	}
+	if q.opts.Retention == 0 {
+		q.opts.Retention = 7 * 24 * time.Hour
+	}
+
+	return q
+}
+
+// ExponentialBackoff returns a backoff function, which doubles the delay
+// after each failed attempt, starting at the specified base delay, without
+// exceeding the specified maximum delay.
+func ExponentialBackoff(base, max time.Duration) func(attempts int) time.Duration {
+	return func(attempts int) time.Duration {
+		delay := base
+		for i := 1; i < attempts && delay < max; i++ {
+			delay *= 2
+		}
+		if delay > max {
+			delay = max
+		}
+
+		return delay
+	}
+}
+
+// Submit adds a job with the specified conversion specification to the
+// queue and returns its ID. It can be called from any goroutine.
+func (q *Queue) Submit(ctx context.Context, spec *pdf.Job) (string, error) {
+	if spec == nil {
+		return "", errors.New("the provided job cannot be nil")
+	}
+	if len(spec.Objects) == 0 {
+		return "", errors.New("must specify at least one object to convert")
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	job := &Job{
+		ID:          hex.EncodeToString(id),
+		Spec:        *spec,
+		Status:      StatusPending,
+		NextAttempt: now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := q.put(ctx, job); err != nil {
+		return "", err
+	}
+
+	// Notify the worker.
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+
+	return job.ID, nil
+}
+
+// Job returns the job with the specified ID.
+func (q *Queue) Job(ctx context.Context, id string) (*Job, error) {
+	return q.store.Get(ctx, id)
+}
+
+// Run executes the queued jobs until the context is done, in which case
+// the context error is returned. Jobs which were running when the process
+// was previously stopped are resumed. Due to a limitation of the `wkhtmltox`
+// library, this method must be called on the main thread, after the library
+// is initialized.
+func (q *Queue) Run(ctx context.Context) error {
+	if err := q.refresh(ctx, true); err != nil {
+		return err
+	}
+
+	refreshed := time.Now()
+	for {
+		if time.Since(refreshed) >= q.opts.PollInterval {
+			if err := q.refresh(ctx, false); err != nil {
+				return err
+			}
+			refreshed = time.Now()
+		}
+
+		job, wait, err := q.next(ctx)
+		if err != nil {
+			return err
+		}
+		if job != nil {
+			if err := q.process(ctx, job); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if poll := q.opts.PollInterval - time.Since(refreshed); poll < wait {
+			wait = poll
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-q.wake:
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+		timer.Stop()
+	}
+}
+
+// refresh adds the pending jobs in the store to the index of pending jobs
+// and deletes the finished jobs which exceeded the retention period. If
+// recovering, the jobs which were interrupted while running are marked as
+// pending. Interrupted attempts count as failed attempts, so that jobs which
+// crash the process are not retried indefinitely.
+func (q *Queue) refresh(ctx context.Context, recovering bool) error {
+	jobs, err := q.store.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, job := range jobs {
+		switch job.Status {
+		case StatusPending:
+			q.index(job)
+		case StatusRunning:
+			if !recovering {
+				continue
+			}
+			if err := q.fail(ctx, job, errors.New("job interrupted")); err != nil {
+				return err
+			}
+		case StatusSucceeded, StatusFailed:
+			if q.opts.Retention < 0 || now.Sub(job.UpdatedAt) < q.opts.Retention {
+				continue
+			}
+			if err := q.store.Delete(ctx, job.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// next returns the oldest indexed job which is due for execution. If no job
+// is due, it returns the amount of time to wait before checking again.
+func (q *Queue) next(ctx context.Context) (*Job, time.Duration, error) {
+	for {
+		now, wait := time.Now(), q.opts.PollInterval
+
+		q.mu.Lock()
+		var due *Job
+		for _, job := range q.pending {
+			if job.NextAttempt.After(now) {
+				if delay := job.NextAttempt.Sub(now); delay < wait {
+					wait = delay
+				}
+				continue
+			}
+			if due == nil || job.CreatedAt.Before(due.CreatedAt) {
+				due = job
+			}
+		}
+		q.mu.Unlock()
+
+		if due == nil {
+			return nil, wait, nil
+		}
+
+		// Reload the job, as it may have been changed or deleted since
+		// it was indexed.
+		job, err := q.store.Get(ctx, due.ID)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, 0, err
+		}
+		if err == nil && job.Status == StatusPending {
+			return job, 0, nil
+		}
+		q.unindex(due.ID)
+	}
+}
+
+// put persists the specified job and updates the index of pending jobs.
+func (q *Queue) put(ctx context.Context, job *Job) error {
+	if err := q.store.Put(ctx, job); err != nil {
+		return err
+	}
+	q.index(job)
+
+	return nil
+}
+
+// index adds the specified job to the index of pending jobs, or removes it,
+// if it is not pending.
+func (q *Queue) index(job *Job) {
+	if job.Status != StatusPending {
+		q.unindex(job.ID)
+		return
+	}
+
+	q.mu.Lock()
+	q.pending[job.ID] = job
+	q.mu.Unlock()
+}
+
+// unindex removes the job with the specified ID from the index of pending
+// jobs.
+func (q *Queue) unindex(id string) {
+	q.mu.Lock()
+	delete(q.pending, id)
+	q.mu.Unlock()
+}
+
+// process executes the specified job and updates its status. Only store
+// errors are returned, as conversion and sink errors cause the job to be
+// retried.
+func (q *Queue) process(ctx context.Context, job *Job) error {
+	job.Status = StatusRunning
+	job.Attempts++
+	job.UpdatedAt = time.Now()
+	if err := q.put(ctx, job); err != nil {
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := pdf.RunJob(ctx, &job.Spec, buf); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			// The conversion was interrupted, so the attempt is not counted.
+			job.Status, job.Attempts = StatusPending, job.Attempts-1
+			job.UpdatedAt = time.Now()
+			if err := q.put(context.Background(), job); err != nil {
+				return err
+			}
+			return ctxErr
+		}
+		return q.fail(ctx, job, err)
+	}
+	if err := q.sink.Write(ctx, job.ID, buf.Bytes()); err != nil {
+		// The job was executed, so the attempt is counted, even if writing
+		// its output was interrupted.
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			if err := q.fail(context.Background(), job, err); err != nil {
+				return err
+			}
+			return ctxErr
+		}
+		return q.fail(ctx, job, err)
+	}
+
+	job.Status, job.LastError = StatusSucceeded, ""
+	job.UpdatedAt = time.Now()
+	return q.put(ctx, job)
+}
+
+// fail records a failed attempt of the specified job, scheduling the job
+// for retry, if it has attempts left.
+func (q *Queue) fail(ctx context.Context, job *Job, err error) error {
+	now := time.Now()
+
+	job.LastError, job.UpdatedAt = err.Error(), now
+	if job.Attempts >= q.opts.MaxAttempts {
+		job.Status = StatusFailed
+		job.LastError = fmt.Sprintf("%s (after %d attempts)", job.LastError, job.Attempts)
+	} else {
+		job.Status = StatusPending
+		job.NextAttempt = now.Add(q.opts.Backoff(job.Attempts))
+	}
+
+	return q.put(ctx, job)
+}
@@ -0,0 +1,127 @@
+//go:build cgo
+
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestQueueRefresh(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jobs := []*Job{
+		testJob("pending", StatusPending, now.Add(-time.Minute)),
+		testJob("running", StatusRunning, now.Add(-2*time.Minute)),
+		testJob("old", StatusSucceeded, now.Add(-2*time.Hour)),
+		testJob("recent", StatusFailed, now.Add(-time.Minute)),
+	}
+	for _, job := range jobs {
+		if err := store.Put(ctx, job); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	q := New(store, NewFileSink(t.TempDir()), &Options{Retention: time.Hour})
+	if err := q.refresh(ctx, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// Finished jobs are pruned after the retention period.
+	if _, err := store.Get(ctx, "old"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected old job to be pruned, got %v", err)
+	}
+	if _, err := store.Get(ctx, "recent"); err != nil {
+		t.Errorf("expected recent job to be retained, got %v", err)
+	}
+
+	// Interrupted jobs are scheduled for retry.
+	running, err := store.Get(ctx, "running")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if running.Status != StatusPending || running.Attempts != 0 || running.LastError != "job interrupted" {
+		t.Errorf("unexpected interrupted job: %+v", running)
+	}
+	if len(q.pending) != 2 {
+		t.Errorf("expected 2 indexed jobs, got %d", len(q.pending))
+	}
+}
+
+func TestQueueNext(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := New(store, NewFileSink(t.TempDir()), &Options{PollInterval: time.Hour})
+
+	// Index jobs, in a different order than the creation order.
+	second := testJob("second", StatusPending, now.Add(-time.Minute))
+	first := testJob("first", StatusPending, now.Add(-2*time.Minute))
+	later := testJob("later", StatusPending, now.Add(-3*time.Minute))
+	later.NextAttempt = now.Add(time.Minute)
+	for _, job := range []*Job{second, first, later} {
+		if err := q.put(ctx, job); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Jobs deleted or changed in the store are removed from the index.
+	if err := store.Delete(ctx, "first"); err != nil {
+		t.Fatal(err)
+	}
+	second.Status = StatusSucceeded
+	if err := store.Put(ctx, second); err != nil {
+		t.Fatal(err)
+	}
+
+	job, wait, err := q.next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job != nil {
+		t.Errorf("expected no due job, got %s", job.ID)
+	}
+	if wait <= 0 || wait > time.Minute {
+		t.Errorf("expected to wait for the next attempt, got %v", wait)
+	}
+	if len(q.pending) != 1 {
+		t.Errorf("expected 1 indexed job, got %d", len(q.pending))
+	}
+
+	// Due jobs are returned in creation order.
+	first.NextAttempt, later.NextAttempt = now, now
+	for _, job := range []*Job{first, later} {
+		if err := q.put(ctx, job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if job, _, err = q.next(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if job == nil || job.ID != "later" {
+		t.Errorf("expected the oldest job to be due, got %+v", job)
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(time.Second, 5*time.Second)
+
+	expected := []time.Duration{time.Second, time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for attempts, delay := range expected {
+		if actual := backoff(attempts); actual != delay {
+			t.Errorf("attempt %d: expected delay %v, got %v", attempts, delay, actual)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package queue
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+)
+
+// Sink stores the output documents of successful jobs.
+// Implementations must be safe for concurrent use.
+type Sink interface {
+	// Write stores the specified data using the provided key. Existing
+	// data stored using the same key is replaced.
+	Write(ctx context.Context, key string, data []byte) error
+}
+
+// FileSink is a Sink implementation which writes output documents to
+// files in a directory, named after the keys of the documents.
+type FileSink struct {
+	// The directory the output documents are written to.
+	Dir string
+
+	// The extension of the output files. If not specified, ".pdf" is used.
+	// E.g.: ".ps".
+	Extension string
+}
+
+// NewFileSink returns a new sink which writes output documents to the
+// specified directory.
+func NewFileSink(dir string) *FileSink {
+	return &FileSink{Dir: dir}
+}
+
+// Write writes the specified data to the file named after the provided key.
+// The directory of the sink is created if it does not exist. Files are
+// written atomically, so partially written documents are never exposed.
+func (s *FileSink) Write(ctx context.Context, key string, data []byte) error {
+	if err := validateID(key); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(s.Dir, ".output-*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name()) // nolint:errcheck
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name()) // nolint:errcheck
+		return err
+	}
+
+	ext := s.Extension
+	if ext == "" {
+		ext = ".pdf"
+	}
+	return os.Rename(f.Name(), filepath.Join(s.Dir, key+ext))
+}
@@ -0,0 +1,162 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Store persists queued jobs. Implementations must be safe for concurrent use.
+type Store interface {
+	// Put creates or updates the specified job.
+	Put(ctx context.Context, job *Job) error
+
+	// Get returns the job with the specified ID, or ErrNotFound if the job
+	// does not exist.
+	Get(ctx context.Context, id string) (*Job, error)
+
+	// Delete removes the job with the specified ID.
+	Delete(ctx context.Context, id string) error
+
+	// List returns all the jobs in the store.
+	List(ctx context.Context) ([]*Job, error)
+}
+
+// FileStore is a Store implementation which persists each job as a JSON
+// file in a directory.
+type FileStore struct {
+	dir string
+	mu  sync.RWMutex
+}
+
+// NewFileStore returns a new store which persists jobs in the specified
+// directory. The directory is created if it does not exist.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &FileStore{dir: dir}, nil
+}
+
+// Put creates or updates the specified job. Jobs are written atomically,
+// so they are not corrupted if the process is interrupted.
+func (s *FileStore) Put(ctx context.Context, job *Job) error {
+	if err := validateID(job.ID); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.CreateTemp(s.dir, ".job-*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name()) // nolint:errcheck
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(f.Name()) // nolint:errcheck
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name()) // nolint:errcheck
+		return err
+	}
+
+	return os.Rename(f.Name(), s.path(job.ID))
+}
+
+// Get returns the job with the specified ID.
+func (s *FileStore) Get(ctx context.Context, id string) (*Job, error) {
+	if err := validateID(id); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.read(s.path(id))
+}
+
+// Delete removes the job with the specified ID.
+func (s *FileStore) Delete(ctx context.Context, id string) error {
+	if err := validateID(id); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.path(id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List returns all the jobs in the store.
+func (s *FileStore) List(ctx context.Context) ([]*Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*Job, 0, len(paths))
+	for _, path := range paths {
+		job, err := s.read(path)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+func (s *FileStore) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+func (s *FileStore) read(path string) (*Job, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	job := &Job{}
+	if err := json.Unmarshal(data, job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// validateID checks that the specified job ID can be used as a file name
+// or storage key.
+func validateID(id string) error {
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return errors.New("invalid job ID")
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	pdf "github.com/adrg/go-wkhtmltopdf"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jobs := []*Job{
+		testJob("a", StatusPending, time.Unix(1, 0)),
+		testJob("b", StatusFailed, time.Unix(2, 0)),
+		testJob("c", StatusSucceeded, time.Unix(3, 0)),
+	}
+	for _, job := range jobs {
+		if err := store.Put(ctx, job); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Update job.
+	jobs[0].Status, jobs[0].Attempts = StatusRunning, 1
+	if err := store.Put(ctx, jobs[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	// Delete job.
+	if err := store.Delete(ctx, "c"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(ctx, "c"); err != nil {
+		t.Errorf("expected deleting a missing job to succeed, got %v", err)
+	}
+	jobs = jobs[:2]
+
+	// Reload the jobs using a new store.
+	store, err = NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listed, err := store.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Slice(listed, func(i, j int) bool { return listed[i].ID < listed[j].ID })
+	if !reflect.DeepEqual(listed, jobs) {
+		t.Errorf("expected jobs %+v, got %+v", jobs, listed)
+	}
+
+	job, err := store.Get(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(job, jobs[0]) {
+		t.Errorf("expected job %+v, got %+v", jobs[0], job)
+	}
+	if _, err := store.Get(ctx, "c"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	// Check that no temporary files are left behind.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(jobs) {
+		t.Errorf("expected %d files, got %d", len(jobs), len(entries))
+	}
+}
+
+func TestFileStoreInvalidID(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{"", "../job", `a\b`, "a.json"} {
+		if err := store.Put(ctx, testJob(id, StatusPending, time.Now())); err == nil {
+			t.Errorf("expected error when putting job with ID %q", id)
+		}
+		if _, err := store.Get(ctx, id); err == nil {
+			t.Errorf("expected error when getting job with ID %q", id)
+		}
+		if err := store.Delete(ctx, id); err == nil {
+			t.Errorf("expected error when deleting job with ID %q", id)
+		}
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	tests := []struct {
+		sink *FileSink
+		file string
+	}{
+		{sink: NewFileSink(dir), file: "job.pdf"},
+		{sink: &FileSink{Dir: dir, Extension: ".ps"}, file: "job.ps"},
+	}
+
+	for _, test := range tests {
+		if err := test.sink.Write(ctx, "job", []byte("data")); err != nil {
+			t.Fatal(err)
+		}
+		if err := test.sink.Write(ctx, "job", []byte("replaced")); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, test.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "replaced" {
+			t.Errorf("expected replaced data, got %q", data)
+		}
+	}
+
+	if err := NewFileSink(dir).Write(ctx, "../job", nil); err == nil {
+		t.Error("expected error for invalid key")
+	}
+}
+
+func testJob(id string, status Status, created time.Time) *Job {
+	created = created.UTC()
+
+	return &Job{
+		ID:          id,
+		Spec:        *pdf.NewJob(pdf.ObjectSpec{URL: "https://example.com/" + id}),
+		Status:      status,
+		NextAttempt: created,
+		CreatedAt:   created,
+		UpdatedAt:   created,
+	}
+}
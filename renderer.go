@@ -0,0 +1,63 @@
+package pdf
+
+import (
+	"errors"
+	"io"
+)
+
+var (
+	// ErrAlreadyRun is returned when running a converter which has already
+	// performed a conversion, or when adding objects to it.
+	ErrAlreadyRun = errors.New("converter has already been run")
+
+	// ErrObjectOwned is returned when adding an object to a converter, if the
+	// object has already been added to another converter.
+	ErrObjectOwned = errors.New("object is owned by another converter")
+)
+
+// Callbacks contains the functions called during the conversion process.
+// The functions are optional.
+type Callbacks struct {
+	// Warning is called when a warning is issued in the conversion process.
+	Warning func(msg string)
+
+	// Error is called when an error is encountered in the conversion process.
+	Error func(msg string)
+
+	// PhaseChanged is called when the conversion phase changes.
+	PhaseChanged func(phaseIndex int)
+
+	// ProgressChanged is called when the conversion progress changes.
+	// The progress is reported for each conversion phase.
+	ProgressChanged func(progressPercent int)
+
+	// Finished is called when the conversion process ends.
+	Finished func(success bool)
+
+	// OverallProgressChanged is called when the overall progress of the
+	// conversion changes. Unlike ProgressChanged, the progress is aggregated
+	// across all conversion phases, so it increases monotonically.
+	OverallProgressChanged func(progress Progress)
+}
+
+// Renderer is implemented by types which convert HTML documents, such as
+// Converter. Code which depends on the Renderer interface, instead of on
+// Converter, can be tested without performing actual conversions, using
+// a fake implementation such as pdftest.FakeRenderer. Objects can be
+// created without the `wkhtmltox` library only when cgo is disabled.
+type Renderer interface {
+	// Add appends the specified object to the list of objects to be
+	// converted. The renderer takes ownership of the object.
+	Add(object *Object) error
+
+	// Run performs the conversion and copies the output to the provided
+	// writer. A renderer can only perform a single conversion.
+	Run(w io.Writer) error
+
+	// SetCallbacks sets the functions called during the conversion process.
+	SetCallbacks(callbacks Callbacks)
+
+	// Destroy releases all resources used by the renderer, including the
+	// objects added to it.
+	Destroy()
+}
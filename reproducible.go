@@ -0,0 +1,90 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/md5"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adrg/go-wkhtmltopdf/internal/pdfdoc"
+)
+
+// sourceDateEpoch returns the time specified by the SOURCE_DATE_EPOCH
+// environment variable, as defined by the reproducible builds project.
+// If the variable is not defined, the zero time is returned.
+func sourceDateEpoch() (time.Time, error) {
+	value := strings.TrimSpace(os.Getenv("SOURCE_DATE_EPOCH"))
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH value: %q", value)
+	}
+
+	return time.Unix(seconds, 0).UTC(), nil
+}
+
+// normalizeDates replaces the creation and modification dates of the
+// specified document with the time specified by SOURCE_DATE_EPOCH. If the
+// variable is not defined, the dates are removed. The dates must be
+// normalized before the document metadata is derived from them.
+func normalizeDates(doc *pdfdoc.Document) error {
+	epoch, err := sourceDateEpoch()
+	if err != nil {
+		return err
+	}
+
+	info := doc.Info()
+	if info == nil {
+		if epoch.IsZero() {
+			return nil
+		}
+		info = pdfdoc.Dict{}
+	}
+	info = info.Clone()
+
+	for _, key := range []pdfdoc.Name{"CreationDate", "ModDate"} {
+		_, ok := info[key]
+		switch {
+		case epoch.IsZero():
+			delete(info, key)
+		case ok || key == "CreationDate":
+			info[key] = pdfdoc.String(pdfdoc.FormatDate(epoch))
+		}
+	}
+
+	if ref, ok := doc.Trailer["Info"].(pdfdoc.Ref); ok {
+		doc.Set(ref, info)
+	} else {
+		doc.Trailer["Info"] = info
+	}
+
+	return nil
+}
+
+// writeReproducible writes the specified document, using an identifier
+// derived from the content of the document, instead of a random or time
+// based one.
+func writeReproducible(doc *pdfdoc.Document) ([]byte, error) {
+	delete(doc.Trailer, "ID")
+
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		return nil, err
+	}
+
+	id := md5.Sum(buf.Bytes())
+	doc.Trailer["ID"] = pdfdoc.Array{pdfdoc.HexString(id[:]), pdfdoc.HexString(id[:])}
+
+	buf.Reset()
+	if err := doc.Write(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
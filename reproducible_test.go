@@ -0,0 +1,127 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/adrg/go-wkhtmltopdf/internal/pdfdoc"
+)
+
+func TestReproducibleOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		epoch  string
+		modify func(opts *ConverterOpts)
+		date   string
+	}{
+		{
+			name: "default",
+		},
+		{
+			name:  "source date epoch",
+			epoch: "1700000000",
+			date:  "D:20231114221320Z",
+		},
+		{
+			name: "outline",
+			modify: func(opts *ConverterOpts) {
+				opts.Outline = []OutlineItem{{Title: "Custom", Page: 1}}
+			},
+		},
+		{
+			name:  "conformance",
+			epoch: "1700000000",
+			modify: func(opts *ConverterOpts) {
+				opts.Conformance = PDFA2B
+			},
+			date: "D:20231114221320Z",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("SOURCE_DATE_EPOCH", test.epoch)
+
+			opts := NewConverterOpts()
+			opts.Reproducible = true
+			if test.modify != nil {
+				test.modify(opts)
+			}
+
+			// Identical inputs generated at different times, with different
+			// identifiers.
+			first, err := opts.postProcess(libraryOutput("Report", "D:20240101120000+02'00", "0011"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			second, err := opts.postProcess(libraryOutput("Report", "D:20250607080910Z", "FFEE"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(first, second) {
+				t.Fatalf("expected identical output documents:\n%s\n\n%s", first, second)
+			}
+
+			doc, err := pdfdoc.Parse(first)
+			if err != nil {
+				t.Fatal(err)
+			}
+			info := doc.Info()
+			if date, _ := info.Text("CreationDate"); date != test.date {
+				t.Errorf("expected creation date %q, got %q", test.date, date)
+			}
+			if date, _ := info.Text("ModDate"); date != test.date {
+				t.Errorf("expected modification date %q, got %q", test.date, date)
+			}
+			if id, ok := doc.Trailer["ID"].(pdfdoc.Array); !ok || len(id) != 2 {
+				t.Errorf("expected document identifier, got %v", doc.Trailer["ID"])
+			}
+
+			// Different inputs produce different output documents.
+			other, err := opts.postProcess(libraryOutput("Other report", "D:20240101120000+02'00", "0011"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Equal(first, other) {
+				t.Error("expected different output documents for different inputs")
+			}
+			otherDoc, err := pdfdoc.Parse(other)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(doc.Trailer["ID"]) == fmt.Sprint(otherDoc.Trailer["ID"]) {
+				t.Error("expected different identifiers for different inputs")
+			}
+		})
+	}
+}
+
+func TestReproducibleOutputInvalidEpoch(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "yesterday")
+
+	opts := NewConverterOpts()
+	opts.Reproducible = true
+	if _, err := opts.postProcess(libraryOutput("Report", "D:20240101120000Z", "0011")); err == nil {
+		t.Error("expected error for invalid SOURCE_DATE_EPOCH")
+	}
+}
+
+// libraryOutput returns a document similar to the ones generated by the
+// `wkhtmltox` library, with the specified title, dates and identifier.
+func libraryOutput(title, date, id string) []byte {
+	data := testDocument([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Resources << /Font << /F1 4 0 R >> >> /Contents 7 0 R >>",
+		"<< /Type /Font /Subtype /TrueType /BaseFont /ABCDEF+DejaVuSans /FontDescriptor 5 0 R >>",
+		"<< /Type /FontDescriptor /FontName /ABCDEF+DejaVuSans /FontFile2 6 0 R >>",
+		"<< /Length 4 >>\nstream\nfont\nendstream",
+		"<< /Length 44 >>\nstream\nBT /F1 12 Tf 72 770 Td (Hello, world) Tj ET\nendstream",
+		fmt.Sprintf("<< /Title (%s) /Producer (wkhtmltopdf 0.12.6) /CreationDate (%s) /ModDate (%s) >>", title, date, date),
+	})
+
+	// Reference the document information dictionary and add the identifier.
+	return bytes.Replace(data, []byte("/Root 1 0 R"),
+		[]byte(fmt.Sprintf("/Root 1 0 R /Info 8 0 R /ID [<%s> <%s>]", id, id)), 1)
+}
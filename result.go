@@ -0,0 +1,173 @@
+package pdf
+
+import (
+	"time"
+
+	"github.com/adrg/go-wkhtmltopdf/internal/pdfdoc"
+)
+
+// Result contains information about a completed conversion.
+type Result struct {
+	// The number of pages of the output document. The page information of
+	// the result is not available for PostScript output documents.
+	PageCount int `json:"pageCount" yaml:"pageCount"`
+
+	// The pages of the output document occupied by each converted object,
+	// in the order in which the objects were added to the converter.
+	Objects []ObjectPages `json:"objects" yaml:"objects"`
+
+	// The size of the output document, in bytes.
+	Size int64 `json:"size" yaml:"size"`
+
+	// The time spent in each of the conversion phases.
+	Phases []PhaseDuration `json:"phases" yaml:"phases"`
+
+	// The total duration of the conversion.
+	Duration time.Duration `json:"duration" yaml:"duration"`
+
+	// The warnings issued during the conversion process.
+	Warnings []string `json:"warnings" yaml:"warnings"`
+
+	// The outline generated for the output document. Each top-level item
+	// represents a converted object, in the order in which the objects were
+	// added to the converter, and contains the outline of the object.
+	Outline []OutlineItem `json:"outline,omitempty" yaml:"outline,omitempty"`
+}
+
+// ObjectPages represents the range of output document pages occupied by
+// a converted object. Page numbers start at 1 and do not include the page
+// offset specified in the converter options. If the pages of the object
+// cannot be determined, both page numbers are 0.
+type ObjectPages struct {
+	// The first page of the object.
+	StartPage int `json:"startPage" yaml:"startPage"`
+
+	// The last page of the object.
+	EndPage int `json:"endPage" yaml:"endPage"`
+}
+
+// PhaseDuration represents the time spent in a conversion phase.
+type PhaseDuration struct {
+	// The index of the conversion phase.
+	Index int `json:"index" yaml:"index"`
+
+	// The description of the conversion phase.
+	Description string `json:"description" yaml:"description"`
+
+	// The time spent in the conversion phase.
+	Duration time.Duration `json:"duration" yaml:"duration"`
+}
+
+// runState collects information about a conversion, based on the events
+// reported by the `wkhtmltox` library. All methods can be called on a nil
+// state, in which case they have no effect.
+type runState struct {
+	start       time.Time
+	phaseStart  time.Time
+	phaseIndex  int
+	phases      []PhaseDuration
+	warnings    []string
+	outlinePath string
+}
+
+func newRunState(phases []string) *runState {
+	now := time.Now()
+
+	state := &runState{
+		start:      now,
+		phaseStart: now,
+		phases:     make([]PhaseDuration, len(phases)),
+	}
+	for i, description := range phases {
+		state.phases[i] = PhaseDuration{Index: i, Description: description}
+	}
+
+	return state
+}
+
+func (s *runState) addWarning(msg string) {
+	if s == nil {
+		return
+	}
+
+	s.warnings = append(s.warnings, msg)
+}
+
+func (s *runState) changePhase(index int) {
+	if s == nil {
+		return
+	}
+
+	s.endPhase()
+	s.phaseIndex = index
+}
+
+func (s *runState) endPhase() {
+	now := time.Now()
+	if s.phaseIndex >= 0 && s.phaseIndex < len(s.phases) {
+		s.phases[s.phaseIndex].Duration += now.Sub(s.phaseStart)
+	}
+	s.phaseStart = now
+}
+
+// result returns the result of the conversion which generated the specified
+// output document. The page information is only available for PDF output.
+func (s *runState) result(data []byte, format OutputFormat, objectCount int, pageOffset int64) (*Result, error) {
+	s.endPhase()
+	s.phaseIndex = -1
+
+	result := &Result{
+		Objects:  make([]ObjectPages, objectCount),
+		Size:     int64(len(data)),
+		Phases:   s.phases,
+		Duration: time.Since(s.start),
+		Warnings: s.warnings,
+	}
+	if format == PostScript {
+		return result, nil
+	}
+
+	doc, err := pdfdoc.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	pages, err := doc.Pages()
+	if err != nil {
+		return nil, err
+	}
+	result.PageCount = len(pages)
+
+	// Each top-level item of the dumped outline represents a converted
+	// object, and points to its first page.
+	items, err := readOutlineDump(s.outlinePath)
+	if err != nil {
+		return result, nil
+	}
+
+	result.Outline = outlineItems(items, pageOffset)
+	if len(items) != objectCount {
+		return result, nil
+	}
+
+	for i, item := range items {
+		page := item.Page - int(pageOffset)
+		if page < 1 || page > result.PageCount {
+			continue
+		}
+		result.Objects[i].StartPage = page
+	}
+	for i := range result.Objects {
+		start := result.Objects[i].StartPage
+		if start == 0 {
+			continue
+		}
+
+		end := result.PageCount
+		if i+1 < len(result.Objects) && result.Objects[i+1].StartPage > 0 {
+			end = result.Objects[i+1].StartPage - 1
+		}
+		result.Objects[i].EndPage = end
+	}
+
+	return result, nil
+}
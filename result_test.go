@@ -0,0 +1,236 @@
+package pdf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testOutlineDump = `<?xml version="1.0" encoding="UTF-8"?>
+<outline xmlns="http://wkhtmltopdf.org/outline">
+  <item title="First" page="%d" link="__WKANCHOR_0" backLink="__WKANCHOR_1">
+    <item title="Intro" page="%d" link="__WKANCHOR_2" backLink="__WKANCHOR_3"/>
+  </item>
+  <item title="Second" page="%d" link="__WKANCHOR_4" backLink="__WKANCHOR_5"/>
+</outline>
+`
+
+func TestReadOutlineDump(t *testing.T) {
+	path := writeOutlineDump(t, `<?xml version="1.0" encoding="UTF-8"?>
+<outline xmlns="http://wkhtmltopdf.org/outline">
+  <item title="First" page="11" link="__WKANCHOR_0" backLink="__WKANCHOR_1">
+    <item title="Intro" page="12" link="__WKANCHOR_2" backLink="__WKANCHOR_3">
+      <item title="Details" page="12" link="__WKANCHOR_4" backLink="__WKANCHOR_5"/>
+    </item>
+  </item>
+  <item title="Second" page="13" link="" backLink=""/>
+</outline>
+`)
+
+	items, err := readOutlineDump(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []outlineDumpItem{
+		{Title: "First", Page: 11, Link: "__WKANCHOR_0", BackLink: "__WKANCHOR_1", Items: []outlineDumpItem{
+			{Title: "Intro", Page: 12, Link: "__WKANCHOR_2", BackLink: "__WKANCHOR_3", Items: []outlineDumpItem{
+				{Title: "Details", Page: 12, Link: "__WKANCHOR_4", BackLink: "__WKANCHOR_5"},
+			}},
+		}},
+		{Title: "Second", Page: 13},
+	}
+	if !reflect.DeepEqual(items, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, items)
+	}
+
+	outline := outlineItems(items, 10)
+	expectedOutline := []OutlineItem{
+		{Title: "First", Page: 1, Link: "__WKANCHOR_0", Children: []OutlineItem{
+			{Title: "Intro", Page: 2, Link: "__WKANCHOR_2", Children: []OutlineItem{
+				{Title: "Details", Page: 2, Link: "__WKANCHOR_4"},
+			}},
+		}},
+		{Title: "Second", Page: 3},
+	}
+	if !reflect.DeepEqual(outline, expectedOutline) {
+		t.Errorf("expected %+v, got %+v", expectedOutline, outline)
+	}
+
+	for name, data := range map[string]string{
+		"malformed": `<outline><item title="First" page="1">`,
+		"invalid":   `<outline><item title="First" page="one"/></outline>`,
+	} {
+		if _, err := readOutlineDump(writeOutlineDump(t, data)); err == nil {
+			t.Errorf("expected error for %s outline dump", name)
+		}
+	}
+	if _, err := readOutlineDump(filepath.Join(t.TempDir(), "missing.xml")); err == nil {
+		t.Error("expected error for missing outline dump")
+	}
+}
+
+func TestRunStateResult(t *testing.T) {
+	document := resultDocument(4)
+
+	tests := []struct {
+		name        string
+		data        []byte
+		format      OutputFormat
+		dump        string
+		objectCount int
+		pageOffset  int64
+		pageCount   int
+		objects     []ObjectPages
+		outline     bool
+	}{
+		{
+			name:        "pages",
+			data:        document,
+			dump:        outlineDump(1, 2, 3),
+			objectCount: 2,
+			pageCount:   4,
+			objects:     []ObjectPages{{StartPage: 1, EndPage: 2}, {StartPage: 3, EndPage: 4}},
+			outline:     true,
+		},
+		{
+			name:        "page offset",
+			data:        document,
+			dump:        outlineDump(11, 11, 14),
+			objectCount: 2,
+			pageOffset:  10,
+			pageCount:   4,
+			objects:     []ObjectPages{{StartPage: 1, EndPage: 3}, {StartPage: 4, EndPage: 4}},
+			outline:     true,
+		},
+		{
+			name:        "page out of range",
+			data:        document,
+			dump:        outlineDump(1, 1, 5),
+			objectCount: 2,
+			pageCount:   4,
+			objects:     []ObjectPages{{StartPage: 1, EndPage: 4}, {}},
+			outline:     true,
+		},
+		{
+			name:        "object count mismatch",
+			data:        document,
+			dump:        outlineDump(1, 1, 2),
+			objectCount: 3,
+			pageCount:   4,
+			objects:     []ObjectPages{{}, {}, {}},
+			outline:     true,
+		},
+		{
+			name:        "malformed outline dump",
+			data:        document,
+			dump:        `<outline><item title="First"`,
+			objectCount: 1,
+			pageCount:   4,
+			objects:     []ObjectPages{{}},
+		},
+		{
+			name:        "postscript",
+			data:        []byte("%!PS-Adobe-3.0\n"),
+			format:      PostScript,
+			dump:        outlineDump(1, 1, 2),
+			objectCount: 1,
+			objects:     []ObjectPages{{}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := newRunState([]string{"Loading", "Printing"})
+			state.outlinePath = writeOutlineDump(t, test.dump)
+
+			result, err := state.result(test.data, test.format, test.objectCount, test.pageOffset)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.PageCount != test.pageCount {
+				t.Errorf("expected page count %d, got %d", test.pageCount, result.PageCount)
+			}
+			if result.Size != int64(len(test.data)) {
+				t.Errorf("expected size %d, got %d", len(test.data), result.Size)
+			}
+			if !reflect.DeepEqual(result.Objects, test.objects) {
+				t.Errorf("expected object pages %+v, got %+v", test.objects, result.Objects)
+			}
+			if outline := len(result.Outline) > 0; outline != test.outline {
+				t.Errorf("expected outline=%t, got %+v", test.outline, result.Outline)
+			}
+		})
+	}
+
+	state := newRunState(nil)
+	if _, err := state.result([]byte("not a document"), PDF, 1, 0); err == nil {
+		t.Error("expected error for invalid output document")
+	}
+}
+
+func TestRunStateEvents(t *testing.T) {
+	state := newRunState([]string{"Loading", "Printing"})
+	state.outlinePath = filepath.Join(t.TempDir(), "missing.xml")
+
+	state.addWarning("first warning")
+	state.changePhase(1)
+	state.addWarning("second warning")
+	state.changePhase(5)
+
+	result, err := state.result(resultDocument(1), PDF, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"first warning", "second warning"}; !reflect.DeepEqual(result.Warnings, expected) {
+		t.Errorf("expected warnings %q, got %q", expected, result.Warnings)
+	}
+	if len(result.Phases) != 2 || result.Phases[0].Description != "Loading" || result.Phases[1].Index != 1 {
+		t.Errorf("unexpected phases %+v", result.Phases)
+	}
+	var total int64
+	for _, phase := range result.Phases {
+		total += int64(phase.Duration)
+	}
+	if total > int64(result.Duration) {
+		t.Errorf("phase durations %+v exceed the total duration %s", result.Phases, result.Duration)
+	}
+	if result.PageCount != 1 || result.Outline != nil || !reflect.DeepEqual(result.Objects, []ObjectPages{{}}) {
+		t.Errorf("unexpected result without outline dump: %+v", result)
+	}
+
+	// Methods of nil states have no effect.
+	var nilState *runState
+	nilState.addWarning("warning")
+	nilState.changePhase(1)
+}
+
+// resultDocument returns a document with the specified number of pages.
+func resultDocument(pageCount int) []byte {
+	objects := []string{"<< /Type /Catalog /Pages 2 0 R >>", ""}
+
+	kids := ""
+	for i := 0; i < pageCount; i++ {
+		kids += fmt.Sprintf("%d 0 R ", len(objects)+1)
+		objects = append(objects, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] >>")
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids, pageCount)
+
+	return testDocument(objects)
+}
+
+func outlineDump(first, intro, second int) string {
+	return fmt.Sprintf(testOutlineDump, first, intro, second)
+}
+
+func writeOutlineDump(t *testing.T, data string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "outline.xml")
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
@@ -0,0 +1,161 @@
+package pdf
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+const (
+	// unitPattern matches the sizes accepted by the library
+	// (e.g. "10mm", "2cm", "1.5in", "12px").
+	unitPattern = `^$|^([0-9]+(\.[0-9]*)?|\.[0-9]+)(mm|cm|m|in|inch|px|pt|pc)?$`
+
+	// cssLengthPattern matches CSS lengths (e.g. "1em", "10px").
+	cssLengthPattern = `^$|^([0-9]+(\.[0-9]*)?|\.[0-9]+)(em|ex|%|mm|cm|in|px|pt|pc)?$`
+
+	// viewportPattern matches viewport sizes (e.g. "1280x1024").
+	viewportPattern = `^$|^[0-9]+x[0-9]+$`
+)
+
+// optionConstraints contains additional JSON Schema constraints of the
+// option fields, which cannot be determined from their types.
+var optionConstraints = map[string]map[string]interface{}{
+	"ConverterOpts.Width":        {"pattern": unitPattern},
+	"ConverterOpts.Height":       {"pattern": unitPattern},
+	"ConverterOpts.MarginTop":    {"pattern": unitPattern},
+	"ConverterOpts.MarginBottom": {"pattern": unitPattern},
+	"ConverterOpts.MarginLeft":   {"pattern": unitPattern},
+	"ConverterOpts.MarginRight":  {"pattern": unitPattern},
+	"ConverterOpts.ViewportSize": {"pattern": viewportPattern},
+	"ConverterOpts.Copies":       {"minimum": 1},
+	"ConverterOpts.ImageQuality": {"maximum": 100},
+	"ObjectOpts.Zoom":            {"exclusiveMinimum": 0},
+	"TOC.FontScale":              {"exclusiveMinimum": 0},
+	"TOC.Indentation":            {"pattern": cssLengthPattern},
+	"OutlineItem.Page":           {"minimum": 1},
+}
+
+// ConverterOptsSchema returns a JSON Schema (draft 2020-12) describing the
+// JSON representation of the converter options, including the defined
+// values of the enumerated options, value ranges, size formats and the
+// defaults returned by NewConverterOpts. Empty values of enumerated options
+// stand for the library defaults. The generated schema is also available in
+// the schema directory of the repository, which is kept in sync by the tests
+// (run them using -update-schema after changing the options).
+func ConverterOptsSchema() ([]byte, error) {
+	return optionsSchema(NewConverterOpts())
+}
+
+// ObjectOptsSchema returns a JSON Schema (draft 2020-12) describing the
+// JSON representation of the object options, including the defaults
+// returned by NewObjectOpts. See ConverterOptsSchema for more details.
+func ObjectOptsSchema() ([]byte, error) {
+	return optionsSchema(NewObjectOpts())
+}
+
+func optionsSchema(defaults interface{}) ([]byte, error) {
+	sb := &schemaBuilder{defs: map[string]interface{}{}}
+
+	v := reflect.ValueOf(defaults).Elem()
+	schema := sb.structSchema(v.Type(), v)
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = v.Type().Name()
+	if len(sb.defs) > 0 {
+		schema["$defs"] = sb.defs
+	}
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// schemaBuilder builds JSON Schemas for option types. Struct types which are
+// not option types are added to the schema definitions, as they may be
+// recursive (e.g. OutlineItem).
+type schemaBuilder struct {
+	defs map[string]interface{}
+}
+
+func (sb *schemaBuilder) structSchema(t reflect.Type, defaults reflect.Value) map[string]interface{} {
+	properties := map[string]interface{}{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if !sf.IsExported() || name == "" || name == "-" {
+			continue
+		}
+
+		var fieldDefault reflect.Value
+		if defaults.IsValid() {
+			fieldDefault = defaults.Field(i)
+		}
+
+		key := t.Name() + "." + sf.Name
+		schema := sb.typeSchema(sf.Type, fieldDefault)
+		if doc := optionDocs[key]; doc != "" {
+			schema["description"] = doc
+		}
+		for constraint, value := range optionConstraints[key] {
+			schema[constraint] = value
+		}
+
+		properties[name] = schema
+	}
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+func (sb *schemaBuilder) typeSchema(t reflect.Type, defaults reflect.Value) map[string]interface{} {
+	schema := map[string]interface{}{}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		if defaults.IsValid() && !defaults.IsNil() {
+			defaults = defaults.Elem()
+		} else {
+			defaults = reflect.Value{}
+		}
+
+		schema = sb.typeSchema(t.Elem(), defaults)
+		schema["type"] = []interface{}{schema["type"], "null"}
+		return schema
+	case reflect.Struct:
+		if !defaults.IsValid() {
+			if _, ok := sb.defs[t.Name()]; !ok {
+				sb.defs[t.Name()] = nil
+				sb.defs[t.Name()] = sb.structSchema(t, reflect.Value{})
+			}
+			return map[string]interface{}{"$ref": "#/$defs/" + t.Name()}
+		}
+		return sb.structSchema(t, defaults)
+	case reflect.String:
+		schema["type"] = "string"
+		if values, ok := optionEnums[t.Name()]; ok {
+			schema["enum"] = append([]string{""}, values...)
+		}
+	case reflect.Bool:
+		schema["type"] = "boolean"
+	case reflect.Int, reflect.Int64:
+		schema["type"] = "integer"
+	case reflect.Uint64:
+		schema["type"] = "integer"
+		schema["minimum"] = 0
+	case reflect.Float64:
+		schema["type"] = "number"
+	case reflect.Map:
+		schema["type"] = []interface{}{"object", "null"}
+		schema["additionalProperties"] = sb.typeSchema(t.Elem(), reflect.Value{})
+	case reflect.Slice:
+		schema["type"] = []interface{}{"array", "null"}
+		schema["items"] = sb.typeSchema(t.Elem(), reflect.Value{})
+	}
+
+	if defaults.IsValid() && !defaults.IsZero() || defaults.Kind() == reflect.Bool {
+		schema["default"] = defaults.Interface()
+	}
+
+	return schema
+}
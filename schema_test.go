@@ -0,0 +1,87 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// updateSchema specifies whether the checked-in schemas are updated instead
+// of being compared with the generated ones.
+var updateSchema = flag.Bool("update-schema", false, "update the checked-in JSON Schemas")
+
+func TestSchemaSync(t *testing.T) {
+	tests := []struct {
+		file   string
+		schema func() ([]byte, error)
+	}{
+		{file: "converter_opts.json", schema: ConverterOptsSchema},
+		{file: "object_opts.json", schema: ObjectOptsSchema},
+	}
+
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			generated, err := test.schema()
+			if err != nil {
+				t.Fatal(err)
+			}
+			generated = append(generated, '\n')
+
+			path := filepath.Join("schema", test.file)
+			if *updateSchema {
+				if err := os.WriteFile(path, generated, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			checkedIn, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("could not read schema (run the tests using -update-schema to create it): %v", err)
+			}
+			if !bytes.Equal(generated, checkedIn) {
+				t.Errorf("%s is out of date (run the tests using -update-schema to update it)", path)
+			}
+		})
+	}
+}
+
+func TestSchemaDefaults(t *testing.T) {
+	data, err := ConverterOptsSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var schema struct {
+		Schema     string `json:"$schema"`
+		Properties map[string]struct {
+			Type    interface{}   `json:"type"`
+			Default interface{}   `json:"default"`
+			Enum    []interface{} `json:"enum"`
+			Pattern string        `json:"pattern"`
+		} `json:"properties"`
+		Defs map[string]interface{} `json:"$defs"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	if schema.Schema != "https://json-schema.org/draft/2020-12/schema" {
+		t.Errorf("unexpected schema dialect %q", schema.Schema)
+	}
+	if prop := schema.Properties["paperSize"]; prop.Default != "A4" || len(prop.Enum) == 0 {
+		t.Errorf("unexpected paper size schema: %+v", prop)
+	}
+	if prop := schema.Properties["imageQuality"]; prop.Default != float64(100) {
+		t.Errorf("unexpected image quality default: %v", prop.Default)
+	}
+	if prop := schema.Properties["marginTop"]; prop.Pattern == "" {
+		t.Error("missing margin pattern")
+	}
+	if _, ok := schema.Defs["OutlineItem"]; !ok {
+		t.Error("missing outline item definition")
+	}
+}
@@ -0,0 +1,88 @@
+package sign
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/adrg/go-wkhtmltopdf/internal/pdfdoc"
+)
+
+// newAppearance returns a form XObject which displays the specified text
+// inside a bordered rectangle of the given size. The text is rendered using
+// the standard Helvetica font, scaled to fit the rectangle.
+func newAppearance(width, height float64, text string) *pdfdoc.Stream {
+	lines := strings.Split(text, "\n")
+
+	// Compute font size. The average Helvetica glyph width is approximated
+	// to 0.55 of the font size.
+	const padding = 4
+	maxLen := 1
+	for _, line := range lines {
+		if n := len([]rune(line)); n > maxLen {
+			maxLen = n
+		}
+	}
+
+	fontSize := (height - 2*padding) / (float64(len(lines)) * 1.2)
+	if w := (width - 2*padding) / (float64(maxLen) * 0.55); w < fontSize {
+		fontSize = w
+	}
+	if fontSize > 12 {
+		fontSize = 12
+	}
+	if fontSize < 1 {
+		fontSize = 1
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "q 0.5 w 0 0 0 RG 0.25 0.25 %s %s re S Q\n", num(width-0.5), num(height-0.5))
+	fmt.Fprintf(&buf, "BT /Helv %s Tf 0 0 0 rg %s TL %d %s Td\n", num(fontSize), num(fontSize*1.2), padding, num(height-padding-fontSize))
+	for i, line := range lines {
+		if i > 0 {
+			buf.WriteString("T* ")
+		}
+
+		var s bytes.Buffer
+		pdfdoc.WriteObject(&s, pdfdoc.String(winAnsi(line))) // nolint:errcheck
+		buf.Write(s.Bytes())
+		buf.WriteString(" Tj\n")
+	}
+	buf.WriteString("ET\n")
+
+	return pdfdoc.NewStream(pdfdoc.Dict{
+		"Type":    pdfdoc.Name("XObject"),
+		"Subtype": pdfdoc.Name("Form"),
+		"BBox":    pdfdoc.Array{int64(0), int64(0), width, height},
+		"Resources": pdfdoc.Dict{
+			"Font": pdfdoc.Dict{
+				"Helv": pdfdoc.Dict{
+					"Type":     pdfdoc.Name("Font"),
+					"Subtype":  pdfdoc.Name("Type1"),
+					"BaseFont": pdfdoc.Name("Helvetica"),
+					"Encoding": pdfdoc.Name("WinAnsiEncoding"),
+				},
+			},
+		},
+	}, buf.Bytes(), true)
+}
+
+// winAnsi converts the specified string to the Windows-1252 encoding.
+// Characters which cannot be represented are replaced by question marks.
+func winAnsi(s string) string {
+	var buf bytes.Buffer
+	for _, r := range s {
+		switch {
+		case r < 0x80 || (r >= 0xa0 && r <= 0xff):
+			buf.WriteByte(byte(r))
+		default:
+			buf.WriteByte('?')
+		}
+	}
+
+	return buf.String()
+}
+
+func num(f float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.2f", f), "0"), ".")
+}
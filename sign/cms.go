@@ -0,0 +1,304 @@
+package sign
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+var (
+	oidData                     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidSignedData               = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidAttrContentType          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidAttrMessageDigest        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidAttrSigningCertificateV2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 47}
+	oidAttrSignatureTimeStamp   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 14}
+	oidTSTInfo                  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+	oidRSAEncryption            = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidECDSAWithSHA256          = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+	oidECDSAWithSHA384          = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 3}
+	oidECDSAWithSHA512          = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 4}
+	oidSHA256                   = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384                   = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512                   = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+)
+
+var (
+	errUnsupportedHash = errors.New("unsupported hash algorithm")
+	errUnsupportedKey  = errors.New("unsupported private key type")
+)
+
+var asn1Null = asn1.RawValue{Tag: asn1.TagNull}
+
+type algorithmIdentifier struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.RawValue `asn1:"optional"`
+}
+
+type issuerAndSerialNumber struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue
+}
+
+type encapsulatedContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"optional"`
+}
+
+type signerInfo struct {
+	Version            int
+	SID                issuerAndSerialNumber
+	DigestAlgorithm    algorithmIdentifier
+	SignedAttrs        asn1.RawValue
+	SignatureAlgorithm algorithmIdentifier
+	Signature          []byte
+	UnsignedAttrs      asn1.RawValue `asn1:"optional"`
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	EncapContentInfo encapsulatedContentInfo
+	Certificates     asn1.RawValue `asn1:"optional"`
+	SignerInfos      asn1.RawValue
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue
+}
+
+type essCertIDv2 struct {
+	HashAlgorithm algorithmIdentifier `asn1:"optional"`
+	CertHash      []byte
+	IssuerSerial  issuerSerial
+}
+
+type issuerSerial struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type signingCertificateV2 struct {
+	Certs []essCertIDv2
+}
+
+func hashAlgorithm(hash crypto.Hash) (algorithmIdentifier, error) {
+	switch hash {
+	case crypto.SHA256:
+		return algorithmIdentifier{Algorithm: oidSHA256}, nil
+	case crypto.SHA384:
+		return algorithmIdentifier{Algorithm: oidSHA384}, nil
+	case crypto.SHA512:
+		return algorithmIdentifier{Algorithm: oidSHA512}, nil
+	}
+
+	return algorithmIdentifier{}, fmt.Errorf("%w: %v", errUnsupportedHash, hash)
+}
+
+func signatureAlgorithm(key crypto.Signer, hash crypto.Hash) (algorithmIdentifier, error) {
+	switch key.Public().(type) {
+	case *rsa.PublicKey:
+		return algorithmIdentifier{Algorithm: oidRSAEncryption, Parameters: asn1Null}, nil
+	case *ecdsa.PublicKey:
+		switch hash {
+		case crypto.SHA256:
+			return algorithmIdentifier{Algorithm: oidECDSAWithSHA256}, nil
+		case crypto.SHA384:
+			return algorithmIdentifier{Algorithm: oidECDSAWithSHA384}, nil
+		case crypto.SHA512:
+			return algorithmIdentifier{Algorithm: oidECDSAWithSHA512}, nil
+		}
+		return algorithmIdentifier{}, fmt.Errorf("%w: %v", errUnsupportedHash, hash)
+	}
+
+	return algorithmIdentifier{}, fmt.Errorf("%w: %T", errUnsupportedKey, key.Public())
+}
+
+// marshalSet returns the DER encoding of a SET OF containing the specified
+// encoded elements. The elements are sorted, as required by DER.
+func marshalSet(class, tag int, elements ...[]byte) asn1.RawValue {
+	sorted := make([][]byte, len(elements))
+	copy(sorted, elements)
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i], sorted[j]) < 0 })
+
+	return asn1.RawValue{
+		Class:      class,
+		Tag:        tag,
+		IsCompound: true,
+		Bytes:      bytes.Join(sorted, nil),
+	}
+}
+
+func marshalAttribute(typ asn1.ObjectIdentifier, value interface{}) ([]byte, error) {
+	encoded, err := asn1.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(attribute{
+		Type:   typ,
+		Values: marshalSet(asn1.ClassUniversal, asn1.TagSet, encoded),
+	})
+}
+
+// createSignature returns a detached CMS signature (RFC 5652) over the data
+// with the specified digest. The signature contains the signed attributes
+// required by the PAdES baseline B-B level. If a timestamp client is
+// provided, a signature timestamp is added as an unsigned attribute.
+func createSignature(digest []byte, key crypto.Signer, certs []*x509.Certificate, hash crypto.Hash, tsa *TimestampClient) ([]byte, error) {
+	signer := certs[0]
+
+	digestAlg, err := hashAlgorithm(hash)
+	if err != nil {
+		return nil, err
+	}
+	sigAlg, err := signatureAlgorithm(key, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create signed attributes.
+	h := hash.New()
+	h.Write(signer.Raw)
+
+	// The issuer of the certificate ID is a GeneralNames sequence, containing
+	// a single directory name.
+	generalName, err := asn1.Marshal(asn1.RawValue{
+		Class:      asn1.ClassContextSpecific,
+		Tag:        4,
+		IsCompound: true,
+		Bytes:      signer.RawIssuer,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	certID := essCertIDv2{
+		CertHash: h.Sum(nil),
+		IssuerSerial: issuerSerial{
+			Issuer: asn1.RawValue{
+				Tag:        asn1.TagSequence,
+				IsCompound: true,
+				Bytes:      generalName,
+			},
+			SerialNumber: signer.SerialNumber,
+		},
+	}
+	if hash != crypto.SHA256 {
+		certID.HashAlgorithm = digestAlg
+	}
+
+	var attrs [][]byte
+	for _, attr := range []struct {
+		typ   asn1.ObjectIdentifier
+		value interface{}
+	}{
+		{oidAttrContentType, oidData},
+		{oidAttrMessageDigest, digest},
+		{oidAttrSigningCertificateV2, signingCertificateV2{Certs: []essCertIDv2{certID}}},
+	} {
+		encoded, err := marshalAttribute(attr.typ, attr.value)
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, encoded)
+	}
+
+	// Sign the DER encoding of the signed attributes.
+	signedAttrs := marshalSet(asn1.ClassUniversal, asn1.TagSet, attrs...)
+	encodedAttrs, err := asn1.Marshal(signedAttrs)
+	if err != nil {
+		return nil, err
+	}
+
+	h = hash.New()
+	h.Write(encodedAttrs)
+	signature, err := key.Sign(rand.Reader, h.Sum(nil), hash)
+	if err != nil {
+		return nil, err
+	}
+
+	info := signerInfo{
+		Version: 1,
+		SID: issuerAndSerialNumber{
+			Issuer:       asn1.RawValue{FullBytes: signer.RawIssuer},
+			SerialNumber: signer.SerialNumber,
+		},
+		DigestAlgorithm:    digestAlg,
+		SignedAttrs:        marshalSet(asn1.ClassContextSpecific, 0, attrs...),
+		SignatureAlgorithm: sigAlg,
+		Signature:          signature,
+	}
+
+	// Timestamp the signature value.
+	if tsa != nil {
+		token, err := tsa.Timestamp(signature)
+		if err != nil {
+			return nil, err
+		}
+
+		attr, err := asn1.Marshal(attribute{
+			Type:   oidAttrSignatureTimeStamp,
+			Values: marshalSet(asn1.ClassUniversal, asn1.TagSet, token),
+		})
+		if err != nil {
+			return nil, err
+		}
+		info.UnsignedAttrs = marshalSet(asn1.ClassContextSpecific, 1, attr)
+	}
+
+	// Create signed data structure.
+	encodedAlg, err := asn1.Marshal(digestAlg)
+	if err != nil {
+		return nil, err
+	}
+	encodedInfo, err := asn1.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+
+	rawCerts := make([][]byte, 0, len(certs))
+	for _, cert := range certs {
+		rawCerts = append(rawCerts, cert.Raw)
+	}
+
+	sd, err := asn1.Marshal(signedData{
+		Version:          1,
+		DigestAlgorithms: marshalSet(asn1.ClassUniversal, asn1.TagSet, encodedAlg),
+		EncapContentInfo: encapsulatedContentInfo{ContentType: oidData},
+		Certificates: asn1.RawValue{
+			Class:      asn1.ClassContextSpecific,
+			Tag:        0,
+			IsCompound: true,
+			Bytes:      bytes.Join(rawCerts, nil),
+		},
+		SignerInfos: marshalSet(asn1.ClassUniversal, asn1.TagSet, encodedInfo),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(contentInfo{
+		ContentType: oidSignedData,
+		Content: asn1.RawValue{
+			Class:      asn1.ClassContextSpecific,
+			Tag:        0,
+			IsCompound: true,
+			Bytes:      sd,
+		},
+	})
+}
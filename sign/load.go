@@ -0,0 +1,111 @@
+package sign
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// LoadPEM returns a new signer using the certificate chain and the private
+// key contained in the specified PEM files. The certificate file must contain
+// the signing certificate, optionally followed by the rest of the chain.
+// If keyPath is empty, the private key is read from the certificate file.
+// Encrypted private keys are not supported.
+func LoadPEM(certPath, keyPath string) (*Signer, error) {
+	certData, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, err
+	}
+
+	keyData := certData
+	if keyPath != "" {
+		if keyData, err = os.ReadFile(keyPath); err != nil {
+			return nil, err
+		}
+	}
+
+	return ParsePEM(certData, keyData)
+}
+
+// ParsePEM returns a new signer using the certificate chain and the private
+// key contained in the specified PEM encoded data.
+func ParsePEM(certData, keyData []byte) (*Signer, error) {
+	var certs []*x509.Certificate
+	for block, rest := pem.Decode(certData); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificates found in PEM data")
+	}
+
+	for block, rest := pem.Decode(keyData); block != nil; block, rest = pem.Decode(rest) {
+		var (
+			key interface{}
+			err error
+		)
+		switch block.Type {
+		case "PRIVATE KEY":
+			key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		case "RSA PRIVATE KEY":
+			key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY":
+			key, err = x509.ParseECPrivateKey(block.Bytes)
+		case "ENCRYPTED PRIVATE KEY":
+			return nil, errors.New("encrypted private keys are not supported")
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("%w: %T", errUnsupportedKey, key)
+		}
+
+		return NewSigner(signer, certs)
+	}
+
+	return nil, errors.New("no private key found in PEM data")
+}
+
+// LoadPKCS12 returns a new signer using the certificate chain and the private
+// key contained in the specified PKCS#12 (.p12, .pfx) file.
+func LoadPKCS12(path, password string) (*Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParsePKCS12(data, password)
+}
+
+// ParsePKCS12 returns a new signer using the certificate chain and the
+// private key contained in the specified PKCS#12 data.
+func ParsePKCS12(data []byte, password string) (*Signer, error) {
+	key, cert, caCerts, err := pkcs12.DecodeChain(data, password)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", errUnsupportedKey, key)
+	}
+
+	return NewSigner(signer, append([]*x509.Certificate{cert}, caCerts...))
+}
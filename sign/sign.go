@@ -0,0 +1,417 @@
+/*
+Package sign implements digital signing of PDF documents, such as the ones
+generated by the wkhtmltox library.
+
+The produced signatures are detached CMS signatures, compatible with the
+PAdES baseline B-B level (ETSI EN 319 142-1). If a timestamp client is
+configured, the signatures also contain an RFC 3161 signature timestamp,
+which raises their level to PAdES B-T. Signatures are added to documents
+using incremental updates, so the original content is preserved.
+
+Example
+
+	signer, err := sign.LoadPKCS12("certificate.p12", "password")
+	if err != nil {
+		log.Fatal(err)
+	}
+	signer.Reason = "Contract approval"
+	signer.Location = "Amsterdam"
+	signer.Appearance = &sign.Appearance{
+		Page: 1,
+		Rect: [4]float64{50, 50, 250, 110},
+	}
+
+	if err := signer.Sign(outFile, inFile); err != nil {
+		log.Fatal(err)
+	}
+*/
+package sign
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/md5"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/adrg/go-wkhtmltopdf/internal/pdfdoc"
+)
+
+// Appearance defines the visible representation of a signature.
+type Appearance struct {
+	// The number of the page on which the signature is displayed.
+	// Page numbers start at 1.
+	Page int
+
+	// The location of the signature on the page, in PDF user space units
+	// (1/72 inch), relative to the bottom left corner of the page.
+	// The values are specified in the following order: left, bottom,
+	// right, top.
+	// E.g.: [4]float64{50, 50, 250, 110}.
+	Rect [4]float64
+
+	// The text displayed inside the signature rectangle. If not specified,
+	// the text contains the name of the signer, the signing time and the
+	// reason and location of the signing, if provided.
+	Text string
+}
+
+// Signer signs PDF documents using a private key and the certificate chain
+// associated with it.
+type Signer struct {
+	// The private key used to create signatures.
+	Key crypto.Signer
+
+	// The certificate chain of the signer. The first certificate must be
+	// the signing certificate, corresponding to the private key.
+	Certificates []*x509.Certificate
+
+	// The hash algorithm used to create signatures.
+	// If not specified, SHA-256 is used.
+	Hash crypto.Hash
+
+	// The name of the signature form field. If not specified, a unique name
+	// of the form "SignatureN" is generated.
+	FieldName string
+
+	// The name of the person or authority signing the document. If not
+	// specified, the common name of the signing certificate is used.
+	Name string
+
+	// The reason for signing the document.
+	// E.g.: "Contract approval".
+	Reason string
+
+	// The location of the signing.
+	// E.g.: "Amsterdam".
+	Location string
+
+	// Information provided by the signer to enable a recipient to contact
+	// the signer.
+	ContactInfo string
+
+	// The claimed signing time. If not specified, the current time is used.
+	Time time.Time
+
+	// The visible representation of the signature. If not specified,
+	// the signature is invisible.
+	Appearance *Appearance
+
+	// The client used to request signature timestamps. If not specified,
+	// the signatures are not timestamped.
+	Timestamper *TimestampClient
+
+	// The number of bytes reserved for the signature in the document. If not
+	// specified, the size is estimated based on the certificate chain.
+	ReservedSize int
+}
+
+// NewSigner returns a new signer which uses the specified private key and
+// certificate chain. The first certificate of the chain must correspond to
+// the private key.
+func NewSigner(key crypto.Signer, certs []*x509.Certificate) (*Signer, error) {
+	if key == nil {
+		return nil, errors.New("private key cannot be nil")
+	}
+	if len(certs) == 0 || certs[0] == nil {
+		return nil, errors.New("must provide the signing certificate")
+	}
+
+	pub, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(certs[0].PublicKey) {
+		return nil, errors.New("private key does not match the signing certificate")
+	}
+
+	return &Signer{
+		Key:          key,
+		Certificates: certs,
+	}, nil
+}
+
+// Sign reads the PDF document from r, signs it and writes the signed
+// document to w.
+func (s *Signer) Sign(w io.Writer, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	signed, err := s.SignBytes(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(signed)
+	return err
+}
+
+// SignBytes signs the specified PDF document and returns the signed document.
+func (s *Signer) SignBytes(data []byte) ([]byte, error) {
+	if s.Key == nil || len(s.Certificates) == 0 {
+		return nil, errors.New("signer must have a private key and a signing certificate")
+	}
+
+	hash := s.Hash
+	if hash == 0 {
+		hash = crypto.SHA256
+	}
+	if _, err := hashAlgorithm(hash); err != nil {
+		return nil, err
+	}
+	if _, err := signatureAlgorithm(s.Key, hash); err != nil {
+		return nil, err
+	}
+
+	signingTime := s.Time
+	if signingTime.IsZero() {
+		signingTime = time.Now()
+	}
+
+	doc, err := pdfdoc.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reserve space for the signature.
+	reserved := s.ReservedSize
+	if reserved <= 0 {
+		reserved = 8192
+		for _, cert := range s.Certificates {
+			reserved += len(cert.Raw)
+		}
+		if s.Timestamper != nil {
+			reserved += 8192
+		}
+	}
+
+	// Add signature dictionary.
+	sigDict := pdfdoc.Dict{
+		"Type":      pdfdoc.Name("Sig"),
+		"Filter":    pdfdoc.Name("Adobe.PPKLite"),
+		"SubFilter": pdfdoc.Name("ETSI.CAdES.detached"),
+		"ByteRange": pdfdoc.Raw(byteRangePlaceholder),
+		"Contents":  pdfdoc.Raw("<" + strings.Repeat("0", 2*reserved) + ">"),
+		"M":         pdfdoc.String(pdfdoc.FormatDate(signingTime)),
+	}
+	for key, val := range map[pdfdoc.Name]string{
+		"Name":        s.signerName(),
+		"Reason":      s.Reason,
+		"Location":    s.Location,
+		"ContactInfo": s.ContactInfo,
+	} {
+		if val != "" {
+			sigDict[key] = pdfdoc.EncodeText(val)
+		}
+	}
+	sigRef := doc.Add(sigDict)
+
+	// Add signature field.
+	if err := s.addField(doc, sigRef, signingTime); err != nil {
+		return nil, err
+	}
+
+	// Add document identifier, if missing.
+	if _, ok := doc.Trailer["ID"].(pdfdoc.Array); !ok {
+		id := md5.Sum(data)
+		doc.Trailer["ID"] = pdfdoc.Array{pdfdoc.HexString(id[:]), pdfdoc.HexString(id[:])}
+	}
+
+	var buf bytes.Buffer
+	if err := doc.WriteUpdate(&buf); err != nil {
+		return nil, err
+	}
+	out := buf.Bytes()
+
+	// Locate signature placeholders.
+	brStart := bytes.LastIndex(out, []byte("/ByteRange "+byteRangePlaceholder))
+	if brStart < 0 {
+		return nil, errors.New("could not locate signature byte range")
+	}
+	brStart += len("/ByteRange ")
+
+	contentsStart := bytes.Index(out[brStart:], []byte("/Contents <"))
+	if contentsStart < 0 {
+		return nil, errors.New("could not locate signature contents")
+	}
+	contentsStart += brStart + len("/Contents ")
+	contentsEnd := contentsStart + 2*reserved + 2
+
+	// Fill in byte range.
+	byteRange := fmt.Sprintf("[0 %d %d %d]", contentsStart, contentsEnd, len(out)-contentsEnd)
+	if len(byteRange) > len(byteRangePlaceholder) {
+		return nil, errors.New("signature byte range exceeds reserved space")
+	}
+	copy(out[brStart:], byteRange+strings.Repeat(" ", len(byteRangePlaceholder)-len(byteRange)))
+
+	// Compute digest of the signed byte ranges.
+	h := hash.New()
+	h.Write(out[:contentsStart])
+	h.Write(out[contentsEnd:])
+
+	signature, err := createSignature(h.Sum(nil), s.Key, s.Certificates, hash, s.Timestamper)
+	if err != nil {
+		return nil, err
+	}
+	if len(signature) > reserved {
+		return nil, fmt.Errorf("signature size (%d bytes) exceeds reserved space (%d bytes)", len(signature), reserved)
+	}
+
+	// Fill in signature contents.
+	hex.Encode(out[contentsStart+1:], signature)
+	return out, nil
+}
+
+// byteRangePlaceholder reserves space for the byte range of the signature.
+const byteRangePlaceholder = "[0 0000000000 0000000000 0000000000]"
+
+func (s *Signer) addField(doc *pdfdoc.Document, sigRef pdfdoc.Ref, signingTime time.Time) error {
+	catalog, catalogRef, err := doc.Catalog()
+	if err != nil {
+		return err
+	}
+	pages, err := doc.Pages()
+	if err != nil {
+		return err
+	}
+	if len(pages) == 0 {
+		return errors.New("document does not contain any pages")
+	}
+
+	// Retrieve the form of the document.
+	form := pdfdoc.Dict{}
+	formRef, formIsRef := catalog["AcroForm"].(pdfdoc.Ref)
+	if f := doc.Dict(catalog["AcroForm"]); f != nil {
+		form = f.Clone()
+	}
+
+	fieldsRef, fieldsIsRef := form["Fields"].(pdfdoc.Ref)
+	fields := append(pdfdoc.Array{}, doc.Array(form["Fields"])...)
+
+	// Generate field name.
+	names := map[string]bool{}
+	for _, field := range fields {
+		if name, ok := doc.Dict(field).Text("T"); ok {
+			names[pdfdoc.DecodeText(name)] = true
+		}
+	}
+
+	name := s.FieldName
+	if name == "" {
+		for i := 1; name == "" || names[name]; i++ {
+			name = fmt.Sprintf("Signature%d", i)
+		}
+	}
+	if names[name] {
+		return fmt.Errorf("form field `%s` already exists", name)
+	}
+
+	// Create signature widget.
+	pageRef := pages[0]
+	if s.Appearance != nil {
+		if s.Appearance.Page < 1 || s.Appearance.Page > len(pages) {
+			return fmt.Errorf("invalid signature page number: %d", s.Appearance.Page)
+		}
+		pageRef = pages[s.Appearance.Page-1]
+	}
+
+	widget := pdfdoc.Dict{
+		"Type":    pdfdoc.Name("Annot"),
+		"Subtype": pdfdoc.Name("Widget"),
+		"FT":      pdfdoc.Name("Sig"),
+		"T":       pdfdoc.EncodeText(name),
+		"V":       sigRef,
+		"F":       int64(132), // Print and locked.
+		"P":       pageRef,
+		"Rect":    pdfdoc.Array{int64(0), int64(0), int64(0), int64(0)},
+	}
+	if s.Appearance != nil {
+		r := s.Appearance.Rect
+		llx, lly, urx, ury := minf(r[0], r[2]), minf(r[1], r[3]), maxf(r[0], r[2]), maxf(r[1], r[3])
+
+		widget["Rect"] = pdfdoc.Array{llx, lly, urx, ury}
+		widget["AP"] = pdfdoc.Dict{
+			"N": doc.Add(s.appearanceStream(urx-llx, ury-lly, signingTime)),
+		}
+	}
+	widgetRef := doc.Add(widget)
+
+	// Add widget to page annotations.
+	page := doc.Dict(pageRef)
+	if page == nil {
+		return errors.New("invalid signature page")
+	}
+	annots := append(append(pdfdoc.Array{}, doc.Array(page["Annots"])...), widgetRef)
+	if annotsRef, ok := page["Annots"].(pdfdoc.Ref); ok {
+		doc.Set(annotsRef, annots)
+	} else {
+		page = page.Clone()
+		page["Annots"] = annots
+		doc.Set(pageRef, page)
+	}
+
+	// Add field to the document form.
+	fields = append(fields, widgetRef)
+	if fieldsIsRef {
+		doc.Set(fieldsRef, fields)
+	} else {
+		form["Fields"] = fields
+	}
+	form["SigFlags"] = int64(3) // Signatures exist and append only.
+
+	if formIsRef {
+		doc.Set(formRef, form)
+		return nil
+	}
+
+	catalog = catalog.Clone()
+	catalog["AcroForm"] = form
+	doc.Set(catalogRef, catalog)
+
+	return nil
+}
+
+func (s *Signer) appearanceStream(width, height float64, signingTime time.Time) *pdfdoc.Stream {
+	text := s.Appearance.Text
+	if text == "" {
+		text = "Digitally signed by " + s.signerName() + "\nDate: " + signingTime.Format("2006-01-02 15:04:05 -07:00")
+		if s.Reason != "" {
+			text += "\nReason: " + s.Reason
+		}
+		if s.Location != "" {
+			text += "\nLocation: " + s.Location
+		}
+	}
+
+	return newAppearance(width, height, text)
+}
+
+// signerName returns the name of the signer, which defaults to the common
+// name of the signing certificate.
+func (s *Signer) signerName() string {
+	if s.Name != "" {
+		return s.Name
+	}
+
+	return s.Certificates[0].Subject.CommonName
+}
+
+func minf(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func maxf(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,710 @@
+package sign
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/adrg/go-wkhtmltopdf/internal/pdfdoc"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+func TestSignByteRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        crypto.Signer
+		hash       crypto.Hash
+		appearance *Appearance
+	}{
+		{name: "ecdsa", key: newECDSAKey(t)},
+		{name: "ecdsa-sha384", key: newECDSAKey(t), hash: crypto.SHA384},
+		{name: "rsa", key: newRSAKey(t)},
+		{name: "rsa-sha512", key: newRSAKey(t), hash: crypto.SHA512},
+		{
+			name: "visible",
+			key:  newECDSAKey(t),
+			appearance: &Appearance{
+				Page: 2,
+				Rect: [4]float64{250, 110, 50, 50},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			signer := newTestSigner(t, test.key)
+			signer.Hash = test.hash
+			signer.Appearance = test.appearance
+
+			original := minimalPDF(2)
+			signed, err := signer.SignBytes(original)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(signed, original) {
+				t.Fatal("signed document does not preserve the original content")
+			}
+
+			br := readByteRange(t, signed)
+			if br[0] != 0 {
+				t.Errorf("byte range starts at %d, expected 0", br[0])
+			}
+			if br[2]+br[3] != len(signed) {
+				t.Errorf("byte range ends at %d, expected %d", br[2]+br[3], len(signed))
+			}
+			if signed[br[1]] != '<' || signed[br[2]-1] != '>' {
+				t.Fatalf("byte range gap %d-%d does not match the signature contents", br[1], br[2])
+			}
+
+			signature := make([]byte, (br[2]-br[1]-2)/2)
+			if _, err := hex.Decode(signature, signed[br[1]+1:br[2]-1]); err != nil {
+				t.Fatal(err)
+			}
+
+			hash := test.hash
+			if hash == 0 {
+				hash = crypto.SHA256
+			}
+			h := hash.New()
+			h.Write(signed[br[0] : br[0]+br[1]])
+			h.Write(signed[br[2] : br[2]+br[3]])
+			verifySignature(t, signature, h.Sum(nil), signer.Certificates[0], hash)
+		})
+	}
+}
+
+func TestSignDictionary(t *testing.T) {
+	tests := []struct {
+		name     string
+		signer   func(*Signer)
+		expected map[pdfdoc.Name]string
+	}{
+		{
+			name: "defaults",
+			expected: map[pdfdoc.Name]string{
+				"Name": "Test Signer",
+			},
+		},
+		{
+			name: "custom",
+			signer: func(s *Signer) {
+				s.Name = "Jane Doe"
+				s.Reason = "Contract approval"
+				s.Location = "Amsterdam"
+				s.ContactInfo = "jane@example.com"
+			},
+			expected: map[pdfdoc.Name]string{
+				"Name":        "Jane Doe",
+				"Reason":      "Contract approval",
+				"Location":    "Amsterdam",
+				"ContactInfo": "jane@example.com",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			signer := newTestSigner(t, newECDSAKey(t))
+			signer.Time = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+			if test.signer != nil {
+				test.signer(signer)
+			}
+
+			signed, err := signer.SignBytes(minimalPDF(1))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			doc, err := pdfdoc.Parse(signed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			catalog, _, err := doc.Catalog()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			form := doc.Dict(catalog["AcroForm"])
+			if flags, _ := pdfdoc.Int(form["SigFlags"]); flags != 3 {
+				t.Errorf("expected SigFlags 3, got %d", flags)
+			}
+			fields := doc.Array(form["Fields"])
+			if len(fields) != 1 {
+				t.Fatalf("expected 1 form field, got %d", len(fields))
+			}
+			field := doc.Dict(fields[0])
+			if name, _ := field.Text("T"); name != "Signature1" {
+				t.Errorf("expected field name Signature1, got %q", name)
+			}
+
+			sig := doc.Dict(field["V"])
+			if filter := sig.Name("SubFilter"); filter != "ETSI.CAdES.detached" {
+				t.Errorf("expected SubFilter ETSI.CAdES.detached, got %s", filter)
+			}
+			if date, _ := sig.Text("M"); date != "D:20240501120000Z" {
+				t.Errorf("expected signing time D:20240501120000Z, got %s", date)
+			}
+			for key, expected := range test.expected {
+				value, _ := sig.Text(key)
+				if value = pdfdoc.DecodeText(value); value != expected {
+					t.Errorf("expected %s %q, got %q", key, expected, value)
+				}
+			}
+		})
+	}
+}
+
+func TestSignTwice(t *testing.T) {
+	signer := newTestSigner(t, newECDSAKey(t))
+
+	signed, err := signer.SignBytes(minimalPDF(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err = signer.SignBytes(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := pdfdoc.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	catalog, _, err := doc.Catalog()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, field := range doc.Array(doc.Dict(catalog["AcroForm"])["Fields"]) {
+		name, _ := doc.Dict(field).Text("T")
+		names = append(names, name)
+	}
+	if fmt.Sprint(names) != "[Signature1 Signature2]" {
+		t.Errorf("expected fields [Signature1 Signature2], got %v", names)
+	}
+
+	signer.FieldName = "Signature1"
+	if _, err := signer.SignBytes(signed); err == nil {
+		t.Error("expected error for duplicate field name")
+	}
+}
+
+func TestSignTimestamp(t *testing.T) {
+	var requests int
+	tsa := httptest.NewServer(timestampHandler(func(req timeStampReq) []byte {
+		requests++
+		return timestampResponse(t, req.MessageImprint, req.Nonce)
+	}))
+	defer tsa.Close()
+
+	signer := newTestSigner(t, newECDSAKey(t))
+	signer.Timestamper = NewTimestampClient(tsa.URL)
+
+	signed, err := signer.SignBytes(minimalPDF(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Fatalf("expected 1 timestamp request, got %d", requests)
+	}
+
+	br := readByteRange(t, signed)
+	signature := make([]byte, (br[2]-br[1]-2)/2)
+	if _, err := hex.Decode(signature, signed[br[1]+1:br[2]-1]); err != nil {
+		t.Fatal(err)
+	}
+
+	info := parseSignerInfo(t, signature)
+	if len(info.UnsignedAttrs.Bytes) == 0 {
+		t.Fatal("signature does not contain unsigned attributes")
+	}
+
+	var attr attribute
+	if _, err := asn1.Unmarshal(info.UnsignedAttrs.Bytes, &attr); err != nil {
+		t.Fatal(err)
+	}
+	if !attr.Type.Equal(oidAttrSignatureTimeStamp) {
+		t.Fatalf("expected signature timestamp attribute, got %v", attr.Type)
+	}
+
+	tst, err := parseTimestampToken(attr.Values.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imprint := sha256Sum(info.Signature)
+	if !bytes.Equal(tst.MessageImprint.HashedMessage, imprint) {
+		t.Error("timestamp token does not cover the signature value")
+	}
+}
+
+func TestTimestampRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{
+			name: "http error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			},
+		},
+		{
+			name: "rejected",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				resp, _ := asn1.Marshal(struct{ Status struct{ Status int } }{
+					Status: struct{ Status int }{Status: 2},
+				})
+				w.Write(resp) // nolint:errcheck
+			},
+		},
+		{
+			name: "imprint mismatch",
+			handler: timestampHandler(func(req timeStampReq) []byte {
+				return timestampResponse(t, messageImprint{
+					HashAlgorithm: algorithmIdentifier{Algorithm: oidSHA256},
+					HashedMessage: sha256Sum([]byte("other")),
+				}, req.Nonce)
+			}),
+		},
+		{
+			name: "nonce mismatch",
+			handler: timestampHandler(func(req timeStampReq) []byte {
+				return timestampResponse(t, req.MessageImprint, new(big.Int).Add(req.Nonce, big.NewInt(1)))
+			}),
+		},
+		{
+			name: "missing nonce",
+			handler: timestampHandler(func(req timeStampReq) []byte {
+				return timestampResponse(t, req.MessageImprint, nil)
+			}),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tsa := httptest.NewServer(test.handler)
+			defer tsa.Close()
+
+			if _, err := NewTimestampClient(tsa.URL).Timestamp([]byte("data")); err == nil {
+				t.Error("expected timestamp error")
+			}
+		})
+	}
+}
+
+func TestTimestamp(t *testing.T) {
+	var nonce *big.Int
+	tsa := httptest.NewServer(timestampHandler(func(req timeStampReq) []byte {
+		nonce = req.Nonce
+		return timestampResponse(t, req.MessageImprint, req.Nonce)
+	}))
+	defer tsa.Close()
+
+	token, err := NewTimestampClient(tsa.URL).Timestamp([]byte("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nonce == nil {
+		t.Fatal("timestamp request does not contain a nonce")
+	}
+
+	info, err := parseTimestampToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Nonce.Cmp(nonce) != 0 {
+		t.Errorf("expected nonce %v, got %v", nonce, info.Nonce)
+	}
+	if !bytes.Equal(info.MessageImprint.HashedMessage, sha256Sum([]byte("data"))) {
+		t.Error("timestamp token does not cover the data")
+	}
+}
+
+func TestParsePEM(t *testing.T) {
+	key := newECDSAKey(t)
+	cert := newCertificate(t, key)
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+
+	tests := []struct {
+		name     string
+		certData []byte
+		keyData  []byte
+		valid    bool
+	}{
+		{name: "separate", certData: certPEM, keyData: keyPEM, valid: true},
+		{name: "combined", certData: append(certPEM, keyPEM...), keyData: append(certPEM, keyPEM...), valid: true},
+		{name: "missing key", certData: certPEM, keyData: certPEM},
+		{name: "missing certificate", certData: keyPEM, keyData: keyPEM},
+		{
+			name:     "mismatched key",
+			certData: certPEM,
+			keyData: func() []byte {
+				der, _ := x509.MarshalPKCS8PrivateKey(newECDSAKey(t))
+				return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+			}(),
+		},
+		{
+			name:     "encrypted key",
+			certData: certPEM,
+			keyData:  pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: []byte{0}}),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			signer, err := ParsePEM(test.certData, test.keyData)
+			if !test.valid {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(signer.Certificates[0].Raw, cert.Raw) {
+				t.Error("unexpected signing certificate")
+			}
+		})
+	}
+}
+
+func TestParsePKCS12(t *testing.T) {
+	key := newRSAKey(t)
+	cert := newCertificate(t, key)
+
+	data, err := pkcs12.Modern.Encode(key, cert, nil, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		valid    bool
+	}{
+		{name: "valid password", password: "secret", valid: true},
+		{name: "invalid password", password: "wrong"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			signer, err := ParsePKCS12(data, test.password)
+			if !test.valid {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(signer.Certificates[0].Raw, cert.Raw) {
+				t.Error("unexpected signing certificate")
+			}
+		})
+	}
+}
+
+// readByteRange returns the values of the byte range of the last signature
+// of the specified document.
+func readByteRange(t *testing.T, data []byte) [4]int {
+	t.Helper()
+
+	matches := regexp.MustCompile(`/ByteRange \[(\d+) (\d+) (\d+) (\d+) *\]`).FindAllSubmatch(data, -1)
+	if len(matches) == 0 {
+		t.Fatal("could not find signature byte range")
+	}
+
+	var br [4]int
+	for i, match := range matches[len(matches)-1][1:] {
+		br[i], _ = strconv.Atoi(string(match))
+	}
+
+	return br
+}
+
+// verifySignature checks that the specified CMS signature contains the
+// expected message digest and that the signed attributes are signed by
+// the provided certificate.
+func verifySignature(t *testing.T, signature, digest []byte, cert *x509.Certificate, hash crypto.Hash) {
+	t.Helper()
+
+	info := parseSignerInfo(t, signature)
+	if info.SID.SerialNumber.Cmp(cert.SerialNumber) != 0 {
+		t.Error("signer identifier does not match the signing certificate")
+	}
+
+	// Check signed attributes.
+	rest := info.SignedAttrs.Bytes
+	attrs := map[string]asn1.RawValue{}
+	for len(rest) > 0 {
+		var attr attribute
+		var err error
+		if rest, err = asn1.Unmarshal(rest, &attr); err != nil {
+			t.Fatal(err)
+		}
+		attrs[attr.Type.String()] = attr.Values
+	}
+	for _, oid := range []asn1.ObjectIdentifier{oidAttrContentType, oidAttrMessageDigest, oidAttrSigningCertificateV2} {
+		if _, ok := attrs[oid.String()]; !ok {
+			t.Errorf("missing signed attribute %v", oid)
+		}
+	}
+
+	var messageDigest []byte
+	if _, err := asn1.Unmarshal(attrs[oidAttrMessageDigest.String()].Bytes, &messageDigest); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(messageDigest, digest) {
+		t.Error("message digest does not match the signed byte ranges")
+	}
+
+	// The signature is computed over the DER encoding of the signed
+	// attributes, using the SET OF tag.
+	signedAttrs := info.SignedAttrs
+	signedAttrs.Class, signedAttrs.Tag, signedAttrs.FullBytes = asn1.ClassUniversal, asn1.TagSet, nil
+	encoded, err := asn1.Marshal(signedAttrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := hash.New()
+	h.Write(encoded)
+	switch pub := cert.PublicKey.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(pub, h.Sum(nil), info.Signature) {
+			t.Error("invalid ECDSA signature")
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(pub, hash, h.Sum(nil), info.Signature); err != nil {
+			t.Errorf("invalid RSA signature: %v", err)
+		}
+	default:
+		t.Fatalf("unexpected public key type %T", pub)
+	}
+}
+
+func parseSignerInfo(t *testing.T, signature []byte) signerInfo {
+	t.Helper()
+
+	var ci contentInfo
+	if _, err := asn1.Unmarshal(signature, &ci); err != nil {
+		t.Fatal(err)
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		t.Fatalf("unexpected content type %v", ci.ContentType)
+	}
+
+	var sd signedData
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		t.Fatal(err)
+	}
+	if len(sd.EncapContentInfo.Content.Bytes) != 0 {
+		t.Error("signature is not detached")
+	}
+
+	var info signerInfo
+	if _, err := asn1.Unmarshal(sd.SignerInfos.Bytes, &info); err != nil {
+		t.Fatal(err)
+	}
+
+	return info
+}
+
+// timestampHandler returns a time stamping authority handler which responds
+// to the parsed timestamp requests using the specified function.
+func timestampHandler(respond func(req timeStampReq) []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var req timeStampReq
+		if _, err := asn1.Unmarshal(body, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/timestamp-reply")
+		w.Write(respond(req)) // nolint:errcheck
+	}
+}
+
+// timestampResponse returns a time stamping authority response containing
+// a minimal, unsigned token for the specified message imprint and nonce.
+func timestampResponse(t *testing.T, imprint messageImprint, nonce *big.Int) []byte {
+	t.Helper()
+
+	info, err := asn1.Marshal(tstInfo{
+		Version:        1,
+		Policy:         asn1.ObjectIdentifier{1, 2, 3},
+		MessageImprint: imprint,
+		SerialNumber:   big.NewInt(1),
+		GenTime:        time.Now().UTC().Truncate(time.Second),
+		Accuracy:       accuracy{Seconds: 1, Millis: 500},
+		Nonce:          nonce,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := asn1.Marshal(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sd, err := asn1.Marshal(struct {
+		Version          int
+		DigestAlgorithms asn1.RawValue
+		EncapContentInfo encapsulatedContentInfo
+	}{
+		Version:          3,
+		DigestAlgorithms: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true},
+		EncapContentInfo: encapsulatedContentInfo{
+			ContentType: oidTSTInfo,
+			Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: content},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := asn1.Marshal(contentInfo{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: sd},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := asn1.Marshal(struct {
+		Status         struct{ Status int }
+		TimeStampToken asn1.RawValue
+	}{TimeStampToken: asn1.RawValue{FullBytes: token}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return resp
+}
+
+func newTestSigner(t *testing.T, key crypto.Signer) *Signer {
+	t.Helper()
+
+	signer, err := NewSigner(key, []*x509.Certificate{newCertificate(t, key)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signer
+}
+
+func newCertificate(t *testing.T, key crypto.Signer) *x509.Certificate {
+	t.Helper()
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "Test Signer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cert
+}
+
+func newECDSAKey(t *testing.T) crypto.Signer {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+func newRSAKey(t *testing.T) crypto.Signer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+func sha256Sum(data []byte) []byte {
+	h := crypto.SHA256.New()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// minimalPDF returns a minimal PDF document with the specified number of
+// pages.
+func minimalPDF(pages int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+
+	var offsets []int
+	object := func(format string, args ...interface{}) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n", len(offsets))
+		fmt.Fprintf(&buf, format, args...)
+		buf.WriteString("\nendobj\n")
+	}
+
+	var kids bytes.Buffer
+	for i := 0; i < pages; i++ {
+		fmt.Fprintf(&kids, "%d 0 R ", 3+i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object("<< /Type /Pages /Kids [%s] /Count %d >>", kids.String(), pages)
+	for i := 0; i < pages; i++ {
+		object("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] >>")
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f\r\n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n\r\n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes()
+}
@@ -0,0 +1,229 @@
+package sign
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"time"
+)
+
+type messageImprint struct {
+	HashAlgorithm algorithmIdentifier
+	HashedMessage []byte
+}
+
+type timeStampReq struct {
+	Version        int
+	MessageImprint messageImprint
+	Nonce          *big.Int `asn1:"optional"`
+	CertReq        bool     `asn1:"optional"`
+}
+
+type timeStampResp struct {
+	Status         asn1.RawValue
+	TimeStampToken asn1.RawValue `asn1:"optional"`
+}
+
+type accuracy struct {
+	Seconds int `asn1:"optional"`
+	Millis  int `asn1:"optional,tag:0"`
+	Micros  int `asn1:"optional,tag:1"`
+}
+
+type tstInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint messageImprint
+	SerialNumber   *big.Int
+	GenTime        time.Time `asn1:"generalized"`
+	Accuracy       accuracy  `asn1:"optional"`
+	Ordering       bool      `asn1:"optional"`
+	Nonce          *big.Int  `asn1:"optional"`
+}
+
+// TimestampClient requests signature timestamps from an RFC 3161 time
+// stamping authority (TSA). Adding a signature timestamp raises the level
+// of the produced signatures from PAdES B-B to PAdES B-T.
+type TimestampClient struct {
+	// The URL of the time stamping authority.
+	// E.g.: "http://timestamp.digicert.com".
+	URL string
+
+	// The hash algorithm used for the message imprint sent to the TSA.
+	// If not specified, SHA-256 is used.
+	Hash crypto.Hash
+
+	// The username and password used to authenticate with the TSA, using
+	// HTTP basic authentication. Both fields are optional.
+	Username string
+	Password string
+
+	// The HTTP client used to send timestamp requests. If not specified,
+	// http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	// The maximum amount of time to wait for a response from the TSA.
+	// If not specified, a default timeout of 30 seconds is used.
+	Timeout time.Duration
+}
+
+// NewTimestampClient returns a new timestamp client which sends requests to
+// the time stamping authority at the specified URL.
+func NewTimestampClient(url string) *TimestampClient {
+	return &TimestampClient{URL: url}
+}
+
+// Timestamp requests a timestamp token for the specified data from the time
+// stamping authority. The returned token is the DER encoding of a CMS
+// ContentInfo structure, as defined in RFC 3161.
+func (c *TimestampClient) Timestamp(data []byte) ([]byte, error) {
+	if c.URL == "" {
+		return nil, errors.New("timestamp authority URL cannot be empty")
+	}
+
+	hash := c.Hash
+	if hash == 0 {
+		hash = crypto.SHA256
+	}
+	alg, err := hashAlgorithm(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	h := hash.New()
+	h.Write(data)
+	imprint := messageImprint{HashAlgorithm: alg, HashedMessage: h.Sum(nil)}
+
+	// Create timestamp request.
+	nonce, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := asn1.Marshal(timeStampReq{
+		Version:        1,
+		MessageImprint: imprint,
+		Nonce:          nonce,
+		CertReq:        true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Send timestamp request.
+	body, err := c.send(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse timestamp response.
+	var resp timeStampResp
+	if _, err := asn1.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid timestamp response: %w", err)
+	}
+
+	var status int
+	if _, err := asn1.Unmarshal(resp.Status.Bytes, &status); err != nil {
+		return nil, fmt.Errorf("invalid timestamp response status: %w", err)
+	}
+	if status != 0 && status != 1 {
+		return nil, fmt.Errorf("timestamp request rejected with status %d", status)
+	}
+	if len(resp.TimeStampToken.FullBytes) == 0 {
+		return nil, errors.New("timestamp response does not contain a token")
+	}
+
+	// Check that the token matches the request.
+	info, err := parseTimestampToken(resp.TimeStampToken.FullBytes)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(info.MessageImprint.HashedMessage, imprint.HashedMessage) {
+		return nil, errors.New("timestamp token message imprint mismatch")
+	}
+	if info.Nonce == nil || info.Nonce.Cmp(nonce) != 0 {
+		return nil, errors.New("timestamp token nonce mismatch")
+	}
+
+	return resp.TimeStampToken.FullBytes, nil
+}
+
+func (c *TimestampClient) send(body []byte) ([]byte, error) {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/timestamp-query")
+	if c.Username != "" || c.Password != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("timestamp request failed: %s", resp.Status)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+func parseTimestampToken(token []byte) (*tstInfo, error) {
+	var ci contentInfo
+	if _, err := asn1.Unmarshal(token, &ci); err != nil {
+		return nil, fmt.Errorf("invalid timestamp token: %w", err)
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		return nil, errors.New("invalid timestamp token content type")
+	}
+
+	var sd struct {
+		Version          int
+		DigestAlgorithms asn1.RawValue
+		EncapContentInfo struct {
+			ContentType asn1.ObjectIdentifier
+			Content     asn1.RawValue
+		}
+	}
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		return nil, fmt.Errorf("invalid timestamp token signed data: %w", err)
+	}
+	if !sd.EncapContentInfo.ContentType.Equal(oidTSTInfo) {
+		return nil, errors.New("invalid timestamp token encapsulated content type")
+	}
+
+	var content []byte
+	if _, err := asn1.Unmarshal(sd.EncapContentInfo.Content.Bytes, &content); err != nil {
+		return nil, fmt.Errorf("invalid timestamp token content: %w", err)
+	}
+
+	info := &tstInfo{}
+	if _, err := asn1.Unmarshal(content, info); err != nil {
+		return nil, fmt.Errorf("invalid timestamp token info: %w", err)
+	}
+
+	return info, nil
+}